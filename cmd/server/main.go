@@ -1,8 +1,12 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"flag"
 	"log"
+	"os"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
@@ -10,7 +14,12 @@ import (
 
 	"github.com/aseerkt/go-simple-bank/pkg/api"
 	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/events"
+	"github.com/aseerkt/go-simple-bank/pkg/gapi"
+	"github.com/aseerkt/go-simple-bank/pkg/ledger"
+	"github.com/aseerkt/go-simple-bank/pkg/scheduler"
 	"github.com/aseerkt/go-simple-bank/pkg/utils"
+	"github.com/aseerkt/go-simple-bank/pkg/webhook"
 
 	_ "github.com/lib/pq"
 )
@@ -24,11 +33,61 @@ func main() {
 		log.Fatal("unable to connect to db: ", err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		runReconcile(conn, os.Args[2:])
+		return
+	}
+
 	runDBMigrations(config.MigrateUrl, config.DBUrl)
 
 	store := db.NewStore(conn)
 
-	server := api.NewServer(store, &config)
+	if err := db.LoadCurrencies(context.Background(), store); err != nil {
+		log.Fatal("cannot load currencies: ", err)
+	}
+
+	server, err := api.NewServer(store, &config)
+
+	if err != nil {
+		log.Fatal("cannot create server: ", err)
+	}
+
+	utils.WatchConfig(func(config utils.Config) {
+		if err := server.RotateTokenKeys(&config); err != nil {
+			log.Println("unable to rotate token keys: ", err)
+			return
+		}
+
+		log.Println("token keys rotated, primary key: ", config.TokenKeyID)
+	})
+
+	if config.SchedulerInterval > 0 {
+		go scheduler.NewWorker(store, config.SchedulerInterval).Start(context.Background())
+	}
+
+	if config.EventPublisher != "" {
+		publisher, err := events.NewPublisher(config.EventPublisher, config.EventPublisherUrl, config.EventTopic)
+
+		if err != nil {
+			log.Fatal("cannot create event publisher: ", err)
+		}
+
+		go events.NewRelay(store, publisher, config.OutboxRelayInterval).Start(context.Background())
+	}
+
+	if config.WebhookInterval > 0 {
+		go webhook.NewDispatcher(store, config.WebhookInterval).Start(context.Background())
+	}
+
+	if config.GRPCServerAddress != "" {
+		grpcServer := gapi.NewServer(&config, store, server.TokenMaker(), server.RateProvider())
+
+		go func() {
+			if err := grpcServer.Start(config.GRPCServerAddress); err != nil {
+				log.Fatal("cannot start grpc server: ", err)
+			}
+		}()
+	}
 
 	server.LoadRoutes()
 
@@ -48,3 +107,29 @@ func runDBMigrations(migratePath string, dbUrl string) {
 
 	log.Println("db migrate successfully")
 }
+
+// runReconcile prints the ledger reconciliation report as JSON. With -strict
+// it exits with status 1 when the ledger doesn't balance, so it can gate a
+// deploy.
+func runReconcile(conn *sql.DB, args []string) {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	strict := flags.Bool("strict", false, "exit with status 1 when mismatches are found")
+	flags.Parse(args)
+
+	report, err := ledger.Reconcile(context.Background(), db.New(conn))
+
+	if err != nil {
+		log.Fatal("unable to reconcile ledger: ", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal("unable to write report: ", err)
+	}
+
+	if *strict && !report.Balanced {
+		os.Exit(1)
+	}
+}
@@ -3,15 +3,46 @@ package api
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
 	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/aseerkt/go-simple-bank/pkg/service"
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
 )
 
+type accountResponse struct {
+	ID               int64       `json:"id"`
+	Owner            string      `json:"owner"`
+	Balance          money.Money `json:"balance"`
+	AvailableBalance money.Money `json:"available_balance"`
+	Currency         string      `json:"currency"`
+	Product          string      `json:"product"`
+	Status           string      `json:"status"`
+	CreatedAt        time.Time   `json:"created_at"`
+}
+
+func getAccountResponse(account db.Account) accountResponse {
+	return accountResponse{
+		ID:               account.ID,
+		Owner:            account.Owner,
+		Balance:          money.New(account.Balance, account.Currency),
+		AvailableBalance: money.New(account.AvailableBalance, account.Currency),
+		Currency:         account.Currency,
+		Product:          account.Product,
+		Status:           account.Status,
+		CreatedAt:        account.CreatedAt,
+	}
+}
+
 type createAccountPayload struct {
 	Currency string `json:"currency" binding:"required,currency"`
+	// Product defaults to a checking account.
+	Product string `json:"product" binding:"omitempty,oneof=checking savings"`
 }
 
 func (s *Server) createAccount(c *gin.Context) {
@@ -28,9 +59,14 @@ func (s *Server) createAccount(c *gin.Context) {
 		Owner:    authPayload.Username,
 		Currency: payload.Currency,
 		Balance:  0,
+		Product:  db.ProductChecking,
+	}
+
+	if payload.Product != "" {
+		arg.Product = payload.Product
 	}
 
-	account, err := s.store.CreateAccount(c, arg)
+	account, err := s.store.CreateAccountTx(c, arg)
 
 	if err != nil {
 		if pqError, ok := err.(*pq.Error); ok {
@@ -44,7 +80,7 @@ func (s *Server) createAccount(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, account)
+	c.JSON(http.StatusCreated, getAccountResponse(account))
 }
 
 type getAccountUri struct {
@@ -59,26 +95,33 @@ func (s *Server) getAccount(c *gin.Context) {
 		return
 	}
 
-	account, err := s.store.GetAccount(c, uri.ID)
+	account, ok := s.getOwnedAccount(c, uri.ID)
 
-	if err != nil {
-		if err == sql.ErrNoRows {
-			handleNotFound(c, err)
-		} else {
-			handleInternalError(c, err)
-		}
+	if !ok {
 		return
 	}
 
+	c.JSON(http.StatusOK, getAccountResponse(account))
+}
+
+// getOwnedAccount loads the account and makes sure the current user may read
+// it, either as its owner or as staff, writing the error response itself when
+// they can't.
+func (s *Server) getOwnedAccount(c *gin.Context, accountID int64) (db.Account, bool) {
+	account, ok := s.loadAccount(c, accountID)
+
+	if !ok {
+		return account, false
+	}
+
 	authPayload := getAuthCtx(c)
 
-	if authPayload.Username != account.Owner {
-		err := errors.New("account doesn't belong to current user")
-		handleUnauthorized(c, err)
-		return
+	if authPayload.Username != account.Owner && !service.HasRole(authPayload, constants.RoleTeller, constants.RoleAdmin) {
+		denyAccess(c, fmt.Sprintf("read account %d owned by %s", account.ID, account.Owner))
+		return account, false
 	}
 
-	c.JSON(http.StatusOK, account)
+	return account, true
 }
 
 type listAccountsQuery struct {
@@ -109,5 +152,152 @@ func (s *Server) listAccounts(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, accounts)
+	response := make([]accountResponse, len(accounts))
+
+	for i, account := range accounts {
+		response[i] = getAccountResponse(account)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type adjustBalanceUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type adjustBalancePayload struct {
+	Amount string `json:"amount" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+type adjustBalanceResponse struct {
+	Account accountResponse `json:"account"`
+	Entry   entryResponse   `json:"entry"`
+}
+
+func (s *Server) adjustBalance(c *gin.Context) {
+	var uri adjustBalanceUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	var payload adjustBalancePayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	account, ok := s.loadAccount(c, uri.ID)
+
+	if !ok {
+		return
+	}
+
+	amount, ok := parseAmount(c, account.Currency, payload.Amount)
+
+	if !ok {
+		return
+	}
+
+	result, err := s.store.AdjustBalanceTx(c, db.AdjustBalanceTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+	})
+
+	if err != nil {
+		handleCashTxError(c, err)
+		return
+	}
+
+	service.SecurityLog.Printf("balance adjusted: user=%q account=%d amount=%q reason=%q",
+		getAuthCtx(c).Username, account.ID, amount, payload.Reason)
+
+	c.JSON(http.StatusOK, adjustBalanceResponse{
+		Account: getAccountResponse(result.Account),
+		Entry:   getEntryResponse(result.Entry, account.Currency),
+	})
+}
+
+type updateAccountStatusUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type updateAccountStatusPayload struct {
+	Status string `json:"status" binding:"required,oneof=active frozen closed"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+type updateAccountStatusResponse struct {
+	Account accountResponse        `json:"account"`
+	Change  db.AccountStatusChange `json:"change"`
+}
+
+// updateAccountStatus lets admins freeze, unfreeze and close any account, and
+// owners close their own.
+func (s *Server) updateAccountStatus(c *gin.Context) {
+	var uri updateAccountStatusUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	var payload updateAccountStatusPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	account, ok := s.loadAccount(c, uri.ID)
+
+	if !ok {
+		return
+	}
+
+	authPayload := getAuthCtx(c)
+
+	if !service.HasRole(authPayload, constants.RoleAdmin) {
+		if account.Owner != authPayload.Username {
+			denyAccess(c, fmt.Sprintf("change status of account %d owned by %s", account.ID, account.Owner))
+			return
+		}
+
+		if payload.Status != db.AccountClosed {
+			denyAccess(c, fmt.Sprintf("set own account %d to %s", account.ID, payload.Status))
+			return
+		}
+	}
+
+	result, err := s.store.ChangeAccountStatusTx(c, db.ChangeAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    payload.Status,
+		ChangedBy: authPayload.Username,
+		Reason:    payload.Reason,
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			handleNotFound(c, err)
+		case errors.Is(err, db.ErrSystemAccount):
+			handleBadRequest(c, err)
+		case errors.Is(err, db.ErrStatusChange), errors.Is(err, db.ErrNonZeroBalance):
+			handleUnprocessableEntity(c, err)
+		default:
+			handleInternalError(c, err)
+		}
+		return
+	}
+
+	service.SecurityLog.Printf("account status changed: user=%q account=%d from=%s to=%s reason=%q",
+		authPayload.Username, account.ID, result.Change.FromStatus, result.Change.ToStatus, payload.Reason)
+
+	c.JSON(http.StatusOK, updateAccountStatusResponse{
+		Account: getAccountResponse(result.Account),
+		Change:  result.Change,
+	})
 }
@@ -13,8 +13,12 @@ import (
 	"time"
 
 	"github.com/aseerkt/go-simple-bank/pkg/constants"
+	"github.com/aseerkt/go-simple-bank/pkg/currency"
 	"github.com/aseerkt/go-simple-bank/pkg/db"
 	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/aseerkt/go-simple-bank/pkg/service"
+	"github.com/aseerkt/go-simple-bank/pkg/token"
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
@@ -33,13 +37,18 @@ func createRandomAccount() (db.User, db.Account) {
 		ID:       gofakeit.Int64(),
 		Owner:    user.Username,
 		Balance:  gofakeit.Int64(),
-		Currency: gofakeit.RandomMapKey(constants.Currency).(string),
+		Currency: gofakeit.RandomString(currency.Codes()),
+		Product:  db.ProductChecking,
 	}
 }
 
 func getAuthMiddleware(username string) func(t *testing.T, s *Server, r *http.Request) {
+	return getAuthMiddlewareWithRole(username, constants.RoleCustomer)
+}
+
+func getAuthMiddlewareWithRole(username, role string) func(t *testing.T, s *Server, r *http.Request) {
 	return func(t *testing.T, s *Server, r *http.Request) {
-		token, err := s.tokenMaker.CreateToken(username, 15*time.Minute)
+		token, _, err := s.tokenMaker.CreateToken(username, role, token.AccessToken, 15*time.Minute)
 		require.NoError(t, err)
 		r.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 	}
@@ -69,15 +78,50 @@ func TestCreateAccountAPI(t *testing.T) {
 					Owner:    user.Username,
 					Currency: "INR",
 					Balance:  0,
+					Product:  db.ProductChecking,
 				}
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
 			},
 			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusCreated, r.Code)
-				var createdAccount db.Account
+				var createdAccount accountResponse
 				err := json.Unmarshal(r.Body.Bytes(), &createdAccount)
 				require.NoError(t, err)
-				require.Equal(t, createdAccount, account)
+				require.Equal(t, getAccountResponse(account), createdAccount)
+			},
+		},
+		{
+			name:      "Savings",
+			setupAuth: setupAuth,
+			body: gin.H{
+				"currency": "INR",
+				"product":  db.ProductSavings,
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				arg := db.CreateAccountParams{
+					Owner:    user.Username,
+					Currency: "INR",
+					Balance:  0,
+					Product:  db.ProductSavings,
+				}
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, r.Code)
+			},
+		},
+		{
+			name:      "SystemProduct",
+			setupAuth: setupAuth,
+			body: gin.H{
+				"currency": "INR",
+				"product":  db.ProductCash,
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
 			},
 		},
 		{
@@ -87,7 +131,7 @@ func TestCreateAccountAPI(t *testing.T) {
 				"currency": "NONE",
 			},
 			buildStub: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, r.Code)
@@ -104,11 +148,12 @@ func TestCreateAccountAPI(t *testing.T) {
 					Owner:    user.Username,
 					Currency: "INR",
 					Balance:  0,
+					Product:  db.ProductChecking,
 				}
 				err := &pq.Error{
 					Code: "23505",
 				}
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Account{}, err)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Account{}, err)
 			},
 			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusForbidden, r.Code)
@@ -126,8 +171,9 @@ func TestCreateAccountAPI(t *testing.T) {
 					Owner:    user.Username,
 					Currency: "INR",
 					Balance:  0,
+					Product:  db.ProductChecking,
 				}
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Account{}, sql.ErrConnDone)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Account{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, r.Code)
@@ -143,7 +189,7 @@ func TestCreateAccountAPI(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStub(store)
 
-			server := newTestServer(store)
+			server := newTestServer(t, store)
 			server.LoadRoutes()
 
 			body, err := json.Marshal(tc.body)
@@ -187,12 +233,12 @@ func TestGetAccountAPI(t *testing.T) {
 
 				require.NoError(t, err)
 
-				var gotAccount db.Account
+				var gotAccount accountResponse
 
 				err = json.Unmarshal(data, &gotAccount)
 
 				require.NoError(t, err)
-				require.Equal(t, account, gotAccount)
+				require.Equal(t, getAccountResponse(account), gotAccount)
 			},
 		},
 		{
@@ -236,7 +282,23 @@ func TestGetAccountAPI(t *testing.T) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+
+				var body gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &body)
+				require.NoError(t, err)
+				require.Equal(t, gin.H{"error": service.ErrAccessDenied.Error()}, body)
+			},
+		},
+		{
+			name:      "Teller",
+			accountID: account.ID,
+			setupAuth: getAuthMiddlewareWithRole("alfred", constants.RoleTeller),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
 	}
@@ -253,7 +315,7 @@ func TestGetAccountAPI(t *testing.T) {
 
 			tc.buildStub(store)
 
-			server := newTestServer(store)
+			server := newTestServer(t, store)
 			server.LoadRoutes()
 
 			recorder := httptest.NewRecorder()
@@ -318,11 +380,14 @@ func TestListAccountsAPI(t *testing.T) {
 			},
 			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, r.Code)
-				var listedAccounts []db.Account
+				var listedAccounts []accountResponse
 				err := json.Unmarshal(r.Body.Bytes(), &listedAccounts)
 				require.NoError(t, err)
-				require.Equal(t, listedAccounts, accounts)
+				require.Len(t, listedAccounts, len(accounts))
 
+				for i, account := range accounts {
+					require.Equal(t, getAccountResponse(account), listedAccounts[i])
+				}
 			},
 		},
 		{
@@ -367,7 +432,7 @@ func TestListAccountsAPI(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStub(store)
 
-			server := newTestServer(store)
+			server := newTestServer(t, store)
 			server.LoadRoutes()
 
 			request, err := http.NewRequest(http.MethodGet, "/accounts", nil)
@@ -385,3 +450,305 @@ func TestListAccountsAPI(t *testing.T) {
 		})
 	}
 }
+
+func TestAdjustBalanceAPI(t *testing.T) {
+	_, account := createRandomAccount()
+
+	account.Currency = "USD"
+
+	admin := getAuthMiddlewareWithRole("alfred", constants.RoleAdmin)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, s *Server, r *http.Request)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Ok",
+			body:      gin.H{"amount": "-0.10", "reason": "chargeback"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				arg := db.AdjustBalanceTxParams{AccountID: account.ID, Amount: money.New(-10, account.Currency)}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().AdjustBalanceTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.AdjustBalanceTxResult{Account: account}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var result adjustBalanceResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &result)
+				require.NoError(t, err)
+				require.Equal(t, getAccountResponse(account), result.Account)
+			},
+		},
+		{
+			name:      "Customer",
+			body:      gin.H{"amount": "0.10", "reason": "gift"},
+			setupAuth: getAuthMiddleware(account.Owner),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "Teller",
+			body:      gin.H{"amount": "0.10", "reason": "gift"},
+			setupAuth: getAuthMiddlewareWithRole("alfred", constants.RoleTeller),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "MissingReason",
+			body:      gin.H{"amount": "0.10"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "TooManyDecimals",
+			body:      gin.H{"amount": "-0.105", "reason": "correction"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().AdjustBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			body:      gin.H{"amount": "0.10", "reason": "correction"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().AdjustBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InsufficientFunds",
+			body:      gin.H{"amount": "-0.10", "reason": "correction"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().AdjustBalanceTx(gomock.Any(), gomock.Any()).Times(1).Return(db.AdjustBalanceTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := newTestServer(t, store)
+			server.LoadRoutes()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/adjustments", account.ID)
+
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, server, request)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateAccountStatusAPI(t *testing.T) {
+	user, account := createRandomAccount()
+
+	account.Status = db.AccountActive
+
+	admin := getAuthMiddlewareWithRole("alfred", constants.RoleAdmin)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, s *Server, r *http.Request)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "AdminFreeze",
+			body:      gin.H{"status": db.AccountFrozen, "reason": "suspected fraud"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				arg := db.ChangeAccountStatusTxParams{
+					AccountID: account.ID,
+					Status:    db.AccountFrozen,
+					ChangedBy: "alfred",
+					Reason:    "suspected fraud",
+				}
+
+				frozen := account
+				frozen.Status = db.AccountFrozen
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.ChangeAccountStatusTxResult{
+					Account: frozen,
+					Change:  db.AccountStatusChange{AccountID: account.ID, FromStatus: db.AccountActive, ToStatus: db.AccountFrozen},
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response updateAccountStatusResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, db.AccountFrozen, response.Account.Status)
+				require.Equal(t, db.AccountFrozen, response.Change.ToStatus)
+			},
+		},
+		{
+			name:      "OwnerClose",
+			body:      gin.H{"status": db.AccountClosed, "reason": "moving banks"},
+			setupAuth: getAuthMiddleware(user.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				arg := db.ChangeAccountStatusTxParams{
+					AccountID: account.ID,
+					Status:    db.AccountClosed,
+					ChangedBy: user.Username,
+					Reason:    "moving banks",
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.ChangeAccountStatusTxResult{Account: account}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "OwnerFreeze",
+			body:      gin.H{"status": db.AccountFrozen, "reason": "lost card"},
+			setupAuth: getAuthMiddleware(user.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "Stranger",
+			body:      gin.H{"status": db.AccountClosed, "reason": "moving banks"},
+			setupAuth: getAuthMiddleware("bruce"),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidStatus",
+			body:      gin.H{"status": "deleted", "reason": "moving banks"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "NonZeroBalance",
+			body:      gin.H{"status": db.AccountClosed, "reason": "moving banks"},
+			setupAuth: getAuthMiddleware(user.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ChangeAccountStatusTxResult{}, db.ErrNonZeroBalance)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:      "AlreadyClosed",
+			body:      gin.H{"status": db.AccountActive, "reason": "reopen"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ChangeAccountStatusTxResult{}, db.ErrStatusChange)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			body:      gin.H{"status": db.AccountFrozen, "reason": "suspected fraud"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().ChangeAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := newTestServer(t, store)
+			server.LoadRoutes()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/status", account.ID)
+
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, server, request)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/aseerkt/go-simple-bank/pkg/service"
+	"github.com/aseerkt/go-simple-bank/pkg/token"
+	"github.com/gin-gonic/gin"
+)
+
+func denyAccess(c *gin.Context, reason string) {
+	username := ""
+
+	if payload, ok := c.Get(authPayloadKey); ok {
+		username = payload.(*token.Payload).Username
+	}
+
+	service.LogDenial(username, c.ClientIP(), c.Request.Method+" "+c.Request.URL.Path, reason)
+
+	handleForbidden(c, service.ErrAccessDenied)
+}
+
+// handleServiceError answers with the status code of the kind of a service
+// error.
+func handleServiceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrAccessDenied):
+		denyAccess(c, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		handleNotFound(c, err)
+	case errors.Is(err, service.ErrInvalid):
+		handleBadRequest(c, err)
+	case errors.Is(err, service.ErrRejected):
+		handleUnprocessableEntity(c, err)
+	default:
+		handleInternalError(c, err)
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/gin-gonic/gin"
+)
+
+type cashTxUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type cashTxPayload struct {
+	Amount string `json:"amount" binding:"required"`
+}
+
+type cashTxResponse struct {
+	Account     accountResponse `json:"account"`
+	CashAccount accountResponse `json:"cash_account"`
+	Entry       entryResponse   `json:"entry"`
+	CashEntry   entryResponse   `json:"cash_entry"`
+}
+
+func getCashTxResponse(result db.CashTxResult) cashTxResponse {
+	return cashTxResponse{
+		Account:     getAccountResponse(result.Account),
+		CashAccount: getAccountResponse(result.CashAccount),
+		Entry:       getEntryResponse(result.Entry, result.Account.Currency),
+		CashEntry:   getEntryResponse(result.CashEntry, result.CashAccount.Currency),
+	}
+}
+
+func (s *Server) createDeposit(c *gin.Context) {
+	s.postCashTx(c, s.store.DepositTx)
+}
+
+func (s *Server) createWithdrawal(c *gin.Context) {
+	s.postCashTx(c, s.store.WithdrawTx)
+}
+
+func (s *Server) postCashTx(c *gin.Context, post func(ctx context.Context, arg db.CashTxParams) (db.CashTxResult, error)) {
+	var uri cashTxUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	var payload cashTxPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	account, ok := s.loadAccount(c, uri.ID)
+
+	if !ok {
+		return
+	}
+
+	amount, ok := parsePositiveAmount(c, account.Currency, payload.Amount)
+
+	if !ok {
+		return
+	}
+
+	result, err := post(c, db.CashTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+	})
+
+	if err != nil {
+		handleCashTxError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, getCashTxResponse(result))
+}
+
+func handleCashTxError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		handleNotFound(c, err)
+	case errors.Is(err, db.ErrSystemAccount), errors.Is(err, money.ErrCurrencyMismatch):
+		handleBadRequest(c, err)
+	case errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, money.ErrOverflow),
+		errors.Is(err, db.ErrAccountFrozen), errors.Is(err, db.ErrAccountClosed):
+		handleUnprocessableEntity(c, err)
+	default:
+		handleInternalError(c, err)
+	}
+}
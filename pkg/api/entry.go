@@ -0,0 +1,113 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/gin-gonic/gin"
+)
+
+type listEntriesUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listEntriesQuery struct {
+	AfterID   int64     `form:"after_id" binding:"min=0"`
+	PageSize  int64     `form:"page_size" binding:"required,min=5,max=20"`
+	StartTime time.Time `form:"start_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTime   time.Time `form:"end_time" time_format:"2006-01-02T15:04:05Z07:00" binding:"omitempty,gtfield=StartTime"`
+}
+
+type entryResponse struct {
+	ID         int64       `json:"id"`
+	AccountID  int64       `json:"account_id"`
+	Amount     money.Money `json:"amount"`
+	TransferID *int64      `json:"transfer_id"`
+	CreateAt   time.Time   `json:"create_at"`
+}
+
+func getEntryResponse(entry db.Entry, code string) entryResponse {
+	response := entryResponse{
+		ID:        entry.ID,
+		AccountID: entry.AccountID,
+		Amount:    money.New(entry.Amount, code),
+		CreateAt:  entry.CreateAt,
+	}
+
+	if entry.TransferID.Valid {
+		response.TransferID = &entry.TransferID.Int64
+	}
+
+	return response
+}
+
+type listEntriesRowResponse struct {
+	ID             int64       `json:"id"`
+	AccountID      int64       `json:"account_id"`
+	Amount         money.Money `json:"amount"`
+	RunningBalance money.Money `json:"running_balance"`
+	CreateAt       time.Time   `json:"create_at"`
+}
+
+type listEntriesResponse struct {
+	Entries     []listEntriesRowResponse `json:"entries"`
+	NextAfterID *int64                   `json:"next_after_id"`
+}
+
+func (s *Server) listEntries(c *gin.Context) {
+	var uri listEntriesUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	var query listEntriesQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	account, ok := s.getOwnedAccount(c, uri.ID)
+
+	if !ok {
+		return
+	}
+
+	arg := db.ListEntriesParams{
+		AccountID: uri.ID,
+		AfterID:   query.AfterID,
+		StartTime: sql.NullTime{Time: query.StartTime, Valid: !query.StartTime.IsZero()},
+		EndTime:   sql.NullTime{Time: query.EndTime, Valid: !query.EndTime.IsZero()},
+		PageSize:  int32(query.PageSize),
+	}
+
+	entries, err := s.store.ListEntries(c, arg)
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	response := listEntriesResponse{Entries: make([]listEntriesRowResponse, len(entries))}
+
+	for i, entry := range entries {
+		response.Entries[i] = listEntriesRowResponse{
+			ID:             entry.ID,
+			AccountID:      entry.AccountID,
+			Amount:         money.New(entry.Amount, account.Currency),
+			RunningBalance: money.New(entry.RunningBalance, account.Currency),
+			CreateAt:       entry.CreateAt,
+		}
+	}
+
+	if len(entries) == int(query.PageSize) {
+		response.NextAfterID = &entries[len(entries)-1].ID
+	}
+
+	c.JSON(http.StatusOK, response)
+}
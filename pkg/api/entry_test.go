@@ -0,0 +1,197 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListEntriesAPI(t *testing.T) {
+	user, account := createRandomAccount()
+
+	account.Currency = "KWD"
+
+	var entries []db.ListEntriesRow
+	var runningBalance int64
+
+	for i := range 5 {
+		amount := int64(gofakeit.IntRange(-100, 100))
+		runningBalance += amount
+		entries = append(entries, db.ListEntriesRow{
+			ID:             int64(i + 1),
+			AccountID:      account.ID,
+			Amount:         amount,
+			RunningBalance: runningBalance,
+		})
+	}
+
+	startTime := time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)
+	endTime := startTime.AddDate(0, 1, 0)
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		query         url.Values
+		setupAuth     func(t *testing.T, s *Server, r *http.Request)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, r *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Ok",
+			accountID: account.ID,
+			query: url.Values{
+				"page_size":  {"5"},
+				"after_id":   {"0"},
+				"start_time": {startTime.Format(time.RFC3339)},
+				"end_time":   {endTime.Format(time.RFC3339)},
+			},
+			setupAuth: getAuthMiddleware(user.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				arg := db.ListEntriesParams{
+					AccountID: account.ID,
+					AfterID:   0,
+					StartTime: sql.NullTime{Time: startTime, Valid: true},
+					EndTime:   sql.NullTime{Time: endTime, Valid: true},
+					PageSize:  5,
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Eq(arg)).Times(1).Return(entries, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, r.Code)
+
+				var response listEntriesResponse
+				err := json.Unmarshal(r.Body.Bytes(), &response)
+				require.NoError(t, err)
+
+				requireEntriesMatch(t, account, entries, response.Entries)
+				require.NotNil(t, response.NextAfterID)
+				require.Equal(t, entries[len(entries)-1].ID, *response.NextAfterID)
+			},
+		},
+		{
+			name:      "LastPage",
+			accountID: account.ID,
+			query: url.Values{
+				"page_size": {"10"},
+				"after_id":  {"3"},
+			},
+			setupAuth: getAuthMiddleware(user.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				arg := db.ListEntriesParams{
+					AccountID: account.ID,
+					AfterID:   3,
+					PageSize:  10,
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Eq(arg)).Times(1).Return(entries[3:], nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, r.Code)
+
+				var response listEntriesResponse
+				err := json.Unmarshal(r.Body.Bytes(), &response)
+				require.NoError(t, err)
+
+				requireEntriesMatch(t, account, entries[3:], response.Entries)
+				require.Nil(t, response.NextAfterID)
+			},
+		},
+		{
+			name:      "InvalidDateRange",
+			accountID: account.ID,
+			query: url.Values{
+				"page_size":  {"5"},
+				"start_time": {endTime.Format(time.RFC3339)},
+				"end_time":   {startTime.Format(time.RFC3339)},
+			},
+			setupAuth: getAuthMiddleware(user.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
+			},
+		},
+		{
+			name:      "AccountMismatch",
+			accountID: account.ID,
+			query: url.Values{
+				"page_size": {"5"},
+			},
+			setupAuth: getAuthMiddleware("alfred"),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, r.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			accountID: account.ID,
+			query: url.Values{
+				"page_size": {"5"},
+			},
+			setupAuth: getAuthMiddleware(user.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Any()).Times(1).Return([]db.ListEntriesRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := newTestServer(t, store)
+			server.LoadRoutes()
+
+			path := fmt.Sprintf("/accounts/%d/entries", tc.accountID)
+
+			request, err := http.NewRequest(http.MethodGet, path, nil)
+			require.NoError(t, err)
+
+			request.URL.RawQuery = tc.query.Encode()
+
+			tc.setupAuth(t, server, request)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func requireEntriesMatch(t *testing.T, account db.Account, entries []db.ListEntriesRow, gotEntries []listEntriesRowResponse) {
+	require.Len(t, gotEntries, len(entries))
+
+	for i, entry := range entries {
+		require.Equal(t, entry.ID, gotEntries[i].ID)
+		require.Equal(t, entry.AccountID, gotEntries[i].AccountID)
+		require.Equal(t, money.New(entry.Amount, account.Currency), gotEntries[i].Amount)
+		require.Equal(t, money.New(entry.RunningBalance, account.Currency), gotEntries[i].RunningBalance)
+	}
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/gin-gonic/gin"
+)
+
+type feeResponse struct {
+	Kind   string      `json:"kind"`
+	Amount money.Money `json:"amount"`
+}
+
+func getFeeResponse(fee db.TransferFee) feeResponse {
+	return feeResponse{
+		Kind:   fee.Kind,
+		Amount: money.New(fee.Amount, fee.Currency),
+	}
+}
+
+type feeRuleResponse struct {
+	Currency       string       `json:"currency"`
+	Product        string       `json:"product"`
+	TransferType   string       `json:"transfer_type"`
+	Flat           money.Money  `json:"flat"`
+	PercentBps     int32        `json:"percent_bps"`
+	PercentMin     money.Money  `json:"percent_min"`
+	PercentMax     *money.Money `json:"percent_max"`
+	FxSurchargeBps int32        `json:"fx_surcharge_bps"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+func getFeeRuleResponse(rule db.FeeRule) feeRuleResponse {
+	response := feeRuleResponse{
+		Currency:       rule.Currency,
+		Product:        rule.Product,
+		TransferType:   rule.TransferType,
+		Flat:           money.New(rule.FlatAmount, rule.Currency),
+		PercentBps:     rule.PercentBps,
+		PercentMin:     money.New(rule.PercentMin, rule.Currency),
+		FxSurchargeBps: rule.FxSurchargeBps,
+		UpdatedAt:      rule.UpdatedAt,
+	}
+
+	if rule.PercentMax.Valid {
+		percentMax := money.New(rule.PercentMax.Int64, rule.Currency)
+		response.PercentMax = &percentMax
+	}
+
+	return response
+}
+
+// listFeeRules lists what transfers cost by currency, product and transfer
+// type. Transfers without a rule are free.
+func (s *Server) listFeeRules(c *gin.Context) {
+	rules, err := s.store.ListFeeRules(c)
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	response := make([]feeRuleResponse, len(rules))
+
+	for i, rule := range rules {
+		response[i] = getFeeRuleResponse(rule)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type upsertFeeRuleUri struct {
+	Currency     string `uri:"currency" binding:"required,currency"`
+	Product      string `uri:"product" binding:"required,oneof=checking savings"`
+	TransferType string `uri:"transfer_type" binding:"required,oneof=internal external"`
+}
+
+// upsertFeeRulePayload holds fee amounts as decimal amounts. Amounts left
+// empty are zero, except percent_max which then doesn't cap the percentage
+// fee.
+type upsertFeeRulePayload struct {
+	Flat           string `json:"flat"`
+	PercentBps     int32  `json:"percent_bps" binding:"min=0,max=10000"`
+	PercentMin     string `json:"percent_min"`
+	PercentMax     string `json:"percent_max"`
+	FxSurchargeBps int32  `json:"fx_surcharge_bps" binding:"min=0,max=10000"`
+}
+
+// upsertFeeRule sets the fees charged on transfers from accounts of a
+// currency and product. It applies to transfers made from then on.
+func (s *Server) upsertFeeRule(c *gin.Context) {
+	var uri upsertFeeRuleUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	var payload upsertFeeRulePayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	var amounts [3]int64
+
+	for i, value := range []string{payload.Flat, payload.PercentMin, payload.PercentMax} {
+		amount, ok := parseLimit(c, uri.Currency, value)
+
+		if !ok {
+			return
+		}
+
+		amounts[i] = amount.Int64
+	}
+
+	arg := db.UpsertFeeRuleParams{
+		Currency:       uri.Currency,
+		Product:        uri.Product,
+		TransferType:   uri.TransferType,
+		FlatAmount:     amounts[0],
+		PercentBps:     payload.PercentBps,
+		PercentMin:     amounts[1],
+		FxSurchargeBps: payload.FxSurchargeBps,
+	}
+
+	if payload.PercentMax != "" {
+		if amounts[2] < arg.PercentMin {
+			handleBadRequest(c, errors.New("percent_max can't be less than percent_min"))
+			return
+		}
+
+		arg.PercentMax.Int64 = amounts[2]
+		arg.PercentMax.Valid = true
+	}
+
+	rule, err := s.store.UpsertFeeRule(c, arg)
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getFeeRuleResponse(rule))
+}
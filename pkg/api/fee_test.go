@@ -0,0 +1,218 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUpsertFeeRuleAPI(t *testing.T) {
+	admin := getAuthMiddlewareWithRole("alfred", constants.RoleAdmin)
+
+	arg := db.UpsertFeeRuleParams{
+		Currency:       "USD",
+		Product:        db.ProductChecking,
+		TransferType:   db.TransferExternal,
+		FlatAmount:     25,
+		PercentBps:     100,
+		PercentMin:     50,
+		PercentMax:     sql.NullInt64{Int64: 1000, Valid: true},
+		FxSurchargeBps: 30,
+	}
+
+	body := gin.H{
+		"flat":             "0.25",
+		"percent_bps":      100,
+		"percent_min":      "0.50",
+		"percent_max":      "10.00",
+		"fx_surcharge_bps": 30,
+	}
+
+	testCases := []struct {
+		name          string
+		path          string
+		body          gin.H
+		setupAuth     func(t *testing.T, s *Server, r *http.Request)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, r *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Ok",
+			path:      "USD/checking/external",
+			body:      body,
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeRule(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.FeeRule{
+						Currency:       arg.Currency,
+						Product:        arg.Product,
+						TransferType:   arg.TransferType,
+						FlatAmount:     arg.FlatAmount,
+						PercentBps:     arg.PercentBps,
+						PercentMin:     arg.PercentMin,
+						PercentMax:     arg.PercentMax,
+						FxSurchargeBps: arg.FxSurchargeBps,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, r.Code)
+
+				var response feeRuleResponse
+				err := json.Unmarshal(r.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, money.New(25, "USD"), response.Flat)
+				require.Equal(t, money.New(50, "USD"), response.PercentMin)
+				require.Equal(t, money.New(1000, "USD"), *response.PercentMax)
+			},
+		},
+		{
+			name:      "Uncapped",
+			path:      "USD/savings/internal",
+			body:      gin.H{"percent_bps": 10},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeRule(gomock.Any(), gomock.Eq(db.UpsertFeeRuleParams{
+					Currency:     "USD",
+					Product:      db.ProductSavings,
+					TransferType: db.TransferInternal,
+					PercentBps:   10,
+				})).Times(1).Return(db.FeeRule{Currency: "USD"}, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, r.Code)
+
+				var response feeRuleResponse
+				err := json.Unmarshal(r.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Nil(t, response.PercentMax)
+			},
+		},
+		{
+			name:      "Customer",
+			path:      "USD/checking/external",
+			body:      body,
+			setupAuth: getAuthMiddleware("bob"),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeRule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, r.Code)
+			},
+		},
+		{
+			name:      "SystemProduct",
+			path:      "USD/cash/external",
+			body:      body,
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeRule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
+			},
+		},
+		{
+			name:      "InvalidTransferType",
+			path:      "USD/checking/wire",
+			body:      body,
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeRule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
+			},
+		},
+		{
+			name:      "MaxBelowMin",
+			path:      "USD/checking/external",
+			body:      gin.H{"percent_bps": 100, "percent_min": "5.00", "percent_max": "1.00"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeRule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
+			},
+		},
+		{
+			name:      "InvalidAmount",
+			path:      "JPY/checking/external",
+			body:      gin.H{"flat": "1.50"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeRule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := newTestServer(t, store)
+			server.LoadRoutes()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/fees/%s", tc.path)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, server, request)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListFeeRulesAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListFeeRules(gomock.Any()).Times(1).Return([]db.FeeRule{
+		{Currency: "USD", Product: db.ProductChecking, TransferType: db.TransferExternal, FlatAmount: 25},
+	}, nil)
+
+	server := newTestServer(t, store)
+	server.LoadRoutes()
+
+	request, err := http.NewRequest(http.MethodGet, "/fees", nil)
+	require.NoError(t, err)
+
+	getAuthMiddleware("bob")(t, server, request)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response []feeRuleResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	require.NoError(t, err)
+	require.Len(t, response, 1)
+	require.Equal(t, money.New(25, "USD"), response[0].Flat)
+	require.Nil(t, response[0].PercentMax)
+}
@@ -29,3 +29,11 @@ func handleForbidden(c *gin.Context, err error) {
 func handleUnauthorized(c *gin.Context, err error) {
 	c.JSON(http.StatusUnauthorized, errorResponse(err))
 }
+
+func handleUnprocessableEntity(c *gin.Context, err error) {
+	c.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+}
+
+func handleConflict(c *gin.Context, err error) {
+	c.JSON(http.StatusConflict, errorResponse(err))
+}
@@ -0,0 +1,304 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/aseerkt/go-simple-bank/pkg/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultHoldDuration is how long a hold lasts when no expiry is given.
+	defaultHoldDuration = 7 * 24 * time.Hour
+	// maxHoldDuration is the longest funds can be held.
+	maxHoldDuration = 30 * 24 * time.Hour
+)
+
+type holdResponse struct {
+	ID          int64       `json:"id"`
+	AccountID   int64       `json:"account_id"`
+	ToAccountID int64       `json:"to_account_id"`
+	Amount      money.Money `json:"amount"`
+	Status      string      `json:"status"`
+	ExpiresAt   time.Time   `json:"expires_at"`
+	TransferID  *int64      `json:"transfer_id"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+func getHoldResponse(hold db.Hold) holdResponse {
+	response := holdResponse{
+		ID:          hold.ID,
+		AccountID:   hold.AccountID,
+		ToAccountID: hold.ToAccountID,
+		Amount:      money.New(hold.Amount, hold.Currency),
+		Status:      hold.Status,
+		ExpiresAt:   hold.ExpiresAt,
+		CreatedAt:   hold.CreatedAt,
+		UpdatedAt:   hold.UpdatedAt,
+	}
+
+	if hold.TransferID.Valid {
+		response.TransferID = &hold.TransferID.Int64
+	}
+
+	return response
+}
+
+type holdTxResponse struct {
+	Hold    holdResponse    `json:"hold"`
+	Account accountResponse `json:"account"`
+}
+
+func getHoldTxResponse(result db.HoldTxResult) holdTxResponse {
+	return holdTxResponse{
+		Hold:    getHoldResponse(result.Hold),
+		Account: getAccountResponse(result.Account),
+	}
+}
+
+type createHoldPayload struct {
+	AccountID   int64  `json:"account_id" binding:"required,min=1"`
+	ToAccountID int64  `json:"to_account_id" binding:"required,min=1,nefield=AccountID"`
+	Amount      string `json:"amount" binding:"required"`
+	Currency    string `json:"currency" binding:"required,currency"`
+	// ExpiresAt defaults to a week from now and can't be more than 30 days
+	// away.
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// createHold reserves funds on the caller's account for the recipient to
+// capture later.
+func (s *Server) createHold(c *gin.Context) {
+	var payload createHoldPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(defaultHoldDuration)
+
+	if payload.ExpiresAt != nil {
+		expiresAt = *payload.ExpiresAt
+
+		if !expiresAt.After(now) || expiresAt.After(now.Add(maxHoldDuration)) {
+			handleBadRequest(c, fmt.Errorf("expires_at must be in the next %s", maxHoldDuration))
+			return
+		}
+	}
+
+	amount, ok := parsePositiveAmount(c, payload.Currency, payload.Amount)
+
+	if !ok {
+		return
+	}
+
+	account, ok := s.loadAccount(c, payload.AccountID)
+
+	if !ok {
+		return
+	}
+
+	if account.Owner != getAuthCtx(c).Username {
+		denyAccess(c, fmt.Sprintf("hold funds on account %d owned by %s", account.ID, account.Owner))
+		return
+	}
+
+	if !checkAccountCurrency(c, account, payload.Currency) {
+		return
+	}
+
+	toAccount, ok := s.loadAccount(c, payload.ToAccountID)
+
+	if !ok {
+		return
+	}
+
+	if toAccount.Owner == db.SystemAccountOwner {
+		handleBadRequest(c, fmt.Errorf("%w: account %d can't receive transfers", db.ErrSystemAccount, toAccount.ID))
+		return
+	}
+
+	if toAccount.Currency != account.Currency {
+		handleBadRequest(c, fmt.Errorf("holds must be in one currency: account %d is in %s", toAccount.ID, toAccount.Currency))
+		return
+	}
+
+	result, err := s.store.CreateHoldTx(c, db.CreateHoldTxParams{
+		AccountID:   account.ID,
+		ToAccountID: toAccount.ID,
+		Amount:      amount,
+		ExpiresAt:   expiresAt,
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrAccountFrozen), errors.Is(err, db.ErrAccountClosed):
+			handleUnprocessableEntity(c, err)
+		default:
+			handleInternalError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, getHoldTxResponse(result))
+}
+
+type holdUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// loadHold loads the hold in the uri. The recipient and admins may act on any
+// hold, while the payer may only act when payerAllowed is set.
+func (s *Server) loadHold(c *gin.Context, action string, payerAllowed bool) (db.Hold, bool) {
+	var uri holdUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		handleBadRequest(c, err)
+		return db.Hold{}, false
+	}
+
+	hold, err := s.store.GetHold(c, uri.ID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handleNotFound(c, err)
+		} else {
+			handleInternalError(c, err)
+		}
+		return hold, false
+	}
+
+	authPayload := getAuthCtx(c)
+
+	if service.HasRole(authPayload, constants.RoleAdmin) {
+		return hold, true
+	}
+
+	accountIDs := []int64{hold.ToAccountID}
+
+	if payerAllowed {
+		accountIDs = append(accountIDs, hold.AccountID)
+	}
+
+	for _, id := range accountIDs {
+		account, err := s.store.GetAccount(c, id)
+
+		if err != nil {
+			handleInternalError(c, err)
+			return hold, false
+		}
+
+		if account.Owner == authPayload.Username {
+			return hold, true
+		}
+	}
+
+	denyAccess(c, fmt.Sprintf("%s hold %d on account %d", action, hold.ID, hold.AccountID))
+	return hold, false
+}
+
+func (s *Server) getHold(c *gin.Context) {
+	hold, ok := s.loadHold(c, "access", true)
+
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, getHoldResponse(hold))
+}
+
+type captureHoldPayload struct {
+	// Amount is optional and at most the amount held; leaving it out
+	// captures the whole hold.
+	Amount string `json:"amount"`
+}
+
+type captureHoldResponse struct {
+	Hold     holdResponse       `json:"hold"`
+	Transfer transferTxResponse `json:"transfer"`
+}
+
+// captureHold moves the held funds to the recipient as a transfer. Only the
+// recipient or an admin can capture.
+func (s *Server) captureHold(c *gin.Context) {
+	hold, ok := s.loadHold(c, "capture", false)
+
+	if !ok {
+		return
+	}
+
+	var payload captureHoldPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil && !errors.Is(err, io.EOF) {
+		handleBadRequest(c, err)
+		return
+	}
+
+	var amount money.Money
+
+	if payload.Amount != "" {
+		if amount, ok = parsePositiveAmount(c, hold.Currency, payload.Amount); !ok {
+			return
+		}
+	}
+
+	result, err := s.store.CaptureHoldTx(c, db.CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Amount: amount,
+	})
+
+	if err != nil {
+		var limitErr *db.LimitError
+
+		switch {
+		case errors.As(err, &limitErr):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "limit": limitErr})
+		case errors.Is(err, db.ErrHoldNotActive), errors.Is(err, db.ErrHoldExpired), errors.Is(err, db.ErrHoldExceeded),
+			errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, money.ErrOverflow),
+			errors.Is(err, db.ErrAccountFrozen), errors.Is(err, db.ErrAccountClosed):
+			handleUnprocessableEntity(c, err)
+		default:
+			handleInternalError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, captureHoldResponse{
+		Hold:     getHoldResponse(result.Hold),
+		Transfer: getTransferTxResponse(result.Transfer),
+	})
+}
+
+// voidHold releases the held funds without moving them. Only the recipient or
+// an admin can void.
+func (s *Server) voidHold(c *gin.Context) {
+	hold, ok := s.loadHold(c, "void", false)
+
+	if !ok {
+		return
+	}
+
+	result, err := s.store.VoidHoldTx(c, hold.ID)
+
+	if err != nil {
+		if errors.Is(err, db.ErrHoldNotActive) {
+			handleUnprocessableEntity(c, err)
+			return
+		}
+		handleInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getHoldTxResponse(result))
+}
@@ -0,0 +1,392 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func createRandomHold(account, toAccount db.Account) db.Hold {
+	return db.Hold{
+		ID:          gofakeit.Int64(),
+		AccountID:   account.ID,
+		ToAccountID: toAccount.ID,
+		Amount:      1050,
+		Currency:    account.Currency,
+		Status:      db.HoldActive,
+		ExpiresAt:   time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+	}
+}
+
+func TestCreateHoldAPI(t *testing.T) {
+	user1, account1 := createRandomAccount()
+	_, account2 := createRandomAccount()
+
+	account1.Currency = "USD"
+	account2.Currency = account1.Currency
+
+	expiresAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+
+	setupAuth := getAuthMiddleware(user1.Username)
+
+	arg := db.CreateHoldTxParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      money.New(1050, account1.Currency),
+		ExpiresAt:   expiresAt,
+	}
+
+	body := gin.H{
+		"account_id":    account1.ID,
+		"to_account_id": account2.ID,
+		"amount":        "10.50",
+		"currency":      account1.Currency,
+		"expires_at":    expiresAt,
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, s *Server, r *http.Request)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, r *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Ok",
+			body:      body,
+			setupAuth: setupAuth,
+			buildStub: func(store *mockdb.MockStore) {
+				held := account1
+				held.AvailableBalance = account1.Balance - 1050
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CreateHoldTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.HoldTxResult{Hold: createRandomHold(account1, account2), Account: held}, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, r.Code)
+
+				var response holdTxResponse
+				err := json.Unmarshal(r.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, money.New(1050, "USD"), response.Hold.Amount)
+				require.Equal(t, db.HoldActive, response.Hold.Status)
+				require.Equal(t, money.New(account1.Balance, "USD"), response.Account.Balance)
+				require.Equal(t, money.New(account1.Balance-1050, "USD"), response.Account.AvailableBalance)
+			},
+		},
+		{
+			name: "DefaultExpiry",
+			body: gin.H{
+				"account_id":    account1.ID,
+				"to_account_id": account2.ID,
+				"amount":        "10.50",
+				"currency":      account1.Currency,
+			},
+			setupAuth: setupAuth,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CreateHoldTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateHoldTxParams) (db.HoldTxResult, error) {
+						require.WithinDuration(t, time.Now().Add(defaultHoldDuration), arg.ExpiresAt, time.Minute)
+						return db.HoldTxResult{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, r.Code)
+			},
+		},
+		{
+			name: "ExpiryTooFar",
+			body: gin.H{
+				"account_id":    account1.ID,
+				"to_account_id": account2.ID,
+				"amount":        "10.50",
+				"currency":      account1.Currency,
+				"expires_at":    time.Now().Add(maxHoldDuration + time.Hour),
+			},
+			setupAuth: setupAuth,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
+			},
+		},
+		{
+			name:      "OtherUsersAccount",
+			body:      body,
+			setupAuth: getAuthMiddleware("mallory"),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().CreateHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, r.Code)
+			},
+		},
+		{
+			name:      "InsufficientFunds",
+			body:      body,
+			setupAuth: setupAuth,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CreateHoldTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.HoldTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := newTestServer(t, store)
+			server.LoadRoutes()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/holds", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, server, request)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCaptureHoldAPI(t *testing.T) {
+	_, account1 := createRandomAccount()
+	user2, account2 := createRandomAccount()
+
+	account1.Currency = "USD"
+	account2.Currency = account1.Currency
+
+	hold := createRandomHold(account1, account2)
+
+	captured := hold
+	captured.Status = db.HoldCaptured
+	captured.TransferID = sql.NullInt64{Int64: 7, Valid: true}
+
+	result := db.CaptureHoldTxResult{
+		Hold: captured,
+		Transfer: db.TransferTxResult{
+			Transfer:    db.Transfer{ID: 7, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 1000, Currency: "USD", ToAmount: 1000, ToCurrency: "USD"},
+			FromAccount: account1,
+			ToAccount:   account2,
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, s *Server, r *http.Request)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, r *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Ok",
+			setupAuth: getAuthMiddleware(user2.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Eq(db.CaptureHoldTxParams{HoldID: hold.ID})).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, r.Code)
+
+				var response captureHoldResponse
+				err := json.Unmarshal(r.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, db.HoldCaptured, response.Hold.Status)
+				require.Equal(t, int64(7), *response.Hold.TransferID)
+				require.Equal(t, int64(7), response.Transfer.Transfer.ID)
+			},
+		},
+		{
+			name:      "Partial",
+			body:      gin.H{"amount": "10.00"},
+			setupAuth: getAuthMiddlewareWithRole("alfred", constants.RoleAdmin),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Eq(db.CaptureHoldTxParams{
+					HoldID: hold.ID,
+					Amount: money.New(1000, "USD"),
+				})).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, r.Code)
+			},
+		},
+		{
+			name:      "Payer",
+			setupAuth: getAuthMiddleware(account1.Owner),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, r.Code)
+			},
+		},
+		{
+			name:      "Expired",
+			setupAuth: getAuthMiddleware(user2.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CaptureHoldTxResult{}, db.ErrHoldExpired)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, r.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			setupAuth: getAuthMiddleware(user2.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(db.Hold{}, sql.ErrNoRows)
+				store.EXPECT().CaptureHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := newTestServer(t, store)
+			server.LoadRoutes()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/holds/%d/capture", hold.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, server, request)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestVoidHoldAPI(t *testing.T) {
+	_, account1 := createRandomAccount()
+	user2, account2 := createRandomAccount()
+
+	hold := createRandomHold(account1, account2)
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, s *Server, r *http.Request)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, r *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Ok",
+			setupAuth: getAuthMiddleware(user2.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				voided := hold
+				voided.Status = db.HoldVoided
+
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().VoidHoldTx(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(db.HoldTxResult{Hold: voided, Account: account1}, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, r.Code)
+
+				var response holdTxResponse
+				err := json.Unmarshal(r.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, db.HoldVoided, response.Hold.Status)
+			},
+		},
+		{
+			name:      "OtherUser",
+			setupAuth: getAuthMiddleware("mallory"),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().VoidHoldTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, r.Code)
+			},
+		},
+		{
+			name:      "NotActive",
+			setupAuth: getAuthMiddleware(user2.Username),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetHold(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().VoidHoldTx(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(db.HoldTxResult{}, db.ErrHoldNotActive)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := newTestServer(t, store)
+			server.LoadRoutes()
+
+			url := fmt.Sprintf("/holds/%d/void", hold.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, server, request)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+const (
+	idempotencyKeyHeaderKey = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
+	// idempotencyKeyCtxKey holds the key of a request that claimed one, so
+	// handlers can record it along with what they change.
+	idempotencyKeyCtxKey = "idempotency_key"
+	// staleIdempotencyKey is how long a key can stay in progress before a
+	// retry of the same request may take it over. It's well past the time any
+	// request takes, so a key is only reclaimed once its response is never
+	// going to be recorded, as when the server died. Transfers store the key in
+	// the transaction that makes them, so a retry that reclaims it answers
+	// with the transfer already made instead of sending the money again.
+	staleIdempotencyKey = 5 * time.Minute
+)
+
+var (
+	errIdempotencyKeyReused     = errors.New("idempotency key already used with a different request")
+	errIdempotencyKeyInProgress = errors.New("request with this idempotency key is still in progress")
+)
+
+// responseRecorder keeps a copy of everything the handler writes so it can be
+// stored against the idempotency key once the request completes.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+func hashRequest(c *gin.Context, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method))
+	h.Write([]byte(c.Request.URL.Path))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// idempotency must run after auth. Requests without an Idempotency-Key header
+// are passed through untouched.
+func idempotency(store db.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeaderKey)
+
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			err := fmt.Errorf("idempotency key must not exceed %d characters", maxIdempotencyKeyLength)
+			c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		username := getAuthCtx(c).Username
+		requestHash := hashRequest(c, body)
+
+		_, err = store.CreateIdempotencyKey(c, db.CreateIdempotencyKeyParams{
+			Key:         key,
+			Username:    username,
+			RequestHash: requestHash,
+		})
+
+		if err != nil {
+			pqError, ok := err.(*pq.Error)
+
+			if !ok || pqError.Code.Name() != "unique_violation" {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+
+			if !replayIdempotentResponse(c, store, username, key, requestHash) {
+				return
+			}
+		}
+
+		c.Set(idempotencyKeyCtxKey, key)
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = recorder
+
+		c.Next()
+
+		status := recorder.Status()
+
+		// server errors are not final, release the key so the client can retry
+		if status >= http.StatusInternalServerError {
+			err := store.DeleteIdempotencyKey(c, db.DeleteIdempotencyKeyParams{
+				Username: username,
+				Key:      key,
+			})
+
+			if err != nil {
+				log.Printf("unable to release idempotency key %q of %s: %s", key, username, err)
+			}
+			return
+		}
+
+		// the response is already sent, so a key left without one is only
+		// reclaimed by a retry once it's stale
+		err = store.UpdateIdempotencyKeyResponse(c, db.UpdateIdempotencyKeyResponseParams{
+			Username:       username,
+			Key:            key,
+			ResponseStatus: sql.NullInt32{Int32: int32(status), Valid: true},
+			ResponseBody:   recorder.body.Bytes(),
+		})
+
+		if err != nil {
+			log.Printf("unable to record response for idempotency key %q of %s: %s", key, username, err)
+		}
+	}
+}
+
+// replayIdempotentResponse answers a request whose key is already taken with
+// the stored response. It returns true instead when the key was left stale by
+// the same request and has been reclaimed for it to run again.
+func replayIdempotentResponse(c *gin.Context, store db.Store, username, key, requestHash string) bool {
+	idempotencyKey, err := store.GetIdempotencyKey(c, db.GetIdempotencyKeyParams{
+		Username: username,
+		Key:      key,
+	})
+
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if idempotencyKey.RequestHash != requestHash {
+		c.AbortWithStatusJSON(http.StatusConflict, errorResponse(errIdempotencyKeyReused))
+		return false
+	}
+
+	if !idempotencyKey.ResponseStatus.Valid {
+		_, err := store.ReclaimIdempotencyKey(c, db.ReclaimIdempotencyKeyParams{
+			Username:     username,
+			Key:          key,
+			RequestHash:  requestHash,
+			StaleSeconds: int32(staleIdempotencyKey / time.Second),
+		})
+
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				c.AbortWithStatusJSON(http.StatusConflict, errorResponse(errIdempotencyKeyInProgress))
+			} else {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			}
+			return false
+		}
+
+		return true
+	}
+
+	c.Data(int(idempotencyKey.ResponseStatus.Int32), "application/json; charset=utf-8", idempotencyKey.ResponseBody)
+	c.Abort()
+	return false
+}
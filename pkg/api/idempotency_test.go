@@ -0,0 +1,243 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	user, account := createRandomAccount()
+
+	setupAuth := getAuthMiddleware(user.Username)
+
+	idempotencyKey := "8b7c6f3e-key"
+
+	body := gin.H{"currency": account.Currency}
+
+	requestHash := func(t *testing.T, body gin.H) string {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		request, err := http.NewRequest(http.MethodPost, "/accounts", nil)
+		require.NoError(t, err)
+		return hashRequest(&gin.Context{Request: request}, data)
+	}
+
+	accountParams := db.CreateAccountParams{
+		Owner:    user.Username,
+		Currency: account.Currency,
+		Balance:  0,
+		Product:  db.ProductChecking,
+	}
+
+	keyParams := db.GetIdempotencyKeyParams{
+		Username: user.Username,
+		Key:      idempotencyKey,
+	}
+
+	reclaimParams := db.ReclaimIdempotencyKeyParams{
+		Username:     user.Username,
+		Key:          idempotencyKey,
+		RequestHash:  requestHash(t, body),
+		StaleSeconds: int32(staleIdempotencyKey / time.Second),
+	}
+
+	testCases := []struct {
+		name          string
+		key           string
+		body          gin.H
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, r *httptest.ResponseRecorder)
+	}{
+		{
+			name: "NoKey",
+			key:  "",
+			body: body,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Eq(accountParams)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, r.Code)
+			},
+		},
+		{
+			name: "FirstRequest",
+			key:  idempotencyKey,
+			body: body,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Eq(db.CreateIdempotencyKeyParams{
+					Key:         idempotencyKey,
+					Username:    user.Username,
+					RequestHash: requestHash(t, body),
+				})).Times(1).Return(db.IdempotencyKey{}, nil)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Eq(accountParams)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.UpdateIdempotencyKeyResponseParams) error {
+						require.Equal(t, int32(http.StatusCreated), arg.ResponseStatus.Int32)
+						require.True(t, arg.ResponseStatus.Valid)
+
+						var storedAccount accountResponse
+						require.NoError(t, json.Unmarshal(arg.ResponseBody, &storedAccount))
+						require.Equal(t, getAccountResponse(account), storedAccount)
+						return nil
+					})
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, r.Code)
+			},
+		},
+		{
+			name: "Replay",
+			key:  idempotencyKey,
+			body: body,
+			buildStub: func(store *mockdb.MockStore) {
+				storedBody, err := json.Marshal(getAccountResponse(account))
+				require.NoError(t, err)
+
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, &pq.Error{Code: "23505"})
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(keyParams)).Times(1).Return(db.IdempotencyKey{
+					Key:            idempotencyKey,
+					Username:       user.Username,
+					RequestHash:    requestHash(t, body),
+					ResponseStatus: sql.NullInt32{Int32: http.StatusCreated, Valid: true},
+					ResponseBody:   storedBody,
+				}, nil)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, r.Code)
+				var replayedAccount accountResponse
+				err := json.Unmarshal(r.Body.Bytes(), &replayedAccount)
+				require.NoError(t, err)
+				require.Equal(t, getAccountResponse(account), replayedAccount)
+			},
+		},
+		{
+			name: "DifferentRequest",
+			key:  idempotencyKey,
+			body: body,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, &pq.Error{Code: "23505"})
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(keyParams)).Times(1).Return(db.IdempotencyKey{
+					Key:            idempotencyKey,
+					Username:       user.Username,
+					RequestHash:    requestHash(t, gin.H{"currency": "NONE"}),
+					ResponseStatus: sql.NullInt32{Int32: http.StatusCreated, Valid: true},
+				}, nil)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, r.Code)
+			},
+		},
+		{
+			name: "InProgress",
+			key:  idempotencyKey,
+			body: body,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, &pq.Error{Code: "23505"})
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(keyParams)).Times(1).Return(db.IdempotencyKey{
+					Key:         idempotencyKey,
+					Username:    user.Username,
+					RequestHash: requestHash(t, body),
+				}, nil)
+				store.EXPECT().ReclaimIdempotencyKey(gomock.Any(), gomock.Eq(reclaimParams)).Times(1).Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, r.Code)
+			},
+		},
+		{
+			name: "StaleKeyReclaimed",
+			key:  idempotencyKey,
+			body: body,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, &pq.Error{Code: "23505"})
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Eq(keyParams)).Times(1).Return(db.IdempotencyKey{
+					Key:         idempotencyKey,
+					Username:    user.Username,
+					RequestHash: requestHash(t, body),
+				}, nil)
+				store.EXPECT().ReclaimIdempotencyKey(gomock.Any(), gomock.Eq(reclaimParams)).Times(1).Return(db.IdempotencyKey{}, nil)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Eq(accountParams)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, r.Code)
+			},
+		},
+		{
+			name: "RecordResponseError",
+			key:  idempotencyKey,
+			body: body,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, nil)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Eq(accountParams)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).Times(1).Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				// the account was created, so the client still sees it
+				require.Equal(t, http.StatusCreated, r.Code)
+			},
+		},
+		{
+			name: "InternalErrorReleasesKey",
+			key:  idempotencyKey,
+			body: body,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, nil)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Eq(accountParams)).Times(1).Return(db.Account{}, sql.ErrConnDone)
+				store.EXPECT().DeleteIdempotencyKey(gomock.Any(), gomock.Eq(db.DeleteIdempotencyKeyParams{
+					Username: user.Username,
+					Key:      idempotencyKey,
+				})).Times(1).Return(nil)
+				store.EXPECT().UpdateIdempotencyKeyResponse(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := newTestServer(t, store)
+			server.LoadRoutes()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			setupAuth(t, server, request)
+
+			if tc.key != "" {
+				request.Header.Set(idempotencyKeyHeaderKey, tc.key)
+			}
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aseerkt/go-simple-bank/pkg/token"
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) getJWKS(c *gin.Context) {
+	publisher, ok := s.tokenMaker.(token.PublicKeyPublisher)
+
+	if !ok {
+		handleNotFound(c, errors.New("configured token type has no public keys"))
+		return
+	}
+
+	keys, err := publisher.PublicKeys()
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aseerkt/go-simple-bank/pkg/token"
+	"github.com/aseerkt/go-simple-bank/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetJWKSAPI(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		setupConfig   func(config *utils.Config)
+		checkResponse func(t *testing.T, r *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Asymmetric",
+			setupConfig: func(config *utils.Config) {
+				config.TokenType = token.TypeJWTEdDSA
+				config.TokenKeyID = "k1"
+				config.TokenKey = base64.StdEncoding.EncodeToString(der)
+				config.TokenVerificationKeys = ""
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, r.Code)
+
+				var jwks token.JSONWebKeySet
+				err := json.Unmarshal(r.Body.Bytes(), &jwks)
+				require.NoError(t, err)
+
+				require.Len(t, jwks.Keys, 1)
+				require.Equal(t, "k1", jwks.Keys[0].KeyID)
+				require.Equal(t, base64.RawURLEncoding.EncodeToString(privateKey.Public().(ed25519.PublicKey)), jwks.Keys[0].X)
+				require.NotContains(t, r.Body.String(), "\"d\"")
+			},
+		},
+		{
+			name:        "Symmetric",
+			setupConfig: func(config *utils.Config) {},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := utils.LoadConfig("../..")
+			tc.setupConfig(&config)
+
+			server, err := NewServer(nil, &config)
+			require.NoError(t, err)
+			server.LoadRoutes()
+
+			request, err := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/gin-gonic/gin"
+)
+
+// limitsPayload holds outgoing limits as decimal amounts. A limit left empty
+// doesn't apply.
+type limitsPayload struct {
+	PerTransfer string `json:"per_transfer"`
+	Daily       string `json:"daily"`
+	Monthly     string `json:"monthly"`
+}
+
+type limitsResponse struct {
+	PerTransfer *money.Money `json:"per_transfer"`
+	Daily       *money.Money `json:"daily"`
+	Monthly     *money.Money `json:"monthly"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+func getLimitsResponse(code string, perTransfer, daily, monthly sql.NullInt64, updatedAt time.Time) limitsResponse {
+	limit := func(value sql.NullInt64) *money.Money {
+		if !value.Valid {
+			return nil
+		}
+
+		amount := money.New(value.Int64, code)
+
+		return &amount
+	}
+
+	return limitsResponse{
+		PerTransfer: limit(perTransfer),
+		Daily:       limit(daily),
+		Monthly:     limit(monthly),
+		UpdatedAt:   updatedAt,
+	}
+}
+
+// parseLimit reads an optional limit in the currency's minor units, writing a
+// bad request response when it isn't a positive amount.
+func parseLimit(c *gin.Context, code string, value string) (sql.NullInt64, bool) {
+	if value == "" {
+		return sql.NullInt64{}, true
+	}
+
+	amount, ok := parsePositiveAmount(c, code, value)
+
+	return sql.NullInt64{Int64: amount.Amount, Valid: ok}, ok
+}
+
+// parseLimits reads the per-transfer, daily and monthly limits of payload.
+func parseLimits(c *gin.Context, code string, payload limitsPayload) ([3]sql.NullInt64, bool) {
+	var limits [3]sql.NullInt64
+
+	for i, value := range []string{payload.PerTransfer, payload.Daily, payload.Monthly} {
+		limit, ok := parseLimit(c, code, value)
+
+		if !ok {
+			return limits, false
+		}
+
+		limits[i] = limit
+	}
+
+	return limits, true
+}
+
+type upsertAccountLimitUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type accountLimitResponse struct {
+	AccountID int64 `json:"account_id"`
+	limitsResponse
+}
+
+func (s *Server) upsertAccountLimit(c *gin.Context) {
+	var uri upsertAccountLimitUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	var payload limitsPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	account, ok := s.loadAccount(c, uri.ID)
+
+	if !ok {
+		return
+	}
+
+	if account.Owner == db.SystemAccountOwner {
+		handleBadRequest(c, fmt.Errorf("%w: account %d can't have limits", db.ErrSystemAccount, account.ID))
+		return
+	}
+
+	limits, ok := parseLimits(c, account.Currency, payload)
+
+	if !ok {
+		return
+	}
+
+	limit, err := s.store.UpsertAccountLimit(c, db.UpsertAccountLimitParams{
+		AccountID:        account.ID,
+		PerTransferLimit: limits[0],
+		DailyLimit:       limits[1],
+		MonthlyLimit:     limits[2],
+	})
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, accountLimitResponse{
+		AccountID:      limit.AccountID,
+		limitsResponse: getLimitsResponse(account.Currency, limit.PerTransferLimit, limit.DailyLimit, limit.MonthlyLimit, limit.UpdatedAt),
+	})
+}
+
+type upsertTierLimitUri struct {
+	Tier     string `uri:"tier" binding:"required,oneof=standard premium"`
+	Currency string `uri:"currency" binding:"required,currency"`
+}
+
+type tierLimitResponse struct {
+	Tier     string `json:"tier"`
+	Currency string `json:"currency"`
+	limitsResponse
+}
+
+// upsertTierLimit sets the limits on what each user in a tier can send from
+// all of their accounts in a currency together.
+func (s *Server) upsertTierLimit(c *gin.Context) {
+	var uri upsertTierLimitUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	var payload limitsPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	limits, ok := parseLimits(c, uri.Currency, payload)
+
+	if !ok {
+		return
+	}
+
+	limit, err := s.store.UpsertTierLimit(c, db.UpsertTierLimitParams{
+		Tier:             uri.Tier,
+		Currency:         uri.Currency,
+		PerTransferLimit: limits[0],
+		DailyLimit:       limits[1],
+		MonthlyLimit:     limits[2],
+	})
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tierLimitResponse{
+		Tier:           limit.Tier,
+		Currency:       limit.Currency,
+		limitsResponse: getLimitsResponse(limit.Currency, limit.PerTransferLimit, limit.DailyLimit, limit.MonthlyLimit, limit.UpdatedAt),
+	})
+}
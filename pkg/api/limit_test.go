@@ -0,0 +1,295 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUpsertAccountLimitAPI(t *testing.T) {
+	_, account := createRandomAccount()
+	account.Currency = "USD"
+
+	admin := getAuthMiddlewareWithRole("alfred", constants.RoleAdmin)
+
+	arg := db.UpsertAccountLimitParams{
+		AccountID:        account.ID,
+		PerTransferLimit: sql.NullInt64{Int64: 10000, Valid: true},
+		DailyLimit:       sql.NullInt64{Int64: 50000, Valid: true},
+	}
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		body          gin.H
+		setupAuth     func(t *testing.T, s *Server, r *http.Request)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, r *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Ok",
+			accountID: account.ID,
+			body:      gin.H{"per_transfer": "100.00", "daily": "500"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpsertAccountLimit(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.AccountLimit{
+						AccountID:        account.ID,
+						PerTransferLimit: arg.PerTransferLimit,
+						DailyLimit:       arg.DailyLimit,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, r.Code)
+
+				var response accountLimitResponse
+				err := json.Unmarshal(r.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, account.ID, response.AccountID)
+				require.Equal(t, money.New(10000, "USD"), *response.PerTransfer)
+				require.Equal(t, money.New(50000, "USD"), *response.Daily)
+				require.Nil(t, response.Monthly)
+			},
+		},
+		{
+			name:      "Customer",
+			accountID: account.ID,
+			body:      gin.H{"daily": "500"},
+			setupAuth: getAuthMiddleware(account.Owner),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertAccountLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, r.Code)
+			},
+		},
+		{
+			name:      "InvalidLimit",
+			accountID: account.ID,
+			body:      gin.H{"daily": "-500"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpsertAccountLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
+			},
+		},
+		{
+			name:      "SystemAccount",
+			accountID: account.ID,
+			body:      gin.H{"daily": "500"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				cashAccount := account
+				cashAccount.Owner = db.SystemAccountOwner
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(cashAccount, nil)
+				store.EXPECT().UpsertAccountLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			accountID: account.ID,
+			body:      gin.H{"daily": "500"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().UpsertAccountLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, r.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			accountID: account.ID,
+			body:      gin.H{"per_transfer": "100.00", "daily": "500"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpsertAccountLimit(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.AccountLimit{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := newTestServer(t, store)
+			server.LoadRoutes()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/limits", tc.accountID)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, server, request)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpsertTierLimitAPI(t *testing.T) {
+	admin := getAuthMiddlewareWithRole("alfred", constants.RoleAdmin)
+
+	arg := db.UpsertTierLimitParams{
+		Tier:         db.TierStandard,
+		Currency:     "JPY",
+		MonthlyLimit: sql.NullInt64{Int64: 1_000_000, Valid: true},
+	}
+
+	testCases := []struct {
+		name          string
+		tier          string
+		currency      string
+		body          gin.H
+		setupAuth     func(t *testing.T, s *Server, r *http.Request)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, r *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Ok",
+			tier:      db.TierStandard,
+			currency:  "JPY",
+			body:      gin.H{"monthly": "1000000"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertTierLimit(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.TierLimit{Tier: arg.Tier, Currency: arg.Currency, MonthlyLimit: arg.MonthlyLimit}, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, r.Code)
+
+				var response tierLimitResponse
+				err := json.Unmarshal(r.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, db.TierStandard, response.Tier)
+				require.Equal(t, money.New(1_000_000, "JPY"), *response.Monthly)
+				require.Nil(t, response.PerTransfer)
+				require.Nil(t, response.Daily)
+			},
+		},
+		{
+			name:      "Customer",
+			tier:      db.TierStandard,
+			currency:  "JPY",
+			body:      gin.H{"monthly": "1000000"},
+			setupAuth: getAuthMiddleware("alfred"),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertTierLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, r.Code)
+			},
+		},
+		{
+			name:      "InvalidTier",
+			tier:      "gold",
+			currency:  "JPY",
+			body:      gin.H{"monthly": "1000000"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertTierLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
+			},
+		},
+		{
+			name:      "UnsupportedCurrency",
+			tier:      db.TierStandard,
+			currency:  "XXX",
+			body:      gin.H{"monthly": "1000000"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertTierLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
+			},
+		},
+		{
+			name:      "InvalidLimit",
+			tier:      db.TierStandard,
+			currency:  "JPY",
+			body:      gin.H{"monthly": "1000.50"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertTierLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			tier:      db.TierStandard,
+			currency:  "JPY",
+			body:      gin.H{"monthly": "1000000"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertTierLimit(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TierLimit{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := newTestServer(t, store)
+			server.LoadRoutes()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/tiers/%s/limits/%s", tc.tier, tc.currency)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, server, request)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
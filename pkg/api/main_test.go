@@ -4,17 +4,32 @@ import (
 	"os"
 	"testing"
 
+	"github.com/aseerkt/go-simple-bank/pkg/currency"
 	"github.com/aseerkt/go-simple-bank/pkg/db"
 	"github.com/aseerkt/go-simple-bank/pkg/utils"
 	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
 )
 
-func newTestServer(store db.Store) *Server {
+func newTestServer(t *testing.T, store db.Store) *Server {
 	config := utils.LoadConfig("../..")
 	gin.SetMode(gin.TestMode)
-	return NewServer(store, &config)
+
+	server, err := NewServer(store, &config)
+	require.NoError(t, err)
+
+	return server
 }
 
 func TestMain(m *testing.M) {
+	currency.Register(
+		currency.Currency{Code: "CAD", NumericCode: 124, MinorUnits: 2, Enabled: true},
+		currency.Currency{Code: "EUR", NumericCode: 978, MinorUnits: 2, Enabled: true},
+		currency.Currency{Code: "INR", NumericCode: 356, MinorUnits: 2, Enabled: true},
+		currency.Currency{Code: "JPY", NumericCode: 392, MinorUnits: 0, Enabled: true},
+		currency.Currency{Code: "KWD", NumericCode: 414, MinorUnits: 3, Enabled: true},
+		currency.Currency{Code: "USD", NumericCode: 840, MinorUnits: 2, Enabled: true},
+	)
+
 	os.Exit(m.Run())
 }
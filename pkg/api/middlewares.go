@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/aseerkt/go-simple-bank/pkg/service"
 	"github.com/aseerkt/go-simple-bank/pkg/token"
 	"github.com/gin-gonic/gin"
 )
@@ -47,7 +48,7 @@ func auth(tm token.Maker) gin.HandlerFunc {
 		}
 
 		accessToken := fields[1]
-		payload, err := tm.VerifyToken(accessToken)
+		payload, err := token.VerifyTokenType(tm, accessToken, token.AccessToken)
 
 		if err != nil {
 			abortUnauthorized(ctx, err)
@@ -62,3 +63,18 @@ func auth(tm token.Maker) gin.HandlerFunc {
 func getAuthCtx(c *gin.Context) *token.Payload {
 	return c.MustGet(authPayloadKey).(*token.Payload)
 }
+
+// requireRole must run after auth.
+func requireRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload := getAuthCtx(ctx)
+
+		if !service.HasRole(payload, roles...) {
+			denyAccess(ctx, fmt.Sprintf("role %q is not one of %v", payload.Role, roles))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
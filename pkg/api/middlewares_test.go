@@ -22,7 +22,7 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "Ok",
 			setupAuth: func(t *testing.T, request *http.Request, tm token.Maker) {
-				token, err := tm.CreateToken("alfred", time.Hour)
+				token, _, err := tm.CreateToken("alfred", "customer", token.AccessToken, time.Hour)
 				require.NoError(t, err)
 				require.NotEmpty(t, token)
 				request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
@@ -31,6 +31,17 @@ func TestAuthMiddleware(t *testing.T) {
 				require.Equal(t, recorder.Code, http.StatusOK)
 			},
 		},
+		{
+			name: "RefreshToken",
+			setupAuth: func(t *testing.T, request *http.Request, tm token.Maker) {
+				token, _, err := tm.CreateToken("alfred", "customer", token.RefreshToken, time.Hour)
+				require.NoError(t, err)
+				request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, recorder.Code, http.StatusUnauthorized)
+			},
+		},
 		{
 			name: "NoAuthorizationHeader",
 			setupAuth: func(t *testing.T, request *http.Request, tm token.Maker) {
@@ -71,7 +82,7 @@ func TestAuthMiddleware(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 
-			server := newTestServer(nil)
+			server := newTestServer(t, nil)
 
 			path := "/auth/me"
 
@@ -94,3 +105,41 @@ func TestAuthMiddleware(t *testing.T) {
 	}
 
 }
+
+func TestRequireRoleMiddleware(t *testing.T) {
+
+	testCases := []struct {
+		name         string
+		role         string
+		expectedCode int
+	}{
+		{name: "Admin", role: "admin", expectedCode: http.StatusOK},
+		{name: "Teller", role: "teller", expectedCode: http.StatusOK},
+		{name: "Customer", role: "customer", expectedCode: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+
+			server := newTestServer(t, nil)
+
+			path := "/auth/staff"
+
+			server.router.GET(path, auth(server.tokenMaker), requireRole("teller", "admin"), func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, gin.H{"hello": "world"})
+			})
+
+			token, _, err := server.tokenMaker.CreateToken("alfred", tc.role, token.AccessToken, time.Hour)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodGet, path, nil)
+			require.NoError(t, err)
+			request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+
+			require.Equal(t, tc.expectedCode, recorder.Code)
+		})
+	}
+}
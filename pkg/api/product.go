@@ -0,0 +1,143 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/gin-gonic/gin"
+)
+
+type productResponse struct {
+	Code               string    `json:"code"`
+	Name               string    `json:"name"`
+	InterestRateBps    int32     `json:"interest_rate_bps"`
+	InterestDaysInYear int32     `json:"interest_days_in_year"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+func getProductResponse(product db.Product) productResponse {
+	return productResponse{
+		Code:               product.Code,
+		Name:               product.Name,
+		InterestRateBps:    product.InterestRateBps,
+		InterestDaysInYear: product.InterestDaysInYear,
+		UpdatedAt:          product.UpdatedAt,
+	}
+}
+
+// listProducts lists the products customers can open accounts of.
+func (s *Server) listProducts(c *gin.Context) {
+	products, err := s.store.ListProducts(c)
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	response := make([]productResponse, len(products))
+
+	for i, product := range products {
+		response[i] = getProductResponse(product)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type updateProductInterestUri struct {
+	Code string `uri:"code" binding:"required"`
+}
+
+type updateProductInterestPayload struct {
+	// InterestRateBps is the yearly rate in basis points.
+	InterestRateBps    *int32 `json:"interest_rate_bps" binding:"required,min=0,max=10000"`
+	InterestDaysInYear int32  `json:"interest_days_in_year" binding:"required,oneof=360 365"`
+}
+
+// updateProductInterest changes the rate a product earns from the next day
+// accrued on. Days already accrued keep the rate they were accrued at.
+func (s *Server) updateProductInterest(c *gin.Context) {
+	var uri updateProductInterestUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	var payload updateProductInterestPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	product, err := s.store.UpdateProductInterest(c, db.UpdateProductInterestParams{
+		Code:               uri.Code,
+		InterestRateBps:    *payload.InterestRateBps,
+		InterestDaysInYear: payload.InterestDaysInYear,
+	})
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handleNotFound(c, err)
+			return
+		}
+		handleInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getProductResponse(product))
+}
+
+type interestPostingResponse struct {
+	Period     string      `json:"period"`
+	Amount     money.Money `json:"amount"`
+	TransferID *int64      `json:"transfer_id"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+type listInterestPostingsUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// listInterestPostings lists the interest paid into an account month by
+// month.
+func (s *Server) listInterestPostings(c *gin.Context) {
+	var uri listInterestPostingsUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	account, ok := s.getOwnedAccount(c, uri.ID)
+
+	if !ok {
+		return
+	}
+
+	postings, err := s.store.ListInterestPostings(c, account.ID)
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	response := make([]interestPostingResponse, len(postings))
+
+	for i, posting := range postings {
+		response[i] = interestPostingResponse{
+			Period:    posting.Period.Format("2006-01"),
+			Amount:    money.New(posting.Amount, account.Currency),
+			CreatedAt: posting.CreatedAt,
+		}
+
+		if posting.TransferID.Valid {
+			response[i].TransferID = &posting.TransferID.Int64
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
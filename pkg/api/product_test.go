@@ -0,0 +1,180 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUpdateProductInterestAPI(t *testing.T) {
+	admin := getAuthMiddlewareWithRole("alfred", constants.RoleAdmin)
+
+	arg := db.UpdateProductInterestParams{
+		Code:               db.ProductSavings,
+		InterestRateBps:    350,
+		InterestDaysInYear: 365,
+	}
+
+	testCases := []struct {
+		name          string
+		code          string
+		body          gin.H
+		setupAuth     func(t *testing.T, s *Server, r *http.Request)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, r *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Ok",
+			code:      db.ProductSavings,
+			body:      gin.H{"interest_rate_bps": 350, "interest_days_in_year": 365},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateProductInterest(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.Product{
+						Code:               arg.Code,
+						Name:               "Savings",
+						InterestRateBps:    arg.InterestRateBps,
+						InterestDaysInYear: arg.InterestDaysInYear,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, r.Code)
+
+				var response productResponse
+				err := json.Unmarshal(r.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, db.ProductSavings, response.Code)
+				require.Equal(t, int32(350), response.InterestRateBps)
+			},
+		},
+		{
+			name:      "ZeroRate",
+			code:      db.ProductSavings,
+			body:      gin.H{"interest_rate_bps": 0, "interest_days_in_year": 360},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateProductInterest(gomock.Any(), gomock.Eq(db.UpdateProductInterestParams{
+					Code:               db.ProductSavings,
+					InterestDaysInYear: 360,
+				})).Times(1)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, r.Code)
+			},
+		},
+		{
+			name:      "Customer",
+			code:      db.ProductSavings,
+			body:      gin.H{"interest_rate_bps": 350, "interest_days_in_year": 365},
+			setupAuth: getAuthMiddleware("bob"),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateProductInterest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, r.Code)
+			},
+		},
+		{
+			name:      "InvalidDaysInYear",
+			code:      db.ProductSavings,
+			body:      gin.H{"interest_rate_bps": 350, "interest_days_in_year": 366},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateProductInterest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
+			},
+		},
+		{
+			name:      "SystemProduct",
+			code:      db.ProductCash,
+			body:      gin.H{"interest_rate_bps": 350, "interest_days_in_year": 365},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateProductInterest(gomock.Any(), gomock.Any()).Times(1).Return(db.Product{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := newTestServer(t, store)
+			server.LoadRoutes()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/products/%s/interest", tc.code)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, server, request)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListInterestPostingsAPI(t *testing.T) {
+	user, account := createRandomAccount()
+	account.Currency = "USD"
+	account.Product = db.ProductSavings
+
+	postings := []db.InterestPosting{
+		{AccountID: account.ID, Period: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), Amount: 410, TransferID: sql.NullInt64{Int64: 3, Valid: true}},
+		{AccountID: account.ID, Period: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), Amount: 0},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	store.EXPECT().ListInterestPostings(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(postings, nil)
+
+	server := newTestServer(t, store)
+	server.LoadRoutes()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d/interest", account.ID), nil)
+	require.NoError(t, err)
+
+	getAuthMiddleware(user.Username)(t, server, request)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response []interestPostingResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	require.NoError(t, err)
+	require.Len(t, response, 2)
+	require.Equal(t, "2024-06", response[0].Period)
+	require.Equal(t, money.New(410, "USD"), response[0].Amount)
+	require.Equal(t, int64(3), *response[0].TransferID)
+	require.Nil(t, response[1].TransferID)
+}
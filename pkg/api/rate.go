@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/fx"
+	"github.com/gin-gonic/gin"
+)
+
+type upsertRatePayload struct {
+	BaseCurrency  string `json:"base_currency" binding:"required,currency"`
+	QuoteCurrency string `json:"quote_currency" binding:"required,currency,nefield=BaseCurrency"`
+	Rate          string `json:"rate" binding:"required"`
+}
+
+type rateResponse struct {
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	Rate          string `json:"rate"`
+}
+
+func (s *Server) upsertRate(c *gin.Context) {
+	var payload upsertRatePayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	rate, err := fx.ParseRate(payload.Rate)
+
+	if err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	saved, err := s.store.UpsertRate(c, db.UpsertRateParams{
+		BaseCurrency:  payload.BaseCurrency,
+		QuoteCurrency: payload.QuoteCurrency,
+		Rate:          rate,
+	})
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rateResponse{
+		BaseCurrency:  saved.BaseCurrency,
+		QuoteCurrency: saved.QuoteCurrency,
+		Rate:          fx.FormatRate(saved.Rate),
+	})
+}
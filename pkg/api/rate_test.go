@@ -0,0 +1,118 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUpsertRateAPI(t *testing.T) {
+	admin := getAuthMiddlewareWithRole("alfred", constants.RoleAdmin)
+
+	arg := db.UpsertRateParams{BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: 92_000_000}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, s *Server, r *http.Request)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, r *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Ok",
+			body:      gin.H{"base_currency": "USD", "quote_currency": "EUR", "rate": "0.92"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertRate(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.Rate{BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: 92_000_000}, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, r.Code)
+
+				var response rateResponse
+				err := json.Unmarshal(r.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, "0.92000000", response.Rate)
+			},
+		},
+		{
+			name:      "Customer",
+			body:      gin.H{"base_currency": "USD", "quote_currency": "EUR", "rate": "0.92"},
+			setupAuth: getAuthMiddleware("alfred"),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, r.Code)
+			},
+		},
+		{
+			name:      "SameCurrency",
+			body:      gin.H{"base_currency": "USD", "quote_currency": "USD", "rate": "1"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
+			},
+		},
+		{
+			name:      "InvalidRate",
+			body:      gin.H{"base_currency": "USD", "quote_currency": "EUR", "rate": "-0.92"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertRate(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			body:      gin.H{"base_currency": "USD", "quote_currency": "EUR", "rate": "0.92"},
+			setupAuth: admin,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertRate(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Rate{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := newTestServer(t, store)
+			server.LoadRoutes()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/rates", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, server, request)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
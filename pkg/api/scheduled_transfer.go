@@ -0,0 +1,381 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/aseerkt/go-simple-bank/pkg/scheduler"
+	"github.com/aseerkt/go-simple-bank/pkg/service"
+	"github.com/gin-gonic/gin"
+)
+
+var errScheduleNotActive = errors.New("scheduled transfer isn't active")
+
+type scheduledTransferResponse struct {
+	ID            int64       `json:"id"`
+	Owner         string      `json:"owner"`
+	FromAccountID int64       `json:"from_account_id"`
+	ToAccountID   int64       `json:"to_account_id"`
+	Amount        money.Money `json:"amount"`
+	Recurrence    *string     `json:"recurrence"`
+	StartAt       time.Time   `json:"start_at"`
+	Status        string      `json:"status"`
+	RunCount      int32       `json:"run_count"`
+	NextRunAt     *time.Time  `json:"next_run_at"`
+	Attempts      int32       `json:"attempts"`
+	LastError     *string     `json:"last_error"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
+
+func getScheduledTransferResponse(schedule db.ScheduledTransfer) scheduledTransferResponse {
+	response := scheduledTransferResponse{
+		ID:            schedule.ID,
+		Owner:         schedule.Owner,
+		FromAccountID: schedule.FromAccountID,
+		ToAccountID:   schedule.ToAccountID,
+		Amount:        money.New(schedule.Amount, schedule.Currency),
+		StartAt:       schedule.StartAt,
+		Status:        schedule.Status,
+		RunCount:      schedule.RunCount,
+		Attempts:      schedule.Attempts,
+		CreatedAt:     schedule.CreatedAt,
+		UpdatedAt:     schedule.UpdatedAt,
+	}
+
+	if schedule.Recurrence.Valid {
+		response.Recurrence = &schedule.Recurrence.String
+	}
+
+	if schedule.NextRunAt.Valid {
+		response.NextRunAt = &schedule.NextRunAt.Time
+	}
+
+	if schedule.LastError.Valid {
+		response.LastError = &schedule.LastError.String
+	}
+
+	return response
+}
+
+type scheduledTransferRunResponse struct {
+	ID           int64     `json:"id"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+	Attempt      int32     `json:"attempt"`
+	TransferID   *int64    `json:"transfer_id"`
+	Error        *string   `json:"error"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func getScheduledTransferRunResponse(run db.ScheduledTransferRun) scheduledTransferRunResponse {
+	response := scheduledTransferRunResponse{
+		ID:           run.ID,
+		ScheduledFor: run.ScheduledFor,
+		Attempt:      run.Attempt,
+		CreatedAt:    run.CreatedAt,
+	}
+
+	if run.TransferID.Valid {
+		response.TransferID = &run.TransferID.Int64
+	}
+
+	if run.Error.Valid {
+		response.Error = &run.Error.String
+	}
+
+	return response
+}
+
+// parseSchedule checks that startAt is in the future and normalizes the
+// optional recurrence rule, writing a bad request response when either isn't
+// valid.
+func parseSchedule(c *gin.Context, startAt time.Time, rule string) (sql.NullString, bool) {
+	if !startAt.After(time.Now()) {
+		handleBadRequest(c, errors.New("start_at must be in the future"))
+		return sql.NullString{}, false
+	}
+
+	if rule == "" {
+		return sql.NullString{}, true
+	}
+
+	recurrence, err := scheduler.ParseRecurrence(rule)
+
+	if err != nil {
+		handleBadRequest(c, err)
+		return sql.NullString{}, false
+	}
+
+	return sql.NullString{String: recurrence.String(), Valid: true}, true
+}
+
+type createScheduledTransferPayload struct {
+	FromAccountID int64     `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64     `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	Amount        string    `json:"amount" binding:"required"`
+	Currency      string    `json:"currency" binding:"required,currency"`
+	StartAt       time.Time `json:"start_at" binding:"required"`
+	// Recurrence is an RRULE such as "FREQ=MONTHLY;COUNT=12". Leaving it out
+	// schedules a single transfer at start_at.
+	Recurrence string `json:"recurrence"`
+}
+
+func (s *Server) createScheduledTransfer(c *gin.Context) {
+	var payload createScheduledTransferPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	amount, ok := parsePositiveAmount(c, payload.Currency, payload.Amount)
+
+	if !ok {
+		return
+	}
+
+	recurrence, ok := parseSchedule(c, payload.StartAt, payload.Recurrence)
+
+	if !ok {
+		return
+	}
+
+	fromAccount, ok := s.loadAccount(c, payload.FromAccountID)
+
+	if !ok {
+		return
+	}
+
+	authPayload := getAuthCtx(c)
+
+	if fromAccount.Owner != authPayload.Username {
+		denyAccess(c, fmt.Sprintf("schedule debits from account %d owned by %s", fromAccount.ID, fromAccount.Owner))
+		return
+	}
+
+	if !checkAccountCurrency(c, fromAccount, payload.Currency) {
+		return
+	}
+
+	toAccount, ok := s.loadAccount(c, payload.ToAccountID)
+
+	if !ok {
+		return
+	}
+
+	if toAccount.Owner == db.SystemAccountOwner {
+		handleBadRequest(c, fmt.Errorf("%w: account %d can't receive transfers", db.ErrSystemAccount, toAccount.ID))
+		return
+	}
+
+	if toAccount.Currency != fromAccount.Currency {
+		handleBadRequest(c, fmt.Errorf("scheduled transfers must be in one currency: account %d is in %s", toAccount.ID, toAccount.Currency))
+		return
+	}
+
+	schedule, err := s.store.CreateScheduledTransfer(c, db.CreateScheduledTransferParams{
+		Owner:         authPayload.Username,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount.Amount,
+		Currency:      amount.Currency,
+		Recurrence:    recurrence,
+		StartAt:       payload.StartAt,
+	})
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, getScheduledTransferResponse(schedule))
+}
+
+type listScheduledTransfersQuery struct {
+	PageID   int64 `form:"page_id" binding:"required,min=1"`
+	PageSize int64 `form:"page_size" binding:"required,min=5,max=20"`
+}
+
+func (s *Server) listScheduledTransfers(c *gin.Context) {
+	var query listScheduledTransfersQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	schedules, err := s.store.ListScheduledTransfers(c, db.ListScheduledTransfersParams{
+		Owner:  getAuthCtx(c).Username,
+		Limit:  int32(query.PageSize),
+		Offset: int32((query.PageID - 1) * query.PageSize),
+	})
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	response := make([]scheduledTransferResponse, len(schedules))
+
+	for i, schedule := range schedules {
+		response[i] = getScheduledTransferResponse(schedule)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type scheduledTransferUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// loadScheduledTransfer loads the scheduled transfer in the uri, which only
+// its owner or an admin may see.
+func (s *Server) loadScheduledTransfer(c *gin.Context) (db.ScheduledTransfer, bool) {
+	var uri scheduledTransferUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		handleBadRequest(c, err)
+		return db.ScheduledTransfer{}, false
+	}
+
+	schedule, err := s.store.GetScheduledTransfer(c, uri.ID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handleNotFound(c, err)
+		} else {
+			handleInternalError(c, err)
+		}
+		return schedule, false
+	}
+
+	authPayload := getAuthCtx(c)
+
+	if schedule.Owner != authPayload.Username && !service.HasRole(authPayload, constants.RoleAdmin) {
+		denyAccess(c, fmt.Sprintf("access scheduled transfer %d owned by %s", schedule.ID, schedule.Owner))
+		return schedule, false
+	}
+
+	return schedule, true
+}
+
+func (s *Server) getScheduledTransfer(c *gin.Context) {
+	schedule, ok := s.loadScheduledTransfer(c)
+
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, getScheduledTransferResponse(schedule))
+}
+
+type updateScheduledTransferPayload struct {
+	Amount     string    `json:"amount" binding:"required"`
+	StartAt    time.Time `json:"start_at" binding:"required"`
+	Recurrence string    `json:"recurrence"`
+}
+
+// updateScheduledTransfer lets the owner change the amount and timing of an
+// active schedule. The recurrence starts over from the new start_at.
+func (s *Server) updateScheduledTransfer(c *gin.Context) {
+	schedule, ok := s.loadScheduledTransfer(c)
+
+	if !ok {
+		return
+	}
+
+	var payload updateScheduledTransferPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	if schedule.Owner != getAuthCtx(c).Username {
+		denyAccess(c, fmt.Sprintf("update scheduled transfer %d owned by %s", schedule.ID, schedule.Owner))
+		return
+	}
+
+	amount, ok := parsePositiveAmount(c, schedule.Currency, payload.Amount)
+
+	if !ok {
+		return
+	}
+
+	recurrence, ok := parseSchedule(c, payload.StartAt, payload.Recurrence)
+
+	if !ok {
+		return
+	}
+
+	schedule, err := s.store.UpdateScheduledTransfer(c, db.UpdateScheduledTransferParams{
+		ID:         schedule.ID,
+		Amount:     amount.Amount,
+		Recurrence: recurrence,
+		StartAt:    payload.StartAt,
+	})
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handleUnprocessableEntity(c, errScheduleNotActive)
+			return
+		}
+		handleInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getScheduledTransferResponse(schedule))
+}
+
+// cancelScheduledTransfer stops an active schedule. Its history is kept.
+func (s *Server) cancelScheduledTransfer(c *gin.Context) {
+	schedule, ok := s.loadScheduledTransfer(c)
+
+	if !ok {
+		return
+	}
+
+	schedule, err := s.store.CancelScheduledTransfer(c, schedule.ID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handleUnprocessableEntity(c, errScheduleNotActive)
+			return
+		}
+		handleInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getScheduledTransferResponse(schedule))
+}
+
+// listScheduledTransferRuns lists every attempt at running a schedule,
+// including the ones that failed.
+func (s *Server) listScheduledTransferRuns(c *gin.Context) {
+	schedule, ok := s.loadScheduledTransfer(c)
+
+	if !ok {
+		return
+	}
+
+	runs, err := s.store.ListScheduledTransferRuns(c, schedule.ID)
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	response := make([]scheduledTransferRunResponse, len(runs))
+
+	for i, run := range runs {
+		response[i] = getScheduledTransferRunResponse(run)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,408 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/aseerkt/go-simple-bank/pkg/scheduler"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func createRandomScheduledTransfer(owner string) db.ScheduledTransfer {
+	startAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	return db.ScheduledTransfer{
+		ID:            gofakeit.Int64(),
+		Owner:         owner,
+		FromAccountID: gofakeit.Int64(),
+		ToAccountID:   gofakeit.Int64(),
+		Amount:        1050,
+		Currency:      "USD",
+		StartAt:       startAt,
+		Status:        scheduler.StatusActive,
+		NextRunAt:     sql.NullTime{Time: startAt, Valid: true},
+		DueAt:         sql.NullTime{Time: startAt, Valid: true},
+	}
+}
+
+func TestCreateScheduledTransferAPI(t *testing.T) {
+	user1, account1 := createRandomAccount()
+	_, account2 := createRandomAccount()
+
+	account1.Currency = "USD"
+	account2.Currency = account1.Currency
+
+	startAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	setupAuth := getAuthMiddleware(user1.Username)
+
+	arg := db.CreateScheduledTransferParams{
+		Owner:         user1.Username,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1050,
+		Currency:      account1.Currency,
+		Recurrence:    sql.NullString{String: "FREQ=MONTHLY;INTERVAL=1;COUNT=12", Valid: true},
+		StartAt:       startAt,
+	}
+
+	body := gin.H{
+		"from_account_id": account1.ID,
+		"to_account_id":   account2.ID,
+		"amount":          "10.50",
+		"currency":        account1.Currency,
+		"start_at":        startAt,
+		"recurrence":      "FREQ=MONTHLY;COUNT=12",
+	}
+
+	withBody := func(changes gin.H) gin.H {
+		changed := gin.H{}
+
+		for k, v := range body {
+			changed[k] = v
+		}
+
+		for k, v := range changes {
+			changed[k] = v
+		}
+
+		return changed
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, s *Server, r *http.Request)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, r *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Ok",
+			body:      body,
+			setupAuth: setupAuth,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.ScheduledTransfer{
+						ID:            1,
+						Owner:         arg.Owner,
+						FromAccountID: arg.FromAccountID,
+						ToAccountID:   arg.ToAccountID,
+						Amount:        arg.Amount,
+						Currency:      arg.Currency,
+						Recurrence:    arg.Recurrence,
+						StartAt:       arg.StartAt,
+						Status:        scheduler.StatusActive,
+						NextRunAt:     sql.NullTime{Time: arg.StartAt, Valid: true},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, r.Code)
+
+				var response scheduledTransferResponse
+				err := json.Unmarshal(r.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, money.New(1050, "USD"), response.Amount)
+				require.Equal(t, arg.Recurrence.String, *response.Recurrence)
+				require.Equal(t, scheduler.StatusActive, response.Status)
+				require.True(t, startAt.Equal(*response.NextRunAt))
+			},
+		},
+		{
+			name:      "OtherUsersAccount",
+			body:      body,
+			setupAuth: getAuthMiddleware("mallory"),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, r.Code)
+			},
+		},
+		{
+			name:      "InvalidRecurrence",
+			body:      withBody(gin.H{"recurrence": "FREQ=HOURLY"}),
+			setupAuth: setupAuth,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
+			},
+		},
+		{
+			name:      "StartInPast",
+			body:      withBody(gin.H{"start_at": startAt.Add(-2 * time.Hour)}),
+			setupAuth: setupAuth,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
+			},
+		},
+		{
+			name:      "CurrencyMismatch",
+			body:      body,
+			setupAuth: setupAuth,
+			buildStub: func(store *mockdb.MockStore) {
+				eurAccount := account2
+				eurAccount.Currency = "EUR"
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(eurAccount, nil)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, r.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			body:      body,
+			setupAuth: setupAuth,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.ScheduledTransfer{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := newTestServer(t, store)
+			server.LoadRoutes()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/scheduled-transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, server, request)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCancelScheduledTransferAPI(t *testing.T) {
+	schedule := createRandomScheduledTransfer(gofakeit.Username())
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, s *Server, r *http.Request)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, r *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Ok",
+			setupAuth: getAuthMiddleware(schedule.Owner),
+			buildStub: func(store *mockdb.MockStore) {
+				cancelled := schedule
+				cancelled.Status = scheduler.StatusCancelled
+				cancelled.NextRunAt = sql.NullTime{}
+
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(schedule.ID)).Times(1).Return(schedule, nil)
+				store.EXPECT().CancelScheduledTransfer(gomock.Any(), gomock.Eq(schedule.ID)).Times(1).Return(cancelled, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, r.Code)
+
+				var response scheduledTransferResponse
+				err := json.Unmarshal(r.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, scheduler.StatusCancelled, response.Status)
+				require.Nil(t, response.NextRunAt)
+			},
+		},
+		{
+			name:      "Admin",
+			setupAuth: getAuthMiddlewareWithRole("alfred", constants.RoleAdmin),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(schedule.ID)).Times(1).Return(schedule, nil)
+				store.EXPECT().CancelScheduledTransfer(gomock.Any(), gomock.Eq(schedule.ID)).Times(1).Return(schedule, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, r.Code)
+			},
+		},
+		{
+			name:      "OtherUser",
+			setupAuth: getAuthMiddleware("mallory"),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(schedule.ID)).Times(1).Return(schedule, nil)
+				store.EXPECT().CancelScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, r.Code)
+			},
+		},
+		{
+			name:      "NotActive",
+			setupAuth: getAuthMiddleware(schedule.Owner),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(schedule.ID)).Times(1).Return(schedule, nil)
+				store.EXPECT().CancelScheduledTransfer(gomock.Any(), gomock.Eq(schedule.ID)).Times(1).Return(db.ScheduledTransfer{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, r.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			setupAuth: getAuthMiddleware(schedule.Owner),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(schedule.ID)).Times(1).Return(db.ScheduledTransfer{}, sql.ErrNoRows)
+				store.EXPECT().CancelScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := newTestServer(t, store)
+			server.LoadRoutes()
+
+			url := fmt.Sprintf("/scheduled-transfers/%d", schedule.ID)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, server, request)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListScheduledTransferRunsAPI(t *testing.T) {
+	schedule := createRandomScheduledTransfer(gofakeit.Username())
+
+	runs := []db.ScheduledTransferRun{
+		{
+			ID:                  1,
+			ScheduledTransferID: schedule.ID,
+			ScheduledFor:        schedule.StartAt,
+			Attempt:             1,
+			Error:               sql.NullString{String: db.ErrInsufficientFunds.Error(), Valid: true},
+		},
+		{
+			ID:                  2,
+			ScheduledTransferID: schedule.ID,
+			ScheduledFor:        schedule.StartAt,
+			Attempt:             2,
+			TransferID:          sql.NullInt64{Int64: 7, Valid: true},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, s *Server, r *http.Request)
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, r *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Ok",
+			setupAuth: getAuthMiddleware(schedule.Owner),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(schedule.ID)).Times(1).Return(schedule, nil)
+				store.EXPECT().ListScheduledTransferRuns(gomock.Any(), gomock.Eq(schedule.ID)).Times(1).Return(runs, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, r.Code)
+
+				var response []scheduledTransferRunResponse
+				err := json.Unmarshal(r.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Len(t, response, 2)
+				require.Equal(t, db.ErrInsufficientFunds.Error(), *response[0].Error)
+				require.Nil(t, response[0].TransferID)
+				require.Equal(t, int64(7), *response[1].TransferID)
+				require.Nil(t, response[1].Error)
+			},
+		},
+		{
+			name:      "OtherUser",
+			setupAuth: getAuthMiddleware("mallory"),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(schedule.ID)).Times(1).Return(schedule, nil)
+				store.EXPECT().ListScheduledTransferRuns(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, r.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			setupAuth: getAuthMiddleware(schedule.Owner),
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetScheduledTransfer(gomock.Any(), gomock.Eq(schedule.ID)).Times(1).Return(schedule, nil)
+				store.EXPECT().ListScheduledTransferRuns(gomock.Any(), gomock.Eq(schedule.ID)).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server := newTestServer(t, store)
+			server.LoadRoutes()
+
+			url := fmt.Sprintf("/scheduled-transfers/%d/runs", schedule.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, server, request)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -1,7 +1,12 @@
 package api
 
 import (
+	"errors"
+	"fmt"
+
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
 	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/fx"
 	"github.com/aseerkt/go-simple-bank/pkg/token"
 	"github.com/aseerkt/go-simple-bank/pkg/utils"
 	"github.com/gin-gonic/gin"
@@ -10,34 +15,148 @@ import (
 )
 
 type Server struct {
-	config     *utils.Config
-	tokenMaker token.Maker
-	store      db.Store
-	router     *gin.Engine
+	config       *utils.Config
+	tokenMaker   token.Maker
+	store        db.Store
+	rateProvider fx.RateProvider
+	router       *gin.Engine
 }
 
-func NewServer(store db.Store, config *utils.Config) *Server {
+func NewServer(store db.Store, config *utils.Config) (*Server, error) {
+
+	keySet, err := tokenKeySet(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tm, err := token.NewMaker(config.TokenType, keySet, config.TokenImplicitAssertion)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	if config.AccessTokenDuration <= 0 || config.RefreshTokenDuration <= config.AccessTokenDuration {
+		return nil, fmt.Errorf("invalid token durations: access %s, refresh %s", config.AccessTokenDuration, config.RefreshTokenDuration)
+	}
+
+	if config.FxSpreadBps < 0 || config.FxSpreadBps >= 10_000 {
+		return nil, fmt.Errorf("invalid fx spread: %d bps", config.FxSpreadBps)
+	}
+
+	var rateProvider fx.RateProvider = fx.NewStoreProvider(store)
 
-	tm := token.NewPasetoMaker()
+	if config.FxRatesFile != "" {
+		rateProvider, err = fx.LoadFileProvider(config.FxRatesFile)
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot load fx rates: %w", err)
+		}
+	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", validCurrency)
 	}
 
-	return &Server{config: config, tokenMaker: tm, store: store, router: gin.Default()}
+	return &Server{config: config, tokenMaker: tm, store: store, rateProvider: rateProvider, router: gin.Default()}, nil
+}
+
+func tokenKeySet(config *utils.Config) (token.KeySet, error) {
+	keySet, err := token.ParseKeySet(config.TokenKeyID, config.TokenKey, config.TokenVerificationKeys)
+
+	if err != nil {
+		return keySet, fmt.Errorf("invalid token keys: %w", err)
+	}
+
+	return keySet, nil
+}
+
+// RotateTokenKeys swaps the token keys for the ones in config without
+// invalidating tokens signed with keys that are still listed there.
+func (s *Server) RotateTokenKeys(config *utils.Config) error {
+	rotator, ok := s.tokenMaker.(token.KeyRotator)
+
+	if !ok {
+		return errors.New("token maker doesn't support key rotation")
+	}
+
+	keySet, err := tokenKeySet(config)
+
+	if err != nil {
+		return err
+	}
+
+	return rotator.Rotate(keySet)
+}
+
+// TokenMaker is the token maker the server signs and verifies tokens with,
+// for other servers that take the same tokens.
+func (s *Server) TokenMaker() token.Maker {
+	return s.tokenMaker
+}
+
+// RateProvider is where the server gets exchange rates from.
+func (s *Server) RateProvider() fx.RateProvider {
+	return s.rateProvider
 }
 
 func (s *Server) LoadRoutes() {
 	s.router.POST("/users", s.createUser)
 	s.router.POST("/users/login", s.loginUser)
+	s.router.POST("/tokens/renew_access", s.renewAccessToken)
+	s.router.GET("/.well-known/jwks.json", s.getJWKS)
 
 	authRoutes := s.router.Group("/").Use(auth(s.tokenMaker))
 
-	authRoutes.POST("/accounts", s.createAccount)
+	authRoutes.POST("/users/logout", s.logoutUser)
+	authRoutes.GET("/users/sessions", s.listSessions)
+	authRoutes.PUT("/users/:username/role", requireRole(constants.RoleAdmin), s.updateUserRole)
+	authRoutes.PUT("/users/:username/tier", requireRole(constants.RoleAdmin), s.updateUserTier)
+
+	authRoutes.POST("/accounts", idempotency(s.store), s.createAccount)
 	authRoutes.GET("/accounts/:id", s.getAccount)
 	authRoutes.GET("/accounts", s.listAccounts)
+	authRoutes.GET("/accounts/:id/entries", s.listEntries)
+	authRoutes.GET("/accounts/:id/transfers", s.listTransfers)
+	authRoutes.GET("/accounts/:id/interest", s.listInterestPostings)
+	authRoutes.PUT("/accounts/:id/status", s.updateAccountStatus)
+	authRoutes.PUT("/accounts/:id/limits", requireRole(constants.RoleAdmin), s.upsertAccountLimit)
+
+	authRoutes.POST("/accounts/:id/adjustments", requireRole(constants.RoleAdmin), s.adjustBalance)
+	authRoutes.POST("/accounts/:id/deposits", requireRole(constants.RoleTeller), idempotency(s.store), s.createDeposit)
+	authRoutes.POST("/accounts/:id/withdrawals", requireRole(constants.RoleTeller), idempotency(s.store), s.createWithdrawal)
+
+	authRoutes.POST("/transfers", idempotency(s.store), s.createTransfer)
+	authRoutes.GET("/transfers/:id", s.getTransfer)
+	authRoutes.POST("/transfers/:id/reverse", idempotency(s.store), s.reverseTransfer)
+
+	authRoutes.POST("/holds", idempotency(s.store), s.createHold)
+	authRoutes.GET("/holds/:id", s.getHold)
+	authRoutes.POST("/holds/:id/capture", idempotency(s.store), s.captureHold)
+	authRoutes.POST("/holds/:id/void", s.voidHold)
+
+	authRoutes.POST("/scheduled-transfers", idempotency(s.store), s.createScheduledTransfer)
+	authRoutes.GET("/scheduled-transfers", s.listScheduledTransfers)
+	authRoutes.GET("/scheduled-transfers/:id", s.getScheduledTransfer)
+	authRoutes.PUT("/scheduled-transfers/:id", s.updateScheduledTransfer)
+	authRoutes.DELETE("/scheduled-transfers/:id", s.cancelScheduledTransfer)
+	authRoutes.GET("/scheduled-transfers/:id/runs", s.listScheduledTransferRuns)
+
+	authRoutes.GET("/products", s.listProducts)
+	authRoutes.PUT("/products/:code/interest", requireRole(constants.RoleAdmin), s.updateProductInterest)
+
+	authRoutes.GET("/fees", s.listFeeRules)
+	authRoutes.PUT("/fees/:currency/:product/:transfer_type", requireRole(constants.RoleAdmin), s.upsertFeeRule)
+
+	authRoutes.POST("/webhooks", s.createWebhook)
+	authRoutes.GET("/webhooks", s.listWebhooks)
+	authRoutes.GET("/webhooks/:id", s.getWebhook)
+	authRoutes.DELETE("/webhooks/:id", s.deleteWebhook)
+	authRoutes.GET("/webhooks/:id/deliveries", s.listWebhookDeliveries)
+	authRoutes.POST("/webhooks/:id/deliveries/:delivery_id/replay", s.replayWebhookDelivery)
 
-	authRoutes.POST("/transfers", s.createTransfer)
+	authRoutes.PUT("/rates", requireRole(constants.RoleAdmin), s.upsertRate)
+	authRoutes.PUT("/tiers/:tier/limits/:currency", requireRole(constants.RoleAdmin), s.upsertTierLimit)
 
 }
 
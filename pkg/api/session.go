@@ -0,0 +1,196 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+var (
+	errSessionBlocked   = errors.New("session is blocked")
+	errSessionExpired   = errors.New("session expired")
+	errSessionMismatch  = errors.New("mismatched session token")
+	errSessionWrongUser = errors.New("session doesn't belong to current user")
+)
+
+type sessionResponse struct {
+	ID        uuid.UUID `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	IsBlocked bool      `json:"is_blocked"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func getSessionResponse(session db.Session) sessionResponse {
+	return sessionResponse{
+		ID:        session.ID,
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		IsBlocked: session.IsBlocked,
+		ExpiresAt: session.ExpiresAt,
+		CreatedAt: session.CreatedAt,
+	}
+}
+
+// getRefreshSession verifies the refresh token and loads the session it was
+// issued for, writing the error response itself when either is invalid.
+func (s *Server) getRefreshSession(c *gin.Context, refreshToken string) (db.Session, bool) {
+	refreshPayload, err := token.VerifyTokenType(s.tokenMaker, refreshToken, token.RefreshToken)
+
+	if err != nil {
+		handleUnauthorized(c, err)
+		return db.Session{}, false
+	}
+
+	sessionID, err := uuid.Parse(refreshPayload.ID)
+
+	if err != nil {
+		handleUnauthorized(c, err)
+		return db.Session{}, false
+	}
+
+	session, err := s.store.GetSession(c, sessionID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handleNotFound(c, err)
+		} else {
+			handleInternalError(c, err)
+		}
+		return session, false
+	}
+
+	if session.RefreshToken != refreshToken || session.Username != refreshPayload.Username {
+		handleUnauthorized(c, errSessionMismatch)
+		return session, false
+	}
+
+	return session, true
+}
+
+type renewAccessTokenPayload struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type renewAccessTokenResponse struct {
+	AccessToken          string    `json:"access_token"`
+	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+}
+
+func (s *Server) renewAccessToken(c *gin.Context) {
+	var payload renewAccessTokenPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	session, ok := s.getRefreshSession(c, payload.RefreshToken)
+
+	if !ok {
+		return
+	}
+
+	if session.IsBlocked {
+		handleUnauthorized(c, errSessionBlocked)
+		return
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		handleUnauthorized(c, errSessionExpired)
+		return
+	}
+
+	// the role is read again so a role change applies from the next renewal
+	user, err := s.store.GetUser(c, session.Username)
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, token.AccessToken, s.config.AccessTokenDuration)
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, renewAccessTokenResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessPayload.ExpiresAt.Time,
+	})
+}
+
+type logoutUserPayload struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+func (s *Server) logoutUser(c *gin.Context) {
+	var payload logoutUserPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	session, ok := s.getRefreshSession(c, payload.RefreshToken)
+
+	if !ok {
+		return
+	}
+
+	if session.Username != getAuthCtx(c).Username {
+		handleUnauthorized(c, errSessionWrongUser)
+		return
+	}
+
+	if err := s.store.BlockSession(c, session.ID); err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type listSessionsQuery struct {
+	PageID   int64 `form:"page_id" binding:"required,min=1"`
+	PageSize int64 `form:"page_size" binding:"required,min=5,max=20"`
+}
+
+func (s *Server) listSessions(c *gin.Context) {
+	var query listSessionsQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	arg := db.ListSessionsParams{
+		Username: getAuthCtx(c).Username,
+		Offset:   int32((query.PageID - 1) * query.PageSize),
+		Limit:    int32(query.PageSize),
+	}
+
+	sessions, err := s.store.ListSessions(c, arg)
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	response := make([]sessionResponse, 0, len(sessions))
+
+	for _, session := range sessions {
+		response = append(response, getSessionResponse(session))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
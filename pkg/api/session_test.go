@@ -0,0 +1,294 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/aseerkt/go-simple-bank/pkg/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func createRandomSession(t *testing.T, s *Server, username string) db.Session {
+	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(username, constants.RoleCustomer, token.RefreshToken, time.Hour)
+	require.NoError(t, err)
+
+	return db.Session{
+		ID:           uuid.MustParse(refreshPayload.ID),
+		Username:     username,
+		RefreshToken: refreshToken,
+		ExpiresAt:    refreshPayload.ExpiresAt.Time,
+	}
+}
+
+func TestRenewAccessTokenAPI(t *testing.T) {
+	user, _ := createRandomUser(t)
+
+	testCases := []struct {
+		name          string
+		buildStub     func(store *mockdb.MockStore, session db.Session)
+		body          func(t *testing.T, s *Server, session db.Session) gin.H
+		checkResponse func(t *testing.T, r *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Ok",
+			body: func(t *testing.T, s *Server, session db.Session) gin.H {
+				return gin.H{"refresh_token": session.RefreshToken}
+			},
+			buildStub: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(session.Username)).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, r.Code)
+
+				var response renewAccessTokenResponse
+				err := json.Unmarshal(r.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.NotEmpty(t, response.AccessToken)
+				require.NotZero(t, response.AccessTokenExpiresAt)
+			},
+		},
+		{
+			name: "InvalidToken",
+			body: func(t *testing.T, s *Server, session db.Session) gin.H {
+				return gin.H{"refresh_token": "invalid_token123"}
+			},
+			buildStub: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, r.Code)
+			},
+		},
+		{
+			name: "AccessToken",
+			body: func(t *testing.T, s *Server, session db.Session) gin.H {
+				accessToken, _, err := s.tokenMaker.CreateToken(session.Username, constants.RoleCustomer, token.AccessToken, time.Hour)
+				require.NoError(t, err)
+				return gin.H{"refresh_token": accessToken}
+			},
+			buildStub: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, r.Code)
+			},
+		},
+		{
+			name: "SessionNotFound",
+			body: func(t *testing.T, s *Server, session db.Session) gin.H {
+				return gin.H{"refresh_token": session.RefreshToken}
+			},
+			buildStub: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(db.Session{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, r.Code)
+			},
+		},
+		{
+			name: "BlockedSession",
+			body: func(t *testing.T, s *Server, session db.Session) gin.H {
+				return gin.H{"refresh_token": session.RefreshToken}
+			},
+			buildStub: func(store *mockdb.MockStore, session db.Session) {
+				session.IsBlocked = true
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, r.Code)
+			},
+		},
+		{
+			name: "MismatchedToken",
+			body: func(t *testing.T, s *Server, session db.Session) gin.H {
+				return gin.H{"refresh_token": session.RefreshToken}
+			},
+			buildStub: func(store *mockdb.MockStore, session db.Session) {
+				session.RefreshToken = "some_other_token"
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, r.Code)
+			},
+		},
+		{
+			name: "ExpiredSession",
+			body: func(t *testing.T, s *Server, session db.Session) gin.H {
+				return gin.H{"refresh_token": session.RefreshToken}
+			},
+			buildStub: func(store *mockdb.MockStore, session db.Session) {
+				session.ExpiresAt = time.Now().Add(-time.Minute)
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			server := newTestServer(t, store)
+			server.LoadRoutes()
+
+			session := createRandomSession(t, server, user.Username)
+			tc.buildStub(store, session)
+
+			body, err := json.Marshal(tc.body(t, server, session))
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/tokens/renew_access", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLogoutUserAPI(t *testing.T) {
+	user, _ := createRandomUser(t)
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, s *Server, r *http.Request)
+		buildStub     func(store *mockdb.MockStore, session db.Session)
+		checkResponse func(t *testing.T, r *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Ok",
+			setupAuth: getAuthMiddleware(user.Username),
+			buildStub: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, r.Code)
+			},
+		},
+		{
+			name:      "OtherUsersSession",
+			setupAuth: getAuthMiddleware("alfred"),
+			buildStub: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, r.Code)
+			},
+		},
+		{
+			name: "RefreshTokenAsBearer",
+			setupAuth: func(t *testing.T, s *Server, r *http.Request) {
+				refreshToken, _, err := s.tokenMaker.CreateToken(user.Username, constants.RoleCustomer, token.RefreshToken, time.Hour)
+				require.NoError(t, err)
+				r.Header.Set("Authorization", "Bearer "+refreshToken)
+			},
+			buildStub: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, r.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			setupAuth: getAuthMiddleware(user.Username),
+			buildStub: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, r *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, r.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			server := newTestServer(t, store)
+			server.LoadRoutes()
+
+			session := createRandomSession(t, server, user.Username)
+			tc.buildStub(store, session)
+
+			body, err := json.Marshal(gin.H{"refresh_token": session.RefreshToken})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/logout", bytes.NewReader(body))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, server, request)
+
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListSessionsAPI(t *testing.T) {
+	user, _ := createRandomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	server := newTestServer(t, store)
+	server.LoadRoutes()
+
+	sessions := []db.Session{
+		createRandomSession(t, server, user.Username),
+		createRandomSession(t, server, user.Username),
+	}
+
+	arg := db.ListSessionsParams{
+		Username: user.Username,
+		Limit:    5,
+		Offset:   0,
+	}
+
+	store.EXPECT().ListSessions(gomock.Any(), gomock.Eq(arg)).Times(1).Return(sessions, nil)
+
+	request, err := http.NewRequest(http.MethodGet, "/users/sessions?page_id=1&page_size=5", nil)
+	require.NoError(t, err)
+
+	getAuthMiddleware(user.Username)(t, server, request)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotContains(t, recorder.Body.String(), "refresh_token")
+
+	var response []sessionResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	require.NoError(t, err)
+	require.Len(t, response, len(sessions))
+	require.Equal(t, sessions[0].ID, response[0].ID)
+}
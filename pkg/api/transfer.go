@@ -2,17 +2,82 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"time"
 
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
 	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/fx"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/aseerkt/go-simple-bank/pkg/service"
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 )
 
+type transferResponse struct {
+	ID            int64       `json:"id"`
+	FromAccountID int64       `json:"from_account_id"`
+	ToAccountID   int64       `json:"to_account_id"`
+	Amount        money.Money `json:"amount"`
+	ToAmount      money.Money `json:"to_amount"`
+	FxRate        string      `json:"fx_rate"`
+	FxSpreadBps   int32       `json:"fx_spread_bps"`
+	ReversalOf    *int64      `json:"reversal_of"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+func getTransferResponse(transfer db.Transfer) transferResponse {
+	response := transferResponse{
+		ID:            transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        money.New(transfer.Amount, transfer.Currency),
+		ToAmount:      money.New(transfer.ToAmount, transfer.ToCurrency),
+		FxRate:        fx.FormatRate(transfer.FxRate),
+		FxSpreadBps:   transfer.FxSpreadBps,
+		CreatedAt:     transfer.CreatedAt,
+	}
+
+	if transfer.ReversalOf.Valid {
+		response.ReversalOf = &transfer.ReversalOf.Int64
+	}
+
+	return response
+}
+
+type transferTxResponse struct {
+	Transfer    transferResponse `json:"transfer"`
+	FromAccount accountResponse  `json:"from_account"`
+	ToAccount   accountResponse  `json:"to_account"`
+	FromEntry   entryResponse    `json:"from_entry"`
+	ToEntry     entryResponse    `json:"to_entry"`
+	Fees        []feeResponse    `json:"fees"`
+}
+
+func getTransferTxResponse(result db.TransferTxResult) transferTxResponse {
+	fees := make([]feeResponse, len(result.Fees))
+
+	for i, fee := range result.Fees {
+		fees[i] = getFeeResponse(fee)
+	}
+
+	return transferTxResponse{
+		Transfer:    getTransferResponse(result.Transfer),
+		FromAccount: getAccountResponse(result.FromAccount),
+		ToAccount:   getAccountResponse(result.ToAccount),
+		FromEntry:   getEntryResponse(result.FromEntry, result.FromAccount.Currency),
+		ToEntry:     getEntryResponse(result.ToEntry, result.ToAccount.Currency),
+		Fees:        fees,
+	}
+}
+
 type createTransferPayload struct {
 	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
-	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	Amount        string `json:"amount" binding:"required"`
 	Currency      string `json:"currency" binding:"required,currency"`
 }
 
@@ -24,49 +89,370 @@ func (s *Server) createTransfer(c *gin.Context) {
 		return
 	}
 
-	if !s.hasAccountWithCurrency(c, payload.FromAccountID, payload.Currency) {
-		return
-	}
+	amount, ok := parsePositiveAmount(c, payload.Currency, payload.Amount)
 
-	if !s.hasAccountWithCurrency(c, payload.ToAccountID, payload.Currency) {
+	if !ok {
 		return
 	}
 
-	arg := db.CreateTransferParams{
+	arg, err := service.PrepareTransfer(c, s.store, s.rateProvider, s.config.FxSpreadBps, service.TransferRequest{
+		Username:      getAuthCtx(c).Username,
 		FromAccountID: payload.FromAccountID,
 		ToAccountID:   payload.ToAccountID,
-		Amount:        payload.Amount,
+		Amount:        amount,
+	})
+
+	if err != nil {
+		handleServiceError(c, err)
+		return
+	}
+
+	if key := c.GetString(idempotencyKeyCtxKey); key != "" {
+		arg.IdempotencyKey = sql.NullString{String: transferIdempotencyKey(getAuthCtx(c).Username, key), Valid: true}
+
+		if s.replayTransfer(c, arg.IdempotencyKey) {
+			return
+		}
 	}
 
-	result, err := s.store.TransferTx(c, arg)
+	result, err := service.Transfer(c, s.store, arg)
 
 	if err != nil {
-		handleInternalError(c, err)
+		var limitErr *db.LimitError
+		var pqError *pq.Error
+
+		switch {
+		// an earlier attempt of the request made the transfer since the lookup
+		case arg.IdempotencyKey.Valid && errors.As(err, &pqError) && pqError.Code.Name() == "unique_violation":
+			if !s.replayTransfer(c, arg.IdempotencyKey) {
+				handleInternalError(c, err)
+			}
+		case errors.As(err, &limitErr):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "limit": limitErr})
+		default:
+			handleServiceError(c, err)
+		}
 		return
 	}
 
-	c.JSON(http.StatusCreated, result)
+	c.JSON(http.StatusCreated, getTransferTxResponse(result))
+}
+
+// transferIdempotencyKey is what a transfer made for a request carrying an
+// Idempotency-Key is stored under. Keys are only unique per user.
+func transferIdempotencyKey(username, key string) string {
+	return fmt.Sprintf("api:%s:%s", username, key)
 }
 
-func (s *Server) hasAccountWithCurrency(c *gin.Context, accountId int64, currency string) bool {
+// replayTransfer answers with the transfer made under idempotencyKey by an
+// earlier attempt of the request. It returns false without answering when
+// there's no such transfer.
+func (s *Server) replayTransfer(c *gin.Context, idempotencyKey sql.NullString) bool {
+	transfer, err := s.store.GetTransferByIdempotencyKey(c, idempotencyKey)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false
+		}
+
+		handleInternalError(c, err)
+		return true
+	}
+
+	result := db.TransferTxResult{Transfer: transfer}
+
+	if result.FromAccount, err = s.store.GetAccount(c, transfer.FromAccountID); err != nil {
+		handleInternalError(c, err)
+		return true
+	}
+
+	if result.ToAccount, err = s.store.GetAccount(c, transfer.ToAccountID); err != nil {
+		handleInternalError(c, err)
+		return true
+	}
+
+	entries, err := s.store.ListTransferEntries(c, sql.NullInt64{Int64: transfer.ID, Valid: true})
+
+	if err != nil {
+		handleInternalError(c, err)
+		return true
+	}
+
+	// the transfer's own entries come before those of its fees and fx legs
+	for _, entry := range entries {
+		switch {
+		case entry.AccountID == transfer.FromAccountID && result.FromEntry.ID == 0:
+			result.FromEntry = entry
+		case entry.AccountID == transfer.ToAccountID && result.ToEntry.ID == 0:
+			result.ToEntry = entry
+		}
+	}
+
+	if result.Fees, err = s.store.ListTransferFees(c, transfer.ID); err != nil {
+		handleInternalError(c, err)
+		return true
+	}
+
+	c.JSON(http.StatusCreated, getTransferTxResponse(result))
+	return true
+}
+
+func (s *Server) loadAccount(c *gin.Context, accountId int64) (db.Account, bool) {
 	account, err := s.store.GetAccount(c, accountId)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			handleNotFound(c, err)
-			return false
+			return account, false
 		}
 
 		handleInternalError(c, err)
-		return false
+		return account, false
 	}
 
+	return account, true
+}
+
+// checkAccountCurrency must only run once the caller is known to own account,
+// or the error would tell anyone the currency of any account.
+func checkAccountCurrency(c *gin.Context, account db.Account, currency string) bool {
 	if account.Currency != currency {
-		err := fmt.Errorf("account %d currency mismatch: %s vs %s", accountId, account.Currency, currency)
+		err := fmt.Errorf("account %d currency mismatch: %s vs %s", account.ID, account.Currency, currency)
 		handleBadRequest(c, err)
 		return false
-
 	}
 
 	return true
 }
+
+type getTransferUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (s *Server) getTransfer(c *gin.Context) {
+	var uri getTransferUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	transfer, err := s.store.GetTransfer(c, uri.ID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handleNotFound(c, err)
+		} else {
+			handleInternalError(c, err)
+		}
+		return
+	}
+
+	authPayload := getAuthCtx(c)
+
+	if service.HasRole(authPayload, constants.RoleTeller, constants.RoleAdmin) {
+		c.JSON(http.StatusOK, getTransferResponse(transfer))
+		return
+	}
+
+	for _, accountID := range []int64{transfer.FromAccountID, transfer.ToAccountID} {
+		account, err := s.store.GetAccount(c, accountID)
+
+		if err != nil {
+			handleInternalError(c, err)
+			return
+		}
+
+		if account.Owner == authPayload.Username {
+			c.JSON(http.StatusOK, getTransferResponse(transfer))
+			return
+		}
+	}
+
+	denyAccess(c, fmt.Sprintf("read transfer %d", transfer.ID))
+}
+
+type reverseTransferUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type reverseTransferPayload struct {
+	// Amount is optional and in the recipient's currency; leaving it out
+	// reverses what's left of the transfer.
+	Amount string `json:"amount"`
+}
+
+func (s *Server) reverseTransfer(c *gin.Context) {
+	var uri reverseTransferUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	var payload reverseTransferPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil && !errors.Is(err, io.EOF) {
+		handleBadRequest(c, err)
+		return
+	}
+
+	transfer, err := s.store.GetTransfer(c, uri.ID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handleNotFound(c, err)
+		} else {
+			handleInternalError(c, err)
+		}
+		return
+	}
+
+	authPayload := getAuthCtx(c)
+
+	if !service.HasRole(authPayload, constants.RoleAdmin) {
+		recipient, err := s.store.GetAccount(c, transfer.ToAccountID)
+
+		if err != nil {
+			handleInternalError(c, err)
+			return
+		}
+
+		if recipient.Owner != authPayload.Username {
+			denyAccess(c, fmt.Sprintf("reverse transfer %d received by %s", transfer.ID, recipient.Owner))
+			return
+		}
+	}
+
+	var amount money.Money
+
+	if payload.Amount != "" {
+		var ok bool
+
+		if amount, ok = parsePositiveAmount(c, transfer.ToCurrency, payload.Amount); !ok {
+			return
+		}
+	}
+
+	result, err := s.store.ReverseTransferTx(c, db.ReverseTransferTxParams{
+		TransferID: transfer.ID,
+		Amount:     amount,
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrAlreadyReversed):
+			handleConflict(c, err)
+		case errors.Is(err, db.ErrNotReversible), errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, money.ErrOverflow),
+			errors.Is(err, db.ErrAccountFrozen), errors.Is(err, db.ErrAccountClosed):
+			handleUnprocessableEntity(c, err)
+		default:
+			handleInternalError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, getTransferTxResponse(result))
+}
+
+type listTransfersUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listTransfersQuery struct {
+	Direction      string    `form:"direction" binding:"omitempty,oneof=incoming outgoing"`
+	CounterpartyID int64     `form:"counterparty_id" binding:"omitempty,min=1"`
+	MinAmount      string    `form:"min_amount"`
+	MaxAmount      string    `form:"max_amount"`
+	StartTime      time.Time `form:"start_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTime        time.Time `form:"end_time" time_format:"2006-01-02T15:04:05Z07:00" binding:"omitempty,gtfield=StartTime"`
+	BeforeID       int64     `form:"before_id" binding:"omitempty,min=1"`
+	PageSize       int64     `form:"page_size" binding:"required,min=5,max=20"`
+}
+
+type listTransfersResponse struct {
+	Transfers    []transferResponse `json:"transfers"`
+	NextBeforeID *int64             `json:"next_before_id"`
+}
+
+func (s *Server) listTransfers(c *gin.Context) {
+	var uri listTransfersUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	var query listTransfersQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	account, ok := s.getOwnedAccount(c, uri.ID)
+
+	if !ok {
+		return
+	}
+
+	// Amount bounds are in the account's currency and match the side of each
+	// transfer the account is on.
+	var minAmount, maxAmount money.Money
+
+	if query.MinAmount != "" {
+		if minAmount, ok = parsePositiveAmount(c, account.Currency, query.MinAmount); !ok {
+			return
+		}
+	}
+
+	if query.MaxAmount != "" {
+		if maxAmount, ok = parsePositiveAmount(c, account.Currency, query.MaxAmount); !ok {
+			return
+		}
+	}
+
+	if !minAmount.IsZero() && !maxAmount.IsZero() {
+		cmp, err := maxAmount.Cmp(minAmount)
+
+		if err == nil && cmp < 0 {
+			err = fmt.Errorf("max_amount %s is less than min_amount %s", maxAmount, minAmount)
+		}
+
+		if err != nil {
+			handleBadRequest(c, err)
+			return
+		}
+	}
+
+	arg := db.ListTransfersParams{
+		AccountID:      uri.ID,
+		Direction:      sql.NullString{String: query.Direction, Valid: query.Direction != ""},
+		CounterpartyID: sql.NullInt64{Int64: query.CounterpartyID, Valid: query.CounterpartyID != 0},
+		MinAmount:      sql.NullInt64{Int64: minAmount.Amount, Valid: !minAmount.IsZero()},
+		MaxAmount:      sql.NullInt64{Int64: maxAmount.Amount, Valid: !maxAmount.IsZero()},
+		StartTime:      sql.NullTime{Time: query.StartTime, Valid: !query.StartTime.IsZero()},
+		EndTime:        sql.NullTime{Time: query.EndTime, Valid: !query.EndTime.IsZero()},
+		BeforeID:       sql.NullInt64{Int64: query.BeforeID, Valid: query.BeforeID != 0},
+		PageSize:       int32(query.PageSize),
+	}
+
+	transfers, err := s.store.ListTransfers(c, arg)
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	response := listTransfersResponse{Transfers: make([]transferResponse, len(transfers))}
+
+	for i, transfer := range transfers {
+		response.Transfers[i] = getTransferResponse(transfer)
+	}
+
+	if len(transfers) == int(query.PageSize) {
+		response.NextBeforeID = &transfers[len(transfers)-1].ID
+	}
+
+	c.JSON(http.StatusOK, response)
+}
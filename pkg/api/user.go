@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/token"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -16,6 +18,8 @@ type userResponse struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
 	FullName string `json:"full_name"`
+	Role     string `json:"role"`
+	Tier     string `json:"tier"`
 }
 
 func getUserResponse(user db.User) *userResponse {
@@ -23,6 +27,8 @@ func getUserResponse(user db.User) *userResponse {
 		Username: user.Username,
 		Email:    user.Email,
 		FullName: user.FullName,
+		Role:     user.Role,
+		Tier:     user.Tier,
 	}
 }
 
@@ -55,7 +61,7 @@ func (s *Server) createUser(c *gin.Context) {
 		HashedPassword: string(hashedPassword),
 	}
 
-	user, err := s.store.CreateUser(c, arg)
+	user, err := s.store.CreateUserTx(c, arg)
 
 	if err != nil {
 		if pqError, ok := err.(*pq.Error); ok {
@@ -77,6 +83,15 @@ type loginUserPayload struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type loginUserResponse struct {
+	SessionID             uuid.UUID     `json:"session_id"`
+	AccessToken           string        `json:"access_token"`
+	AccessTokenExpiresAt  time.Time     `json:"access_token_expires_at"`
+	RefreshToken          string        `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time     `json:"refresh_token_expires_at"`
+	User                  *userResponse `json:"user"`
+}
+
 func (s *Server) loginUser(c *gin.Context) {
 	var payload loginUserPayload
 	if err := c.ShouldBindJSON(&payload); err != nil {
@@ -100,15 +115,128 @@ func (s *Server) loginUser(c *gin.Context) {
 		return
 	}
 
-	token, err := s.tokenMaker.CreateToken(user.Username, 12*time.Hour)
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, token.AccessToken, s.config.AccessTokenDuration)
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, token.RefreshToken, s.config.RefreshTokenDuration)
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	sessionID, err := uuid.Parse(refreshPayload.ID)
 
 	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	session, err := s.store.CreateSession(c, db.CreateSessionParams{
+		ID:           sessionID,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		UserAgent:    c.Request.UserAgent(),
+		ClientIp:     c.ClientIP(),
+		IsBlocked:    false,
+		ExpiresAt:    refreshPayload.ExpiresAt.Time,
+	})
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loginUserResponse{
+		SessionID:             session.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessPayload.ExpiresAt.Time,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: refreshPayload.ExpiresAt.Time,
+		User:                  getUserResponse(user),
+	})
+}
+
+type updateUserRoleUri struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+type updateUserRolePayload struct {
+	Role string `json:"role" binding:"required,oneof=customer teller admin"`
+}
+
+func (s *Server) updateUserRole(c *gin.Context) {
+	var uri updateUserRoleUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	var payload updateUserRolePayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
 		handleBadRequest(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-		"user":  getUserResponse(user),
+	user, err := s.store.UpdateUserRole(c, db.UpdateUserRoleParams{
+		Username: uri.Username,
+		Role:     payload.Role,
 	})
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handleNotFound(c, err)
+			return
+		}
+		handleInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getUserResponse(user))
+}
+
+type updateUserTierUri struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+type updateUserTierPayload struct {
+	Tier string `json:"tier" binding:"required,oneof=standard premium"`
+}
+
+func (s *Server) updateUserTier(c *gin.Context) {
+	var uri updateUserTierUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	var payload updateUserTierPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	user, err := s.store.UpdateUserTier(c, db.UpdateUserTierParams{
+		Username: uri.Username,
+		Tier:     payload.Tier,
+	})
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handleNotFound(c, err)
+			return
+		}
+		handleInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getUserResponse(user))
 }
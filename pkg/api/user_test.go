@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
 	"github.com/aseerkt/go-simple-bank/pkg/db"
 	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
 	"github.com/brianvoe/gofakeit/v7"
@@ -29,6 +30,8 @@ func createRandomUser(t *testing.T) (db.User, string) {
 		FullName:       gofakeit.Name(),
 		Email:          gofakeit.Email(),
 		HashedPassword: string(hashedPassword),
+		Role:           constants.RoleCustomer,
+		Tier:           db.TierStandard,
 	}, password
 }
 
@@ -51,7 +54,7 @@ func TestCreateUserAPI(t *testing.T) {
 				"password":  password,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+				store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
 			},
 
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -77,7 +80,7 @@ func TestCreateUserAPI(t *testing.T) {
 				"password":  password,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, recorder.Code, http.StatusBadRequest)
@@ -95,7 +98,7 @@ func TestCreateUserAPI(t *testing.T) {
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
 
-			server := newTestServer(store)
+			server := newTestServer(t, store)
 
 			url := "/users"
 
@@ -137,9 +140,24 @@ func TestLoginUser(t *testing.T) {
 			},
 			buildStubs: func(ms *mockdb.MockStore) {
 				ms.EXPECT().GetUser(gomock.Any(), user.Username).Times(1).Return(user, nil)
+				ms.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateSessionParams) (db.Session, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.NotEmpty(t, arg.RefreshToken)
+						require.False(t, arg.IsBlocked)
+						return db.Session{ID: arg.ID, Username: arg.Username, RefreshToken: arg.RefreshToken, ExpiresAt: arg.ExpiresAt}, nil
+					})
 			},
 			checkResponse: func(rr *httptest.ResponseRecorder) {
 				require.Equal(t, rr.Code, http.StatusOK)
+
+				var response loginUserResponse
+				err := json.Unmarshal(rr.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.NotEmpty(t, response.AccessToken)
+				require.NotEmpty(t, response.RefreshToken)
+				require.NotZero(t, response.SessionID)
+				require.True(t, response.AccessTokenExpiresAt.Before(response.RefreshTokenExpiresAt))
 			},
 		},
 		{
@@ -202,7 +220,7 @@ func TestLoginUser(t *testing.T) {
 			defer ctrl.Finish()
 
 			store := mockdb.NewMockStore(ctrl)
-			server := newTestServer(store)
+			server := newTestServer(t, store)
 			server.LoadRoutes()
 
 			tc.buildStubs(store)
@@ -1,14 +1,43 @@
 package api
 
 import (
-	"github.com/aseerkt/go-simple-bank/pkg/utils"
+	"fmt"
+
+	"github.com/aseerkt/go-simple-bank/pkg/currency"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 )
 
 var validCurrency validator.Func = func(fl validator.FieldLevel) bool {
-	if currency, ok := fl.Field().Interface().(string); ok {
-		return utils.IsSupportedCurrency(currency)
+	if code, ok := fl.Field().Interface().(string); ok {
+		return currency.IsSupported(code)
 	}
 
 	return false
 }
+
+// parseAmount reads a decimal amount in the currency's minor units, writing a
+// bad request response when it isn't valid for the currency.
+func parseAmount(c *gin.Context, code string, value string) (money.Money, bool) {
+	amount, err := money.Parse(code, value)
+
+	if err != nil {
+		handleBadRequest(c, err)
+		return money.Money{}, false
+	}
+
+	return amount, true
+}
+
+// parsePositiveAmount is parseAmount for amounts that must be above zero.
+func parsePositiveAmount(c *gin.Context, code string, value string) (money.Money, bool) {
+	amount, ok := parseAmount(c, code, value)
+
+	if ok && !amount.IsPositive() {
+		handleBadRequest(c, fmt.Errorf("%w: amount must be greater than zero", currency.ErrInvalidAmount))
+		return money.Money{}, false
+	}
+
+	return amount, ok
+}
@@ -0,0 +1,306 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/service"
+	"github.com/aseerkt/go-simple-bank/pkg/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+var errDeliveryPending = errors.New("webhook delivery is already pending")
+
+// webhookResponse leaves out the secret, which only the owner needs and
+// already has.
+type webhookResponse struct {
+	ID         int64     `json:"id"`
+	Owner      string    `json:"owner"`
+	Url        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func getWebhookResponse(webhook db.Webhook) webhookResponse {
+	return webhookResponse{
+		ID:         webhook.ID,
+		Owner:      webhook.Owner,
+		Url:        webhook.Url,
+		EventTypes: webhook.EventTypes,
+		CreatedAt:  webhook.CreatedAt,
+	}
+}
+
+type webhookDeliveryResponse struct {
+	ID             int64      `json:"id"`
+	EventID        int64      `json:"event_id"`
+	Status         string     `json:"status"`
+	Attempts       int32      `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastStatusCode *int32     `json:"last_status_code"`
+	LastError      *string    `json:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func getWebhookDeliveryResponse(delivery db.WebhookDelivery) webhookDeliveryResponse {
+	response := webhookDeliveryResponse{
+		ID:            delivery.ID,
+		EventID:       delivery.EventID,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		NextAttemptAt: delivery.NextAttemptAt,
+		CreatedAt:     delivery.CreatedAt,
+	}
+
+	if delivery.LastStatusCode.Valid {
+		response.LastStatusCode = &delivery.LastStatusCode.Int32
+	}
+
+	if delivery.LastError.Valid {
+		response.LastError = &delivery.LastError.String
+	}
+
+	if delivery.DeliveredAt.Valid {
+		response.DeliveredAt = &delivery.DeliveredAt.Time
+	}
+
+	return response
+}
+
+type createWebhookPayload struct {
+	Url string `json:"url" binding:"required,url"`
+	// Secret signs every delivery; see the Webhook-Signature header.
+	Secret string `json:"secret" binding:"required,min=16,max=256"`
+	// EventTypes filters the events sent. Leaving it out sends all of them.
+	EventTypes []string `json:"event_types"`
+}
+
+// createWebhook registers an endpoint to be sent the events about the
+// caller's accounts.
+func (s *Server) createWebhook(c *gin.Context) {
+	var payload createWebhookPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	if err := webhook.ValidateURL(payload.Url); err != nil {
+		handleBadRequest(c, fmt.Errorf("url %q: %w", payload.Url, err))
+		return
+	}
+
+	eventTypes := []string{}
+
+	for _, eventType := range payload.EventTypes {
+		if !slices.Contains(db.WebhookEvents, eventType) {
+			handleBadRequest(c, fmt.Errorf("unknown event type %q: must be one of %v", eventType, db.WebhookEvents))
+			return
+		}
+
+		if !slices.Contains(eventTypes, eventType) {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+
+	webhook, err := s.store.CreateWebhook(c, db.CreateWebhookParams{
+		Owner:      getAuthCtx(c).Username,
+		Url:        payload.Url,
+		Secret:     payload.Secret,
+		EventTypes: eventTypes,
+	})
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, getWebhookResponse(webhook))
+}
+
+func (s *Server) listWebhooks(c *gin.Context) {
+	webhooks, err := s.store.ListWebhooks(c, getAuthCtx(c).Username)
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	response := make([]webhookResponse, len(webhooks))
+
+	for i, webhook := range webhooks {
+		response[i] = getWebhookResponse(webhook)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type webhookUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// loadWebhook loads the webhook in the uri, which only its owner or an admin
+// may act on.
+func (s *Server) loadWebhook(c *gin.Context, action string) (db.Webhook, bool) {
+	var uri webhookUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		handleBadRequest(c, err)
+		return db.Webhook{}, false
+	}
+
+	webhook, err := s.store.GetWebhook(c, uri.ID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handleNotFound(c, err)
+		} else {
+			handleInternalError(c, err)
+		}
+		return webhook, false
+	}
+
+	authPayload := getAuthCtx(c)
+
+	if webhook.Owner != authPayload.Username && !service.HasRole(authPayload, constants.RoleAdmin) {
+		denyAccess(c, fmt.Sprintf("%s webhook %d owned by %s", action, webhook.ID, webhook.Owner))
+		return webhook, false
+	}
+
+	return webhook, true
+}
+
+func (s *Server) getWebhook(c *gin.Context) {
+	webhook, ok := s.loadWebhook(c, "access")
+
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, getWebhookResponse(webhook))
+}
+
+// deleteWebhook removes a webhook along with its deliveries, including the
+// ones still pending.
+func (s *Server) deleteWebhook(c *gin.Context) {
+	webhook, ok := s.loadWebhook(c, "delete")
+
+	if !ok {
+		return
+	}
+
+	if err := s.store.DeleteWebhook(c, webhook.ID); err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type listWebhookDeliveriesQuery struct {
+	// Status of dead lists the dead letters.
+	Status   string `form:"status" binding:"omitempty,oneof=pending delivered dead"`
+	BeforeID int64  `form:"before_id" binding:"omitempty,min=1"`
+	PageSize int64  `form:"page_size" binding:"required,min=5,max=20"`
+}
+
+type listWebhookDeliveriesResponse struct {
+	Deliveries   []webhookDeliveryResponse `json:"deliveries"`
+	NextBeforeID *int64                    `json:"next_before_id"`
+}
+
+// listWebhookDeliveries lists the deliveries of a webhook, newest first.
+func (s *Server) listWebhookDeliveries(c *gin.Context) {
+	webhook, ok := s.loadWebhook(c, "access")
+
+	if !ok {
+		return
+	}
+
+	var query listWebhookDeliveriesQuery
+
+	if err := c.ShouldBindQuery(&query); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	deliveries, err := s.store.ListWebhookDeliveries(c, db.ListWebhookDeliveriesParams{
+		WebhookID: webhook.ID,
+		Status:    sql.NullString{String: query.Status, Valid: query.Status != ""},
+		BeforeID:  sql.NullInt64{Int64: query.BeforeID, Valid: query.BeforeID != 0},
+		PageSize:  int32(query.PageSize),
+	})
+
+	if err != nil {
+		handleInternalError(c, err)
+		return
+	}
+
+	response := listWebhookDeliveriesResponse{Deliveries: make([]webhookDeliveryResponse, len(deliveries))}
+
+	for i, delivery := range deliveries {
+		response.Deliveries[i] = getWebhookDeliveryResponse(delivery)
+	}
+
+	if len(deliveries) == int(query.PageSize) {
+		response.NextBeforeID = &deliveries[len(deliveries)-1].ID
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type webhookDeliveryUri struct {
+	DeliveryID int64 `uri:"delivery_id" binding:"required,min=1"`
+}
+
+// replayWebhookDelivery sends a dead or delivered delivery again, with a
+// fresh set of attempts.
+func (s *Server) replayWebhookDelivery(c *gin.Context) {
+	webhook, ok := s.loadWebhook(c, "replay deliveries of")
+
+	if !ok {
+		return
+	}
+
+	var uri webhookDeliveryUri
+
+	if err := c.ShouldBindUri(&uri); err != nil {
+		handleBadRequest(c, err)
+		return
+	}
+
+	delivery, err := s.store.GetWebhookDelivery(c, uri.DeliveryID)
+
+	if err == nil && delivery.WebhookID != webhook.ID {
+		err = sql.ErrNoRows
+	}
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handleNotFound(c, err)
+		} else {
+			handleInternalError(c, err)
+		}
+		return
+	}
+
+	delivery, err = s.store.ReplayWebhookDelivery(c, delivery.ID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handleUnprocessableEntity(c, errDeliveryPending)
+			return
+		}
+		handleInternalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getWebhookDeliveryResponse(delivery))
+}
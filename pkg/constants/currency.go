@@ -1,8 +0,0 @@
-package constants
-
-var Currency = map[string]string{
-	"USD": "USD",
-	"EUR": "EUR",
-	"CAD": "CAD",
-	"INR": "INR",
-}
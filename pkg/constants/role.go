@@ -0,0 +1,7 @@
+package constants
+
+const (
+	RoleCustomer = "customer"
+	RoleTeller   = "teller"
+	RoleAdmin    = "admin"
+)
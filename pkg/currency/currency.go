@@ -0,0 +1,134 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrUnknownCurrency = errors.New("unknown currency")
+	ErrInvalidAmount   = errors.New("invalid amount")
+)
+
+// Currency is an ISO 4217 currency as stored in the currencies table.
+type Currency struct {
+	Code        string
+	NumericCode int32
+	MinorUnits  int32
+	Enabled     bool
+}
+
+var amountPattern = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// registry holds every currency in the currencies table, enabled or not, so
+// that amounts in a disabled currency can still be formatted.
+var registry = struct {
+	sync.RWMutex
+	currencies map[string]Currency
+}{currencies: map[string]Currency{}}
+
+// Register replaces the registry with currencies.
+func Register(currencies ...Currency) {
+	byCode := make(map[string]Currency, len(currencies))
+
+	for _, currency := range currencies {
+		byCode[currency.Code] = currency
+	}
+
+	registry.Lock()
+	registry.currencies = byCode
+	registry.Unlock()
+}
+
+func Get(code string) (Currency, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	currency, ok := registry.currencies[code]
+	return currency, ok
+}
+
+// IsSupported reports whether new accounts and transfers may use code.
+func IsSupported(code string) bool {
+	currency, ok := Get(code)
+	return ok && currency.Enabled
+}
+
+// Codes returns the enabled currency codes in order.
+func Codes() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	var codes []string
+
+	for code, currency := range registry.currencies {
+		if currency.Enabled {
+			codes = append(codes, code)
+		}
+	}
+
+	slices.Sort(codes)
+
+	return codes
+}
+
+// FormatAmount writes an amount of minor units as a decimal string with as
+// many decimals as the currency has minor units, e.g. 1234 USD is "12.34".
+func FormatAmount(code string, amount int64) string {
+	currency, ok := Get(code)
+
+	if !ok || currency.MinorUnits == 0 {
+		return strconv.FormatInt(amount, 10)
+	}
+
+	sign := ""
+	abs := uint64(amount)
+
+	if amount < 0 {
+		sign = "-"
+		abs = -abs
+	}
+
+	digits := strconv.FormatUint(abs, 10)
+	units := int(currency.MinorUnits)
+
+	if len(digits) <= units {
+		digits = strings.Repeat("0", units-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-units] + "." + digits[len(digits)-units:]
+}
+
+// ParseAmount reads a decimal string into minor units of the currency. It
+// rejects more decimals than the currency has minor units.
+func ParseAmount(code string, value string) (int64, error) {
+	currency, ok := Get(code)
+
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, code)
+	}
+
+	if !amountPattern.MatchString(value) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	units := int(currency.MinorUnits)
+
+	if len(fraction) > units {
+		return 0, fmt.Errorf("%w: %s allows %d decimals, got %q", ErrInvalidAmount, code, units, value)
+	}
+
+	amount, err := strconv.ParseInt(whole+fraction+strings.Repeat("0", units-len(fraction)), 10, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is out of range", ErrInvalidAmount, value)
+	}
+
+	return amount, nil
+}
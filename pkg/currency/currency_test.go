@@ -0,0 +1,86 @@
+package currency
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testCurrencies = []Currency{
+	{Code: "USD", NumericCode: 840, MinorUnits: 2, Enabled: true},
+	{Code: "JPY", NumericCode: 392, MinorUnits: 0, Enabled: true},
+	{Code: "KWD", NumericCode: 414, MinorUnits: 3, Enabled: true},
+	{Code: "XTS", NumericCode: 963, MinorUnits: 2, Enabled: false},
+}
+
+func TestRegister(t *testing.T) {
+	Register(testCurrencies...)
+
+	require.True(t, IsSupported("USD"))
+	require.False(t, IsSupported("XTS"))
+	require.False(t, IsSupported("GBP"))
+	require.Equal(t, []string{"JPY", "KWD", "USD"}, Codes())
+
+	_, ok := Get("XTS")
+	require.True(t, ok)
+}
+
+func TestFormatAmount(t *testing.T) {
+	Register(testCurrencies...)
+
+	testCases := []struct {
+		code     string
+		amount   int64
+		expected string
+	}{
+		{"USD", 1234, "12.34"},
+		{"USD", 5, "0.05"},
+		{"USD", -5, "-0.05"},
+		{"USD", 0, "0.00"},
+		{"JPY", 1234, "1234"},
+		{"KWD", 1234, "1.234"},
+		{"KWD", -1, "-0.001"},
+		{"XTS", 1234, "12.34"},
+		{"GBP", 1234, "1234"},
+		{"USD", math.MinInt64, "-92233720368547758.08"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.expected, FormatAmount(tc.code, tc.amount), tc)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	Register(testCurrencies...)
+
+	testCases := []struct {
+		code     string
+		value    string
+		expected int64
+	}{
+		{"USD", "12.34", 1234},
+		{"USD", "12.3", 1230},
+		{"USD", "12", 1200},
+		{"USD", "-0.05", -5},
+		{"JPY", "1234", 1234},
+		{"KWD", "1.234", 1234},
+	}
+
+	for _, tc := range testCases {
+		amount, err := ParseAmount(tc.code, tc.value)
+		require.NoError(t, err, tc)
+		require.Equal(t, tc.expected, amount, tc)
+	}
+
+	for _, value := range []string{"", "abc", "1.", ".5", "1,000", "12.345", "1e3", "99999999999999999999"} {
+		_, err := ParseAmount("USD", value)
+		require.ErrorIs(t, err, ErrInvalidAmount, value)
+	}
+
+	_, err := ParseAmount("JPY", "12.5")
+	require.ErrorIs(t, err, ErrInvalidAmount)
+
+	_, err = ParseAmount("GBP", "12.50")
+	require.ErrorIs(t, err, ErrUnknownCurrency)
+}
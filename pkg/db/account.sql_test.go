@@ -2,7 +2,6 @@ package db
 
 import (
 	"context"
-	"database/sql"
 	"testing"
 	"time"
 
@@ -11,13 +10,22 @@ import (
 )
 
 func createTestAccount(t *testing.T) Account {
+	return createTestAccountWithCurrency(t, gofakeit.CurrencyShort())
+}
+
+func createTestAccountWithCurrency(t *testing.T, currency string) Account {
+	return createTestAccountWithProduct(t, currency, ProductChecking)
+}
+
+func createTestAccountWithProduct(t *testing.T, currency, product string) Account {
 
 	user := createTestUser(t)
 
 	arg := CreateAccountParams{
 		Owner:    user.Username,
-		Balance:  gofakeit.Int64(),
-		Currency: gofakeit.CurrencyShort(),
+		Balance:  int64(gofakeit.IntRange(1000, 1000000)),
+		Currency: currency,
+		Product:  product,
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -27,7 +35,9 @@ func createTestAccount(t *testing.T) Account {
 
 	require.Equal(t, arg.Owner, account.Owner)
 	require.Equal(t, arg.Balance, account.Balance)
+	require.Equal(t, arg.Balance, account.AvailableBalance)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.Equal(t, arg.Product, account.Product)
 
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
@@ -73,17 +83,52 @@ func TestUpdateAccount(t *testing.T) {
 	require.Equal(t, arg.Balance, account2.Balance)
 }
 
-func TestDeleteAccount(t *testing.T) {
+func TestUpdateAccountStatus(t *testing.T) {
 	account1 := createTestAccount(t)
+	require.Equal(t, AccountActive, account1.Status)
+
+	account2, err := testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:     account1.ID,
+		Status: AccountFrozen,
+	})
 
-	err := testQueries.DeleteAccount(context.Background(), account1.ID)
 	require.NoError(t, err)
+	require.Equal(t, AccountFrozen, account2.Status)
+	require.Equal(t, account1.Balance, account2.Balance)
 
-	account2, err := testQueries.GetAccount(context.Background(), account1.ID)
+	_, err = testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:     account1.ID,
+		Status: "deleted",
+	})
 
-	require.Empty(t, account2)
 	require.Error(t, err)
-	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestListAccountStatusChanges(t *testing.T) {
+	account := createTestAccount(t)
+
+	arg := CreateAccountStatusChangeParams{
+		AccountID:  account.ID,
+		FromStatus: AccountActive,
+		ToStatus:   AccountFrozen,
+		ChangedBy:  account.Owner,
+		Reason:     gofakeit.Sentence(5),
+	}
+
+	change, err := testQueries.CreateAccountStatusChange(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.Equal(t, arg.AccountID, change.AccountID)
+	require.Equal(t, arg.FromStatus, change.FromStatus)
+	require.Equal(t, arg.ToStatus, change.ToStatus)
+	require.Equal(t, arg.ChangedBy, change.ChangedBy)
+	require.Equal(t, arg.Reason, change.Reason)
+	require.NotZero(t, change.CreatedAt)
+
+	changes, err := testQueries.ListAccountStatusChanges(context.Background(), account.ID)
+
+	require.NoError(t, err)
+	require.Equal(t, []AccountStatusChange{change}, changes)
 }
 
 func TestListAccount(t *testing.T) {
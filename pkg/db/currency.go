@@ -0,0 +1,32 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aseerkt/go-simple-bank/pkg/currency"
+)
+
+// LoadCurrencies replaces the currency registry with the currencies table.
+func LoadCurrencies(ctx context.Context, q Querier) error {
+	currencies, err := q.ListCurrencies(ctx)
+
+	if err != nil {
+		return fmt.Errorf("cannot load currencies: %w", err)
+	}
+
+	registered := make([]currency.Currency, len(currencies))
+
+	for i, c := range currencies {
+		registered[i] = currency.Currency{
+			Code:        c.Code,
+			NumericCode: c.NumericCode,
+			MinorUnits:  c.MinorUnits,
+			Enabled:     c.Enabled,
+		}
+	}
+
+	currency.Register(registered...)
+
+	return nil
+}
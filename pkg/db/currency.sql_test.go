@@ -0,0 +1,32 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aseerkt/go-simple-bank/pkg/currency"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCurrencies(t *testing.T) {
+	currencies, err := testQueries.ListCurrencies(context.Background())
+	require.NoError(t, err)
+
+	byCode := make(map[string]Currency, len(currencies))
+	for _, currency := range currencies {
+		byCode[currency.Code] = currency
+	}
+
+	require.Equal(t, Currency{Code: "USD", NumericCode: 840, MinorUnits: 2, Enabled: true}, byCode["USD"])
+	require.Equal(t, Currency{Code: "JPY", NumericCode: 392, MinorUnits: 0, Enabled: true}, byCode["JPY"])
+	require.Equal(t, Currency{Code: "KWD", NumericCode: 414, MinorUnits: 3, Enabled: true}, byCode["KWD"])
+}
+
+func TestLoadCurrencies(t *testing.T) {
+	err := LoadCurrencies(context.Background(), testQueries)
+	require.NoError(t, err)
+
+	require.True(t, currency.IsSupported("USD"))
+	require.Equal(t, "1.234", currency.FormatAmount("KWD", 1234))
+	require.Equal(t, "1234", currency.FormatAmount("JPY", 1234))
+}
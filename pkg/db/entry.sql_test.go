@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -46,3 +47,52 @@ func TestGetEntry(t *testing.T) {
 	require.Equal(t, entry2.AccountID, entry1.AccountID)
 	require.WithinDuration(t, entry2.CreateAt, entry1.CreateAt, time.Second)
 }
+
+func TestListEntries(t *testing.T) {
+	account := createTestAccount(t)
+
+	var entries []Entry
+
+	for range 6 {
+		entry, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{
+			AccountID: account.ID,
+			Amount:    int64(gofakeit.IntRange(-100, 100)),
+		})
+		require.NoError(t, err)
+		entries = append(entries, entry)
+	}
+
+	arg := ListEntriesParams{
+		AccountID: account.ID,
+		AfterID:   entries[1].ID,
+		PageSize:  3,
+	}
+
+	rows, err := testQueries.ListEntries(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	runningBalance := entries[0].Amount + entries[1].Amount
+
+	for i, row := range rows {
+		entry := entries[i+2]
+		runningBalance += entry.Amount
+
+		require.Equal(t, entry.ID, row.ID)
+		require.Equal(t, account.ID, row.AccountID)
+		require.Equal(t, entry.Amount, row.Amount)
+		require.Equal(t, runningBalance, row.RunningBalance)
+	}
+
+	arg = ListEntriesParams{
+		AccountID: account.ID,
+		StartTime: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+		PageSize:  10,
+	}
+
+	rows, err = testQueries.ListEntries(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.Empty(t, rows)
+}
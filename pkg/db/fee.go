@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+)
+
+// ProductFeeIncome is the product of the system accounts fees are paid into,
+// one per currency.
+const ProductFeeIncome = "fee_income"
+
+// Transfer types fee rules are keyed by. Internal transfers stay between
+// accounts of one owner and external ones go to someone else.
+const (
+	TransferInternal = "internal"
+	TransferExternal = "external"
+)
+
+// Fee kinds. Each fee charged on a transfer is posted as a leg of its own.
+const (
+	FeeFlat        = "flat"
+	FeePercent     = "percent"
+	FeeFxSurcharge = "fx_surcharge"
+)
+
+// fee is a fee worked out for a transfer that's yet to be posted.
+type fee struct {
+	kind   string
+	amount money.Money
+}
+
+// transferType returns whether a transfer between the two accounts is
+// internal or external.
+func transferType(from, to Account) string {
+	if from.Owner == to.Owner {
+		return TransferInternal
+	}
+
+	return TransferExternal
+}
+
+// findFees works out the fees on sending amount from one account to another
+// under the rule for the sender's currency and product and the transfer type.
+// Without a rule the transfer is free.
+func findFees(ctx context.Context, q *Queries, from, to Account, amount int64) ([]fee, error) {
+	rule, err := q.GetFeeRule(ctx, GetFeeRuleParams{
+		Currency:     from.Currency,
+		Product:      from.Product,
+		TransferType: transferType(from, to),
+	})
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return computeFees(rule, money.New(amount, from.Currency), from.Currency != to.Currency)
+}
+
+// computeFees works out the fees rule charges on sending amount. The
+// percentage fee is rounded down before it's raised to the minimum or cut to
+// the maximum, and the surcharge only applies across currencies. Fees that
+// come to nothing are left out.
+func computeFees(rule FeeRule, amount money.Money, crossCurrency bool) ([]fee, error) {
+	var fees []fee
+
+	add := func(kind string, value int64) {
+		if value > 0 {
+			fees = append(fees, fee{kind, money.New(value, amount.Currency)})
+		}
+	}
+
+	add(FeeFlat, rule.FlatAmount)
+
+	if rule.PercentBps > 0 {
+		percent, err := bpsOf(amount.Amount, rule.PercentBps)
+
+		if err != nil {
+			return nil, err
+		}
+
+		percent = max(percent, rule.PercentMin)
+
+		if rule.PercentMax.Valid {
+			percent = min(percent, rule.PercentMax.Int64)
+		}
+
+		add(FeePercent, percent)
+	}
+
+	if crossCurrency && rule.FxSurchargeBps > 0 {
+		surcharge, err := bpsOf(amount.Amount, rule.FxSurchargeBps)
+
+		if err != nil {
+			return nil, err
+		}
+
+		add(FeeFxSurcharge, surcharge)
+	}
+
+	return fees, nil
+}
+
+// bpsOf returns bps basis points of amount, rounded down.
+func bpsOf(amount int64, bps int32) (int64, error) {
+	value := new(big.Int).Mul(big.NewInt(amount), big.NewInt(int64(bps)))
+	value.Quo(value, big.NewInt(bpsScale))
+
+	if !value.IsInt64() {
+		return 0, money.ErrOverflow
+	}
+
+	return value.Int64(), nil
+}
+
+// totalOf adds the fees up on top of amount.
+func totalOf(amount money.Money, fees []fee) (money.Money, error) {
+	total := amount
+
+	for _, f := range fees {
+		var err error
+
+		if total, err = total.Add(f.amount); err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
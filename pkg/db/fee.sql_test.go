@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertFeeRule(t *testing.T) {
+	arg := UpsertFeeRuleParams{
+		Currency:       "KWD",
+		Product:        ProductSavings,
+		TransferType:   TransferInternal,
+		FlatAmount:     100,
+		PercentBps:     25,
+		PercentMin:     50,
+		PercentMax:     sql.NullInt64{Int64: 5000, Valid: true},
+		FxSurchargeBps: 10,
+	}
+
+	rule, err := testQueries.UpsertFeeRule(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.FlatAmount, rule.FlatAmount)
+	require.Equal(t, arg.PercentMax, rule.PercentMax)
+
+	// a zeroed rule charges nothing, which leaves other tests unaffected
+	defer func() {
+		_, err := testQueries.UpsertFeeRule(context.Background(), UpsertFeeRuleParams{
+			Currency:     arg.Currency,
+			Product:      arg.Product,
+			TransferType: arg.TransferType,
+		})
+		require.NoError(t, err)
+	}()
+
+	arg.PercentMax = sql.NullInt64{}
+
+	updated, err := testQueries.UpsertFeeRule(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, updated.PercentMax.Valid)
+
+	found, err := testQueries.GetFeeRule(context.Background(), GetFeeRuleParams{
+		Currency:     arg.Currency,
+		Product:      arg.Product,
+		TransferType: arg.TransferType,
+	})
+	require.NoError(t, err)
+	require.Equal(t, updated, found)
+
+	rules, err := testQueries.ListFeeRules(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, rules, found)
+
+	arg.PercentMax = sql.NullInt64{Int64: 10, Valid: true}
+
+	_, err = testQueries.UpsertFeeRule(context.Background(), arg)
+	require.Error(t, err)
+}
+
+func TestComputeFees(t *testing.T) {
+	testCases := []struct {
+		name          string
+		rule          FeeRule
+		amount        int64
+		crossCurrency bool
+		expected      []fee
+	}{
+		{
+			name:   "Free",
+			amount: 1000,
+		},
+		{
+			name:     "Flat",
+			rule:     FeeRule{FlatAmount: 25},
+			amount:   1000,
+			expected: []fee{{FeeFlat, money.New(25, "USD")}},
+		},
+		{
+			name:     "Percent",
+			rule:     FeeRule{PercentBps: 150},
+			amount:   1999,
+			expected: []fee{{FeePercent, money.New(29, "USD")}},
+		},
+		{
+			name:     "PercentMin",
+			rule:     FeeRule{PercentBps: 100, PercentMin: 50},
+			amount:   1000,
+			expected: []fee{{FeePercent, money.New(50, "USD")}},
+		},
+		{
+			name:     "PercentMax",
+			rule:     FeeRule{PercentBps: 100, PercentMax: sql.NullInt64{Int64: 500, Valid: true}},
+			amount:   1_000_000,
+			expected: []fee{{FeePercent, money.New(500, "USD")}},
+		},
+		{
+			name:   "SameCurrency",
+			rule:   FeeRule{FxSurchargeBps: 50},
+			amount: 10_000,
+		},
+		{
+			name:          "CrossCurrency",
+			rule:          FeeRule{FlatAmount: 25, PercentBps: 10, FxSurchargeBps: 50},
+			amount:        10_000,
+			crossCurrency: true,
+			expected: []fee{
+				{FeeFlat, money.New(25, "USD")},
+				{FeePercent, money.New(10, "USD")},
+				{FeeFxSurcharge, money.New(50, "USD")},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fees, err := computeFees(tc.rule, money.New(tc.amount, "USD"), tc.crossCurrency)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, fees)
+		})
+	}
+}
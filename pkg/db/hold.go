@@ -0,0 +1,271 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+)
+
+// Hold statuses. A hold reserves funds while it's active and releases them
+// whichever way it ends.
+const (
+	HoldActive   = "active"
+	HoldCaptured = "captured"
+	HoldVoided   = "voided"
+	HoldExpired  = "expired"
+)
+
+var (
+	ErrHoldNotActive = errors.New("hold isn't active")
+	ErrHoldExpired   = errors.New("hold expired")
+	ErrHoldExceeded  = errors.New("capture exceeds hold")
+)
+
+type CreateHoldTxParams struct {
+	AccountID   int64       `json:"account_id"`
+	ToAccountID int64       `json:"to_account_id"`
+	Amount      money.Money `json:"amount"`
+	ExpiresAt   time.Time   `json:"expires_at"`
+}
+
+type HoldTxResult struct {
+	Hold    Hold    `json:"hold"`
+	Account Account `json:"account"`
+}
+
+// CreateHoldTx reserves amount on the account for a later capture by the
+// recipient. Reserved funds stay in the balance but come out of the available
+// balance, so they can't be spent elsewhere in the meantime.
+func (s *SQLStore) CreateHoldTx(ctx context.Context, arg CreateHoldTxParams) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := s.execTxWithRetry(ctx, func(q *Queries) error {
+		result = HoldTxResult{}
+
+		toAccount, err := q.GetAccount(ctx, arg.ToAccountID)
+
+		if err != nil {
+			return err
+		}
+
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+
+		if err != nil {
+			return err
+		}
+
+		if account.Owner == SystemAccountOwner || toAccount.Owner == SystemAccountOwner {
+			return fmt.Errorf("%w: holds can't involve system accounts", ErrSystemAccount)
+		}
+
+		if account.Currency != arg.Amount.Currency || toAccount.Currency != arg.Amount.Currency {
+			return fmt.Errorf("%w: hold of %s between accounts in %s and %s", money.ErrCurrencyMismatch, arg.Amount, account.Currency, toAccount.Currency)
+		}
+
+		reserved, err := arg.Amount.Neg()
+
+		if err != nil {
+			return err
+		}
+
+		if err := checkStatus(account, reserved); err != nil {
+			return err
+		}
+
+		if err := checkFunds(account, arg.Amount); err != nil {
+			return err
+		}
+
+		result.Account, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+			ID:     account.ID,
+			Amount: reserved.Amount,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+			AccountID:   account.ID,
+			ToAccountID: toAccount.ID,
+			Amount:      arg.Amount.Amount,
+			Currency:    arg.Amount.Currency,
+			ExpiresAt:   arg.ExpiresAt,
+		})
+
+		return err
+	})
+
+	return result, err
+}
+
+type CaptureHoldTxParams struct {
+	HoldID int64 `json:"hold_id"`
+	// Amount to capture, at most the amount held. Zero captures all of it.
+	Amount money.Money `json:"amount"`
+}
+
+type CaptureHoldTxResult struct {
+	Hold     Hold             `json:"hold"`
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// CaptureHoldTx turns an active hold into a transfer to its recipient. The
+// whole hold is released, so capturing less than was held frees the rest. The
+// transfer goes through the same checks as TransferTx, limits included.
+func (s *SQLStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error) {
+	var result CaptureHoldTxResult
+
+	err := s.execTxWithRetry(ctx, func(q *Queries) error {
+		result = CaptureHoldTxResult{}
+
+		hold, err := q.GetHoldForUpdate(ctx, arg.HoldID)
+
+		if err != nil {
+			return err
+		}
+
+		if err := checkHold(hold, time.Now()); err != nil {
+			return err
+		}
+
+		amount := hold.Amount
+
+		if !arg.Amount.IsZero() {
+			if arg.Amount.Currency != hold.Currency {
+				return fmt.Errorf("%w: hold %d is in %s, not %s", money.ErrCurrencyMismatch, hold.ID, hold.Currency, arg.Amount.Currency)
+			}
+
+			if arg.Amount.Amount > hold.Amount {
+				return fmt.Errorf("%w: hold %d is for %s, can't capture %s", ErrHoldExceeded, hold.ID, money.New(hold.Amount, hold.Currency), arg.Amount)
+			}
+
+			amount = arg.Amount.Amount
+		}
+
+		// the hold is released only once the transfer holds its locks, which
+		// it takes in the same order as any other transfer
+		pending, err := lockTransfer(ctx, q, CreateTransferParams{
+			FromAccountID: hold.AccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        amount,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		account, err := release(ctx, q, hold)
+
+		if err != nil {
+			return err
+		}
+
+		pending.accounts[account.ID] = account
+
+		result.Transfer, err = pending.post(ctx, q)
+
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+			ID:         hold.ID,
+			Status:     HoldCaptured,
+			TransferID: sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+		})
+
+		return err
+	})
+
+	return result, err
+}
+
+// VoidHoldTx cancels an active hold and gives its funds back to the available
+// balance.
+func (s *SQLStore) VoidHoldTx(ctx context.Context, holdID int64) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := s.execTxWithRetry(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, holdID)
+
+		if err != nil {
+			return err
+		}
+
+		if hold.Status != HoldActive {
+			return fmt.Errorf("%w: hold %d is %s", ErrHoldNotActive, hold.ID, hold.Status)
+		}
+
+		result, err = endHold(ctx, q, hold, HoldVoided)
+
+		return err
+	})
+
+	return result, err
+}
+
+// ExpireHoldTx releases the funds of one active hold past its expiry. It
+// returns sql.ErrNoRows when no hold has expired.
+func (s *SQLStore) ExpireHoldTx(ctx context.Context) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := s.execTxWithRetry(ctx, func(q *Queries) error {
+		hold, err := q.GetExpiredHoldForUpdate(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		result, err = endHold(ctx, q, hold, HoldExpired)
+
+		return err
+	})
+
+	return result, err
+}
+
+// checkHold makes sure hold can still be captured at now.
+func checkHold(hold Hold, now time.Time) error {
+	if hold.Status != HoldActive {
+		return fmt.Errorf("%w: hold %d is %s", ErrHoldNotActive, hold.ID, hold.Status)
+	}
+
+	if !hold.ExpiresAt.After(now) {
+		return fmt.Errorf("%w: hold %d expired at %s", ErrHoldExpired, hold.ID, hold.ExpiresAt)
+	}
+
+	return nil
+}
+
+// endHold releases the funds of hold and moves it to status.
+func endHold(ctx context.Context, q *Queries, hold Hold, status string) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	account, err := release(ctx, q, hold)
+
+	if err != nil {
+		return result, err
+	}
+
+	result.Account = account
+	result.Hold, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+		ID:     hold.ID,
+		Status: status,
+	})
+
+	return result, err
+}
+
+// release adds the amount of hold back to the available balance of the
+// account it was held on.
+func release(ctx context.Context, q *Queries, hold Hold) (Account, error) {
+	return q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+		ID:     hold.AccountID,
+		Amount: hold.Amount,
+	})
+}
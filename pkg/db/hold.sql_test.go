@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createTestHold(t *testing.T, expiresAt time.Time) Hold {
+	account1 := createTestAccountWithCurrency(t, "USD")
+	account2 := createTestAccountWithCurrency(t, account1.Currency)
+
+	arg := CreateHoldParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      10,
+		Currency:    account1.Currency,
+		ExpiresAt:   expiresAt,
+	}
+
+	hold, err := testQueries.CreateHold(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.NotZero(t, hold.ID)
+	require.Equal(t, arg.AccountID, hold.AccountID)
+	require.Equal(t, arg.ToAccountID, hold.ToAccountID)
+	require.Equal(t, arg.Amount, hold.Amount)
+	require.Equal(t, HoldActive, hold.Status)
+	require.WithinDuration(t, expiresAt, hold.ExpiresAt, time.Second)
+	require.False(t, hold.TransferID.Valid)
+
+	return hold
+}
+
+func TestCreateHold(t *testing.T) {
+	createTestHold(t, time.Now().Add(time.Hour))
+}
+
+func TestGetHold(t *testing.T) {
+	hold1 := createTestHold(t, time.Now().Add(time.Hour))
+
+	hold2, err := testQueries.GetHold(context.Background(), hold1.ID)
+
+	require.NoError(t, err)
+	require.Equal(t, hold1.ID, hold2.ID)
+	require.Equal(t, hold1.Amount, hold2.Amount)
+	require.WithinDuration(t, hold1.ExpiresAt, hold2.ExpiresAt, time.Second)
+}
+
+func TestUpdateHoldStatus(t *testing.T) {
+	hold1 := createTestHold(t, time.Now().Add(time.Hour))
+
+	hold2, err := testQueries.UpdateHoldStatus(context.Background(), UpdateHoldStatusParams{
+		ID:     hold1.ID,
+		Status: HoldVoided,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, HoldVoided, hold2.Status)
+	require.False(t, hold2.TransferID.Valid)
+
+	_, err = testQueries.UpdateHoldStatus(context.Background(), UpdateHoldStatusParams{
+		ID:     hold1.ID,
+		Status: "unknown",
+	})
+	require.Error(t, err)
+}
+
+func TestAddAccountAvailableBalance(t *testing.T) {
+	account1 := createTestAccount(t)
+
+	account2, err := testQueries.AddAccountAvailableBalance(context.Background(), AddAccountAvailableBalanceParams{
+		ID:     account1.ID,
+		Amount: -10,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, account2.Balance)
+	require.Equal(t, account1.AvailableBalance-10, account2.AvailableBalance)
+
+	_, err = testQueries.AddAccountAvailableBalance(context.Background(), AddAccountAvailableBalanceParams{
+		ID:     0,
+		Amount: 10,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
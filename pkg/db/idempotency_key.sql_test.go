@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestIdempotencyKey(t *testing.T) IdempotencyKey {
+	user := createTestUser(t)
+
+	arg := CreateIdempotencyKeyParams{
+		Key:         gofakeit.UUID(),
+		Username:    user.Username,
+		RequestHash: gofakeit.LetterN(64),
+	}
+
+	idempotencyKey, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, idempotencyKey)
+
+	require.Equal(t, arg.Key, idempotencyKey.Key)
+	require.Equal(t, arg.Username, idempotencyKey.Username)
+	require.Equal(t, arg.RequestHash, idempotencyKey.RequestHash)
+	require.False(t, idempotencyKey.ResponseStatus.Valid)
+	require.Empty(t, idempotencyKey.ResponseBody)
+	require.NotZero(t, idempotencyKey.CreatedAt)
+
+	return idempotencyKey
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	createTestIdempotencyKey(t)
+}
+
+func TestUpdateIdempotencyKeyResponse(t *testing.T) {
+	key1 := createTestIdempotencyKey(t)
+
+	arg := UpdateIdempotencyKeyResponseParams{
+		Username:       key1.Username,
+		Key:            key1.Key,
+		ResponseStatus: sql.NullInt32{Int32: 201, Valid: true},
+		ResponseBody:   []byte(`{"id":1}`),
+	}
+
+	err := testQueries.UpdateIdempotencyKeyResponse(context.Background(), arg)
+	require.NoError(t, err)
+
+	key2, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: key1.Username,
+		Key:      key1.Key,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, key1.RequestHash, key2.RequestHash)
+	require.Equal(t, arg.ResponseStatus, key2.ResponseStatus)
+	require.Equal(t, arg.ResponseBody, key2.ResponseBody)
+}
+
+func TestReclaimIdempotencyKey(t *testing.T) {
+	key1 := createTestIdempotencyKey(t)
+
+	arg := ReclaimIdempotencyKeyParams{
+		Username:     key1.Username,
+		Key:          key1.Key,
+		RequestHash:  key1.RequestHash,
+		StaleSeconds: 60,
+	}
+
+	// a key that was just taken is still in progress
+	_, err := testQueries.ReclaimIdempotencyKey(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	arg.StaleSeconds = 0
+
+	key2, err := testQueries.ReclaimIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, key1.Key, key2.Key)
+	require.False(t, key2.ResponseStatus.Valid)
+	require.True(t, key2.CreatedAt.After(key1.CreatedAt))
+
+	// a different request can't take the key over
+	arg.RequestHash = gofakeit.LetterN(64)
+
+	_, err = testQueries.ReclaimIdempotencyKey(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeleteIdempotencyKey(t *testing.T) {
+	key1 := createTestIdempotencyKey(t)
+
+	err := testQueries.DeleteIdempotencyKey(context.Background(), DeleteIdempotencyKeyParams{
+		Username: key1.Username,
+		Key:      key1.Key,
+	})
+	require.NoError(t, err)
+
+	key2, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: key1.Username,
+		Key:      key1.Key,
+	})
+
+	require.Empty(t, key2)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
@@ -0,0 +1,190 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+)
+
+// Account products. Customers open checking and savings accounts, while the
+// system user owns one cash and one interest expense account per currency.
+const (
+	ProductChecking        = "checking"
+	ProductSavings         = "savings"
+	ProductCash            = "cash"
+	ProductInterestExpense = "interest_expense"
+)
+
+// bpsScale is the number of basis points in a whole.
+const bpsScale = 10_000
+
+var ErrInterestPosted = errors.New("interest already posted")
+
+type PostInterestTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// Period is the first day of the month to post, in UTC.
+	Period time.Time `json:"period"`
+}
+
+type PostInterestTxResult struct {
+	Posting         InterestPosting `json:"posting"`
+	Transfer        Transfer        `json:"transfer"`
+	Account         Account         `json:"account"`
+	InterestAccount Account         `json:"interest_account"`
+}
+
+// PostInterestTx pays the interest an account accrued in a month as a
+// transfer from the interest expense account of its currency. Interest is
+// summed exactly over every day accrued up to the end of the month and rounded
+// down, less what was posted for earlier months, so no fraction of a minor
+// unit is ever lost or paid twice. Each month is posted at most once; posting
+// it again fails with ErrInterestPosted.
+func (s *SQLStore) PostInterestTx(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error) {
+	var result PostInterestTxResult
+
+	err := s.execTxWithRetry(ctx, func(q *Queries) error {
+		result = PostInterestTxResult{}
+
+		account, err := q.GetAccount(ctx, arg.AccountID)
+
+		if err != nil {
+			return err
+		}
+
+		interestAccount, err := openSystemAccount(ctx, q, account.Currency, ProductInterestExpense)
+
+		if err != nil {
+			return err
+		}
+
+		// the account lock keeps concurrent posters of the same month apart
+		accounts, err := lockAccounts(ctx, q, account.ID, interestAccount.ID)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = q.GetInterestPosting(ctx, GetInterestPostingParams{AccountID: account.ID, Period: arg.Period})
+
+		if err == nil {
+			return fmt.Errorf("%w: account %d for %s", ErrInterestPosted, account.ID, arg.Period.Format("2006-01"))
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		periodEnd := arg.Period.AddDate(0, 1, 0)
+
+		sums, err := q.SumInterestAccruals(ctx, SumInterestAccrualsParams{AccountID: account.ID, Before: periodEnd})
+
+		if err != nil {
+			return err
+		}
+
+		accrued, err := accruedInterest(sums)
+
+		if err != nil {
+			return err
+		}
+
+		posted, err := q.GetPostedInterest(ctx, GetPostedInterestParams{AccountID: account.ID, Before: arg.Period})
+
+		if err != nil {
+			return err
+		}
+
+		amount := accrued - posted
+
+		// interest accrued on an account that's been closed since is forfeited
+		if account.Status == AccountClosed {
+			amount = 0
+		}
+
+		record := CreateInterestPostingParams{
+			AccountID: account.ID,
+			Period:    arg.Period,
+			Amount:    amount,
+		}
+
+		if amount > 0 {
+			interest := money.New(amount, account.Currency)
+
+			paid, err := interest.Neg()
+
+			if err != nil {
+				return err
+			}
+
+			result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+				FromAccountID: interestAccount.ID,
+				ToAccountID:   account.ID,
+				Amount:        amount,
+				Currency:      account.Currency,
+				ToAmount:      amount,
+				ToCurrency:    account.Currency,
+				FxRate:        FxRateScale,
+			})
+
+			if err != nil {
+				return err
+			}
+
+			transferID := sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+
+			err = post(ctx, q, transferID, []posting{
+				{nil, &result.InterestAccount, accounts[interestAccount.ID], paid},
+				{nil, &result.Account, accounts[account.ID], interest},
+			})
+
+			if err != nil {
+				return err
+			}
+
+			record.TransferID = transferID
+		}
+
+		result.Posting, err = q.CreateInterestPosting(ctx, record)
+
+		if err != nil || amount == 0 {
+			return err
+		}
+
+		return enqueue(ctx, q, EventInterestPosted, AccountKey(account.ID), InterestPostedEvent{
+			Posting:  result.Posting,
+			Transfer: result.Transfer,
+		}, account.Owner)
+	})
+
+	return result, err
+}
+
+// accruedInterest adds up accrued interest exactly and rounds it down to a
+// whole minor unit. Each sum is balance times rate in basis points for a day,
+// so it's divided by the basis points in a whole and the days in the year.
+func accruedInterest(sums []SumInterestAccrualsRow) (int64, error) {
+	total := new(big.Rat)
+
+	for _, sum := range sums {
+		numerator, ok := new(big.Int).SetString(sum.Numerator, 10)
+
+		if !ok || sum.DaysInYear <= 0 {
+			return 0, fmt.Errorf("invalid interest accrual %s over %d days", sum.Numerator, sum.DaysInYear)
+		}
+
+		denominator := big.NewInt(bpsScale * int64(sum.DaysInYear))
+
+		total.Add(total, new(big.Rat).SetFrac(numerator, denominator))
+	}
+
+	whole := new(big.Int).Div(total.Num(), total.Denom())
+
+	if !whole.IsInt64() {
+		return 0, money.ErrOverflow
+	}
+
+	return whole.Int64(), nil
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccrueInterest(t *testing.T) {
+	account := createTestAccountWithProduct(t, "USD", ProductSavings)
+	checking := createTestAccountWithCurrency(t, "USD")
+
+	day := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+
+	arg := AccrueInterestParams{AccrualDate: day, DayEnd: day.AddDate(0, 0, 1)}
+
+	accrued, err := testQueries.AccrueInterest(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, accrued)
+
+	sums, err := testQueries.SumInterestAccruals(context.Background(), SumInterestAccrualsParams{
+		AccountID: account.ID,
+		Before:    arg.DayEnd,
+	})
+	require.NoError(t, err)
+	require.Len(t, sums, 1)
+	require.Equal(t, int32(365), sums[0].DaysInYear)
+
+	sums, err = testQueries.SumInterestAccruals(context.Background(), SumInterestAccrualsParams{
+		AccountID: checking.ID,
+		Before:    arg.DayEnd,
+	})
+	require.NoError(t, err)
+	require.Empty(t, sums)
+
+	// accruing the same day again doesn't add to it
+	_, err = testQueries.AccrueInterest(context.Background(), arg)
+	require.NoError(t, err)
+
+	again, err := testQueries.SumInterestAccruals(context.Background(), SumInterestAccrualsParams{
+		AccountID: account.ID,
+		Before:    arg.DayEnd,
+	})
+	require.NoError(t, err)
+	require.Len(t, again, 1)
+
+	last, err := testQueries.GetLastInterestAccrualDate(context.Background())
+	require.NoError(t, err)
+	require.False(t, last.Before(day))
+}
+
+func TestAccruedInterest(t *testing.T) {
+	testCases := []struct {
+		name     string
+		sums     []SumInterestAccrualsRow
+		expected int64
+	}{
+		{
+			name:     "Nothing",
+			expected: 0,
+		},
+		{
+			// 1000.00 at 2% for 30 days is 1.643... in cents
+			name:     "RoundedDown",
+			sums:     []SumInterestAccrualsRow{{DaysInYear: 365, Numerator: "600000000"}},
+			expected: 164,
+		},
+		{
+			// a third and two thirds of a cent add up to exactly one
+			name: "ExactFractions",
+			sums: []SumInterestAccrualsRow{
+				{DaysInYear: 360, Numerator: "1200000"},
+				{DaysInYear: 360, Numerator: "2400000"},
+			},
+			expected: 1,
+		},
+		{
+			name: "MixedDayCounts",
+			sums: []SumInterestAccrualsRow{
+				{DaysInYear: 360, Numerator: "1200000"},
+				{DaysInYear: 365, Numerator: "2433333"},
+			},
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			amount, err := accruedInterest(tc.sums)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, amount)
+		})
+	}
+
+	_, err := accruedInterest([]SumInterestAccrualsRow{{DaysInYear: 365, Numerator: "1.5"}})
+	require.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListBalanceMismatches(t *testing.T) {
+	account := createTestAccount(t)
+
+	mismatches, err := testQueries.ListBalanceMismatches(context.Background())
+	require.NoError(t, err)
+
+	// createTestAccount opens the account with a balance but no entry
+	require.Contains(t, mismatches, ListBalanceMismatchesRow{
+		AccountID:    account.ID,
+		Currency:     account.Currency,
+		Balance:      account.Balance,
+		EntriesTotal: 0,
+	})
+}
+
+func TestListTransferEntryMismatches(t *testing.T) {
+	s := NewStore(testDB)
+
+	account1 := createTestAccount(t)
+	account2 := createTestAccountWithCurrency(t, account1.Currency)
+
+	result, err := s.TransferTx(context.Background(), CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	orphan, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		ToAmount:      10,
+		FxRate:        FxRateScale,
+		Currency:      account1.Currency,
+		ToCurrency:    account2.Currency,
+	})
+	require.NoError(t, err)
+
+	mismatches, err := testQueries.ListTransferEntryMismatches(context.Background())
+	require.NoError(t, err)
+
+	ids := make([]int64, len(mismatches))
+	for i, mismatch := range mismatches {
+		ids[i] = mismatch.TransferID
+	}
+
+	require.NotContains(t, ids, result.Transfer.ID)
+	require.Contains(t, ids, orphan.ID)
+}
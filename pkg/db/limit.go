@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+)
+
+// User tiers. Every user starts in the standard tier.
+const (
+	TierStandard = "standard"
+	TierPremium  = "premium"
+)
+
+// Limit scopes and kinds reported by LimitError.
+const (
+	LimitScopeAccount = "account"
+	LimitScopeUser    = "user"
+
+	LimitPerTransfer = "per_transfer"
+	LimitDaily       = "daily"
+	LimitMonthly     = "monthly"
+)
+
+var ErrLimitExceeded = errors.New("transfer limit exceeded")
+
+// LimitError says which limit a transfer would break and how much could still
+// be sent under it.
+type LimitError struct {
+	Scope     string      `json:"scope"`
+	Limit     string      `json:"limit"`
+	Max       money.Money `json:"max"`
+	Remaining money.Money `json:"remaining"`
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: %s %s limit is %s, %s remaining", ErrLimitExceeded, e.Scope, e.Limit, e.Max, e.Remaining)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// outgoingLimits are the limits of one scope in minor units.
+type outgoingLimits struct {
+	perTransfer sql.NullInt64
+	daily       sql.NullInt64
+	monthly     sql.NullInt64
+}
+
+// checkLimits makes sure sending amount from account stays within the limits
+// of the account and of its owner's tier. Days and months are counted in UTC.
+// The caller must hold the lock on the owner's user row so that concurrent
+// transfers can't both fit under a limit they break together.
+func checkLimits(ctx context.Context, q *Queries, account Account, owner User, amount money.Money, now time.Time) error {
+	now = now.UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	accountLimit, err := q.GetAccountLimit(ctx, account.ID)
+
+	switch {
+	case err == nil:
+		totals, err := q.GetAccountOutgoingTotals(ctx, GetAccountOutgoingTotalsParams{
+			DayStart:   dayStart,
+			AccountID:  account.ID,
+			MonthStart: monthStart,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		limits := outgoingLimits{accountLimit.PerTransferLimit, accountLimit.DailyLimit, accountLimit.MonthlyLimit}
+
+		if err := limits.check(LimitScopeAccount, amount, totals.Daily, totals.Monthly); err != nil {
+			return err
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+
+	tierLimit, err := q.GetTierLimit(ctx, GetTierLimitParams{Tier: owner.Tier, Currency: amount.Currency})
+
+	switch {
+	case err == nil:
+		totals, err := q.GetOwnerOutgoingTotals(ctx, GetOwnerOutgoingTotalsParams{
+			DayStart:   dayStart,
+			Owner:      owner.Username,
+			Currency:   amount.Currency,
+			MonthStart: monthStart,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		limits := outgoingLimits{tierLimit.PerTransferLimit, tierLimit.DailyLimit, tierLimit.MonthlyLimit}
+
+		return limits.check(LimitScopeUser, amount, totals.Daily, totals.Monthly)
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+
+	return nil
+}
+
+// check returns a LimitError for the first limit that sending amount on top
+// of the daily and monthly totals already sent would go over.
+func (l outgoingLimits) check(scope string, amount money.Money, daily, monthly int64) error {
+	checks := []struct {
+		name  string
+		limit sql.NullInt64
+		used  int64
+	}{
+		{LimitPerTransfer, l.perTransfer, 0},
+		{LimitDaily, l.daily, daily},
+		{LimitMonthly, l.monthly, monthly},
+	}
+
+	for _, c := range checks {
+		if !c.limit.Valid {
+			continue
+		}
+
+		remaining := max(c.limit.Int64-c.used, 0)
+
+		if amount.Amount > remaining {
+			return &LimitError{
+				Scope:     scope,
+				Limit:     c.name,
+				Max:       money.New(c.limit.Int64, amount.Currency),
+				Remaining: money.New(remaining, amount.Currency),
+			}
+		}
+	}
+
+	return nil
+}
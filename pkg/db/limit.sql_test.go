@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertAccountLimit(t *testing.T) {
+	account := createTestAccount(t)
+
+	arg := UpsertAccountLimitParams{
+		AccountID:  account.ID,
+		DailyLimit: sql.NullInt64{Int64: 5000, Valid: true},
+	}
+
+	limit1, err := testQueries.UpsertAccountLimit(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.DailyLimit, limit1.DailyLimit)
+	require.False(t, limit1.PerTransferLimit.Valid)
+	require.False(t, limit1.MonthlyLimit.Valid)
+
+	arg.DailyLimit = sql.NullInt64{}
+	arg.MonthlyLimit = sql.NullInt64{Int64: 90000, Valid: true}
+
+	_, err = testQueries.UpsertAccountLimit(context.Background(), arg)
+	require.NoError(t, err)
+
+	limit2, err := testQueries.GetAccountLimit(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.False(t, limit2.DailyLimit.Valid)
+	require.Equal(t, arg.MonthlyLimit, limit2.MonthlyLimit)
+	require.WithinDuration(t, limit1.UpdatedAt, limit2.UpdatedAt, time.Second)
+}
+
+func TestGetAccountOutgoingTotals(t *testing.T) {
+	account1 := createTestAccount(t)
+	account2 := createTestAccountWithCurrency(t, account1.Currency)
+
+	for _, amount := range []int64{10, 20} {
+		_, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+			ToAmount:      amount,
+			Currency:      account1.Currency,
+			ToCurrency:    account2.Currency,
+			FxRate:        FxRateScale,
+		})
+		require.NoError(t, err)
+	}
+
+	now := time.Now().UTC()
+
+	totals, err := testQueries.GetAccountOutgoingTotals(context.Background(), GetAccountOutgoingTotalsParams{
+		DayStart:   now.Add(-time.Hour),
+		AccountID:  account1.ID,
+		MonthStart: now.Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(30), totals.Daily)
+	require.Equal(t, int64(30), totals.Monthly)
+
+	owner, err := testQueries.GetOwnerOutgoingTotals(context.Background(), GetOwnerOutgoingTotalsParams{
+		DayStart:   now.Add(time.Hour),
+		Owner:      account1.Owner,
+		Currency:   account1.Currency,
+		MonthStart: now.Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	require.Zero(t, owner.Daily)
+	require.Equal(t, int64(30), owner.Monthly)
+}
@@ -5,29 +5,206 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Account struct {
-	ID        int64     `json:"id"`
-	Owner     string    `json:"owner"`
-	Balance   int64     `json:"balance"`
-	Currency  string    `json:"currency"`
-	CreatedAt time.Time `json:"created_at"`
+	ID               int64     `json:"id"`
+	Owner            string    `json:"owner"`
+	Balance          int64     `json:"balance"`
+	Currency         string    `json:"currency"`
+	CreatedAt        time.Time `json:"created_at"`
+	Status           string    `json:"status"`
+	AvailableBalance int64     `json:"available_balance"`
+	Product          string    `json:"product"`
+}
+
+type AccountLimit struct {
+	AccountID        int64         `json:"account_id"`
+	PerTransferLimit sql.NullInt64 `json:"per_transfer_limit"`
+	DailyLimit       sql.NullInt64 `json:"daily_limit"`
+	MonthlyLimit     sql.NullInt64 `json:"monthly_limit"`
+	UpdatedAt        time.Time     `json:"updated_at"`
+}
+
+type AccountStatusChange struct {
+	ID         int64     `json:"id"`
+	AccountID  int64     `json:"account_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  string    `json:"changed_by"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type Currency struct {
+	Code        string `json:"code"`
+	NumericCode int32  `json:"numeric_code"`
+	MinorUnits  int32  `json:"minor_units"`
+	Enabled     bool   `json:"enabled"`
 }
 
 type Entry struct {
-	ID        int64     `json:"id"`
-	AccountID int64     `json:"account_id"`
-	Amount    int64     `json:"amount"`
-	CreateAt  time.Time `json:"create_at"`
+	ID         int64         `json:"id"`
+	AccountID  int64         `json:"account_id"`
+	Amount     int64         `json:"amount"`
+	CreateAt   time.Time     `json:"create_at"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+}
+
+type FeeRule struct {
+	Currency       string        `json:"currency"`
+	Product        string        `json:"product"`
+	TransferType   string        `json:"transfer_type"`
+	FlatAmount     int64         `json:"flat_amount"`
+	PercentBps     int32         `json:"percent_bps"`
+	PercentMin     int64         `json:"percent_min"`
+	PercentMax     sql.NullInt64 `json:"percent_max"`
+	FxSurchargeBps int32         `json:"fx_surcharge_bps"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+type Hold struct {
+	ID          int64         `json:"id"`
+	AccountID   int64         `json:"account_id"`
+	ToAccountID int64         `json:"to_account_id"`
+	Amount      int64         `json:"amount"`
+	Currency    string        `json:"currency"`
+	Status      string        `json:"status"`
+	ExpiresAt   time.Time     `json:"expires_at"`
+	TransferID  sql.NullInt64 `json:"transfer_id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+type IdempotencyKey struct {
+	Key            string        `json:"key"`
+	Username       string        `json:"username"`
+	RequestHash    string        `json:"request_hash"`
+	ResponseStatus sql.NullInt32 `json:"response_status"`
+	ResponseBody   []byte        `json:"response_body"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+type InterestAccrual struct {
+	AccountID   int64     `json:"account_id"`
+	AccrualDate time.Time `json:"accrual_date"`
+	Balance     int64     `json:"balance"`
+	RateBps     int32     `json:"rate_bps"`
+	DaysInYear  int32     `json:"days_in_year"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type InterestPosting struct {
+	AccountID  int64         `json:"account_id"`
+	Period     time.Time     `json:"period"`
+	Amount     int64         `json:"amount"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type Outbox struct {
+	ID          int64           `json:"id"`
+	EventType   string          `json:"event_type"`
+	Key         string          `json:"key"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"created_at"`
+	PublishedAt sql.NullTime    `json:"published_at"`
+}
+
+type Product struct {
+	Code               string    `json:"code"`
+	Name               string    `json:"name"`
+	System             bool      `json:"system"`
+	InterestRateBps    int32     `json:"interest_rate_bps"`
+	InterestDaysInYear int32     `json:"interest_days_in_year"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+type Rate struct {
+	BaseCurrency  string    `json:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency"`
+	Rate          int64     `json:"rate"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64          `json:"id"`
+	Owner         string         `json:"owner"`
+	FromAccountID int64          `json:"from_account_id"`
+	ToAccountID   int64          `json:"to_account_id"`
+	Amount        int64          `json:"amount"`
+	Currency      string         `json:"currency"`
+	Recurrence    sql.NullString `json:"recurrence"`
+	StartAt       time.Time      `json:"start_at"`
+	Status        string         `json:"status"`
+	RunCount      int32          `json:"run_count"`
+	NextRunAt     sql.NullTime   `json:"next_run_at"`
+	DueAt         sql.NullTime   `json:"due_at"`
+	Attempts      int32          `json:"attempts"`
+	LastError     sql.NullString `json:"last_error"`
+	LockedUntil   sql.NullTime   `json:"locked_until"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64          `json:"id"`
+	ScheduledTransferID int64          `json:"scheduled_transfer_id"`
+	ScheduledFor        time.Time      `json:"scheduled_for"`
+	Attempt             int32          `json:"attempt"`
+	TransferID          sql.NullInt64  `json:"transfer_id"`
+	Error               sql.NullString `json:"error"`
+	CreatedAt           time.Time      `json:"created_at"`
+}
+
+type Session struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	RefreshToken string    `json:"refresh_token"`
+	UserAgent    string    `json:"user_agent"`
+	ClientIp     string    `json:"client_ip"`
+	IsBlocked    bool      `json:"is_blocked"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type TierLimit struct {
+	Tier             string        `json:"tier"`
+	Currency         string        `json:"currency"`
+	PerTransferLimit sql.NullInt64 `json:"per_transfer_limit"`
+	DailyLimit       sql.NullInt64 `json:"daily_limit"`
+	MonthlyLimit     sql.NullInt64 `json:"monthly_limit"`
+	UpdatedAt        time.Time     `json:"updated_at"`
 }
 
 type Transfer struct {
+	ID             int64          `json:"id"`
+	FromAccountID  int64          `json:"from_account_id"`
+	ToAccountID    int64          `json:"to_account_id"`
+	Amount         int64          `json:"amount"`
+	CreatedAt      time.Time      `json:"created_at"`
+	ReversalOf     sql.NullInt64  `json:"reversal_of"`
+	ToAmount       int64          `json:"to_amount"`
+	FxRate         int64          `json:"fx_rate"`
+	FxSpreadBps    int32          `json:"fx_spread_bps"`
+	Currency       string         `json:"currency"`
+	ToCurrency     string         `json:"to_currency"`
+	IdempotencyKey sql.NullString `json:"idempotency_key"`
+}
+
+type TransferFee struct {
 	ID            int64     `json:"id"`
-	FromAccountID int64     `json:"from_account_id"`
-	ToAccountID   int64     `json:"to_account_id"`
+	TransferID    int64     `json:"transfer_id"`
+	Kind          string    `json:"kind"`
 	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	DebitEntryID  int64     `json:"debit_entry_id"`
+	CreditEntryID int64     `json:"credit_entry_id"`
 	CreatedAt     time.Time `json:"created_at"`
 }
 
@@ -38,4 +215,28 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreateAt          time.Time `json:"create_at"`
+	Role              string    `json:"role"`
+	Tier              string    `json:"tier"`
+}
+
+type Webhook struct {
+	ID         int64     `json:"id"`
+	Owner      string    `json:"owner"`
+	Url        string    `json:"url"`
+	Secret     string    `json:"secret"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64          `json:"id"`
+	WebhookID      int64          `json:"webhook_id"`
+	EventID        int64          `json:"event_id"`
+	Status         string         `json:"status"`
+	Attempts       int32          `json:"attempts"`
+	NextAttemptAt  time.Time      `json:"next_attempt_at"`
+	LastStatusCode sql.NullInt32  `json:"last_status_code"`
+	LastError      sql.NullString `json:"last_error"`
+	DeliveredAt    sql.NullTime   `json:"delivered_at"`
+	CreatedAt      time.Time      `json:"created_at"`
 }
@@ -0,0 +1,212 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Event types written to the outbox.
+const (
+	EventTransferCompleted = "transfer.completed"
+	EventCashDeposited     = "cash.deposited"
+	EventCashWithdrawn     = "cash.withdrawn"
+	EventBalanceAdjusted   = "balance.adjusted"
+	EventInterestPosted    = "interest.posted"
+	EventAccountCreated    = "account.created"
+	EventAccountStatus     = "account.status_changed"
+	EventUserRegistered    = "user.registered"
+)
+
+// outboxLockKey is the advisory lock that keeps a single relay publishing at a
+// time, so events leave in the order they were written.
+const outboxLockKey int64 = 0x6f7574626f78
+
+// TransferCompletedEvent is the payload of transfer.completed. Fees are
+// charged to the sender on top of the transfer amount. The event is written
+// under the key of both accounts, so a consumer of every key sees each
+// transfer twice and drops the second copy by transfer.id.
+type TransferCompletedEvent struct {
+	Transfer Transfer      `json:"transfer"`
+	Fees     []TransferFee `json:"fees"`
+}
+
+// CashPostedEvent is the payload of cash.deposited, cash.withdrawn and
+// balance.adjusted. The entry amount is in minor units of currency and
+// negative when money left the account.
+type CashPostedEvent struct {
+	Entry    Entry  `json:"entry"`
+	Currency string `json:"currency"`
+}
+
+// InterestPostedEvent is the payload of interest.posted, written when a
+// posting pays anything. The transfer is from the interest expense account.
+type InterestPostedEvent struct {
+	Posting  InterestPosting `json:"posting"`
+	Transfer Transfer        `json:"transfer"`
+}
+
+// UserRegisteredEvent is the payload of user.registered. It leaves out the
+// password hash.
+type UserRegisteredEvent struct {
+	Username  string    `json:"username"`
+	FullName  string    `json:"full_name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Tier      string    `json:"tier"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AccountKey is the outbox key of events about an account. Every event about
+// an account is written while the account is locked or being created, so
+// events with the same key are written in the order they happened.
+func AccountKey(accountID int64) string {
+	return fmt.Sprintf("account:%d", accountID)
+}
+
+// UserKey is the outbox key of events about a user.
+func UserKey(username string) string {
+	return fmt.Sprintf("user:%s", username)
+}
+
+// enqueue writes an event to the outbox as part of the transaction of q and
+// queues it for the webhooks of the owners it's about.
+func enqueue(ctx context.Context, q *Queries, eventType string, key string, payload any, owners ...string) error {
+	data, err := json.Marshal(payload)
+
+	if err != nil {
+		return err
+	}
+
+	event, err := q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		EventType: eventType,
+		Key:       key,
+		Payload:   data,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	owners = slices.DeleteFunc(slices.Clone(owners), func(owner string) bool {
+		return owner == SystemAccountOwner
+	})
+
+	if len(owners) == 0 {
+		return nil
+	}
+
+	_, err = q.CreateWebhookDeliveries(ctx, CreateWebhookDeliveriesParams{
+		EventID:   event.ID,
+		Owners:    owners,
+		EventType: eventType,
+	})
+
+	return err
+}
+
+// CreateAccountTx creates an account and its account.created event.
+func (s *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	var account Account
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		account, err = q.CreateAccount(ctx, arg)
+
+		if err != nil {
+			return err
+		}
+
+		return enqueue(ctx, q, EventAccountCreated, AccountKey(account.ID), account, account.Owner)
+	})
+
+	return account, err
+}
+
+// CreateUserTx creates a user and its user.registered event.
+func (s *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error) {
+	var user User
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		user, err = q.CreateUser(ctx, arg)
+
+		if err != nil {
+			return err
+		}
+
+		return enqueue(ctx, q, EventUserRegistered, UserKey(user.Username), UserRegisteredEvent{
+			Username:  user.Username,
+			FullName:  user.FullName,
+			Email:     user.Email,
+			Role:      user.Role,
+			Tier:      user.Tier,
+			CreatedAt: user.CreateAt,
+		})
+	})
+
+	return user, err
+}
+
+type RelayOutboxTxParams struct {
+	// Limit is the most events handed to Publish.
+	Limit int32
+	// Publish sends an event on. Events are handed over in the order they
+	// were written and the batch stops at the first error.
+	Publish func(ctx context.Context, event Outbox) error
+}
+
+// RelayOutboxTx publishes up to Limit unpublished events and marks the ones
+// that were published, returning how many. Only one relay holds the outbox at
+// a time; while another has it nothing is published. Events are marked when
+// the transaction commits, so an event whose mark is lost to a crash is
+// published again: delivery is at least once, and consumers can tell repeats
+// apart by the event id. The two copies of a transfer.completed are separate
+// events; see TransferCompletedEvent.
+func (s *SQLStore) RelayOutboxTx(ctx context.Context, arg RelayOutboxTxParams) (int, error) {
+	var published []int64
+	var publishErr error
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		published, publishErr = nil, nil
+
+		locked, err := q.TryAdvisoryXactLock(ctx, outboxLockKey)
+
+		if err != nil || !locked {
+			return err
+		}
+
+		events, err := q.ListUnpublishedOutboxEvents(ctx, arg.Limit)
+
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if publishErr = arg.Publish(ctx, event); publishErr != nil {
+				publishErr = fmt.Errorf("publish event %d: %w", event.ID, publishErr)
+				break
+			}
+
+			published = append(published, event.ID)
+		}
+
+		if len(published) == 0 {
+			return nil
+		}
+
+		_, err = q.MarkOutboxEventsPublished(ctx, published)
+
+		return err
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(published), publishErr
+}
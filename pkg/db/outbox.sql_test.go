@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createTestOutboxEvent(t *testing.T) Outbox {
+	arg := CreateOutboxEventParams{
+		EventType: EventAccountCreated,
+		Key:       AccountKey(1),
+		Payload:   json.RawMessage(`{"id": 1}`),
+	}
+
+	event, err := testQueries.CreateOutboxEvent(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, event.ID)
+	require.Equal(t, arg.EventType, event.EventType)
+	require.Equal(t, arg.Key, event.Key)
+	require.JSONEq(t, string(arg.Payload), string(event.Payload))
+	require.False(t, event.PublishedAt.Valid)
+
+	return event
+}
+
+func TestMarkOutboxEventsPublished(t *testing.T) {
+	event1 := createTestOutboxEvent(t)
+	event2 := createTestOutboxEvent(t)
+
+	events, err := testQueries.ListUnpublishedOutboxEvents(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.LessOrEqual(t, events[0].ID, event1.ID)
+
+	marked, err := testQueries.MarkOutboxEventsPublished(context.Background(), []int64{event1.ID, event2.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), marked)
+
+	// events are only marked once
+	marked, err = testQueries.MarkOutboxEventsPublished(context.Background(), []int64{event1.ID})
+	require.NoError(t, err)
+	require.Zero(t, marked)
+}
+
+func TestTryAdvisoryXactLock(t *testing.T) {
+	tx1, err := testDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+
+	defer tx1.Rollback()
+
+	tx2, err := testDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+
+	defer tx2.Rollback()
+
+	locked, err := New(tx1).TryAdvisoryXactLock(context.Background(), 42)
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	locked, err = New(tx2).TryAdvisoryXactLock(context.Background(), 42)
+	require.NoError(t, err)
+	require.False(t, locked)
+}
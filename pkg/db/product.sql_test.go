@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetProduct(t *testing.T) {
+	product, err := testQueries.GetProduct(context.Background(), ProductSavings)
+
+	require.NoError(t, err)
+	require.Equal(t, ProductSavings, product.Code)
+	require.False(t, product.System)
+
+	product, err = testQueries.GetProduct(context.Background(), ProductInterestExpense)
+
+	require.NoError(t, err)
+	require.True(t, product.System)
+}
+
+func TestListProducts(t *testing.T) {
+	products, err := testQueries.ListProducts(context.Background())
+
+	require.NoError(t, err)
+
+	codes := make([]string, len(products))
+
+	for i, product := range products {
+		codes[i] = product.Code
+	}
+
+	require.Equal(t, []string{ProductChecking, ProductSavings}, codes)
+}
+
+func TestUpdateProductInterest(t *testing.T) {
+	savings, err := testQueries.GetProduct(context.Background(), ProductSavings)
+	require.NoError(t, err)
+
+	// other tests accrue interest at the seeded rate
+	defer testQueries.UpdateProductInterest(context.Background(), UpdateProductInterestParams{
+		Code:               savings.Code,
+		InterestRateBps:    savings.InterestRateBps,
+		InterestDaysInYear: savings.InterestDaysInYear,
+	})
+
+	updated, err := testQueries.UpdateProductInterest(context.Background(), UpdateProductInterestParams{
+		Code:               savings.Code,
+		InterestRateBps:    savings.InterestRateBps + 50,
+		InterestDaysInYear: 360,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, savings.InterestRateBps+50, updated.InterestRateBps)
+	require.Equal(t, int32(360), updated.InterestDaysInYear)
+
+	_, err = testQueries.UpdateProductInterest(context.Background(), UpdateProductInterestParams{
+		Code:               ProductCash,
+		InterestRateBps:    100,
+		InterestDaysInYear: 365,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -6,21 +6,132 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
+	// Accrues a day of interest on every open account whose product earns it, on
+	// the balance the account had at day_end. Accounts already accrued for the day
+	// are left alone, so a day can safely be accrued again.
+	AccrueInterest(ctx context.Context, arg AccrueInterestParams) (int64, error)
+	// Holds reserve and release funds by moving only the available balance.
+	AddAccountAvailableBalance(ctx context.Context, arg AddAccountAvailableBalanceParams) (Account, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	// Moves a schedule past the occurrence at scheduled_for. Nothing is updated
+	// when the schedule has been changed since it was claimed.
+	AdvanceScheduledTransfer(ctx context.Context, arg AdvanceScheduledTransferParams) (int64, error)
+	BlockSession(ctx context.Context, id uuid.UUID) error
+	CancelScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	// Leases the schedule that's been due the longest. Rows leased or locked by
+	// another worker are skipped, so any number of workers can claim at once.
+	ClaimDueScheduledTransfer(ctx context.Context, leaseSeconds int32) (ScheduledTransfer, error)
+	// Leases the pending delivery that's been due the longest by pushing its next
+	// attempt back. Rows locked by another worker are skipped, and a delivery
+	// whose worker died is tried again once the lease is over.
+	ClaimDueWebhookDelivery(ctx context.Context, leaseSeconds int32) (WebhookDelivery, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountStatusChange(ctx context.Context, arg CreateAccountStatusChangeParams) (AccountStatusChange, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateSystemAccount(ctx context.Context, arg CreateSystemAccountParams) error
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferFee(ctx context.Context, arg CreateTransferFeeParams) (TransferFee, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	DeleteAccount(ctx context.Context, id int64) error
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error)
+	// Queues the event for every webhook of the owners that takes its type.
+	CreateWebhookDeliveries(ctx context.Context, arg CreateWebhookDeliveriesParams) (int64, error)
+	DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error
+	DeleteTierLimit(ctx context.Context, arg DeleteTierLimitParams) error
+	DeleteWebhook(ctx context.Context, id int64) error
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountLimit(ctx context.Context, accountID int64) (AccountLimit, error)
+	// Reversals are refunds rather than spending, so they don't count.
+	GetAccountOutgoingTotals(ctx context.Context, arg GetAccountOutgoingTotalsParams) (GetAccountOutgoingTotalsRow, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	// Holds locked by another transaction are skipped, so expiry can run in
+	// several places at once.
+	GetExpiredHoldForUpdate(ctx context.Context) (Hold, error)
+	GetFeeRule(ctx context.Context, arg GetFeeRuleParams) (FeeRule, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetInterestPosting(ctx context.Context, arg GetInterestPostingParams) (InterestPosting, error)
+	GetLastInterestAccrualDate(ctx context.Context) (time.Time, error)
+	GetOutboxEvent(ctx context.Context, id int64) (Outbox, error)
+	GetOwnerOutgoingTotals(ctx context.Context, arg GetOwnerOutgoingTotalsParams) (GetOwnerOutgoingTotalsRow, error)
+	GetPostedInterest(ctx context.Context, arg GetPostedInterestParams) (int64, error)
+	GetProduct(ctx context.Context, code string) (Product, error)
+	GetRate(ctx context.Context, arg GetRateParams) (Rate, error)
+	GetReversedAmount(ctx context.Context, reversalOf sql.NullInt64) (GetReversedAmountRow, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSystemAccount(ctx context.Context, arg GetSystemAccountParams) (Account, error)
+	GetTierLimit(ctx context.Context, arg GetTierLimitParams) (TierLimit, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferByIdempotencyKey(ctx context.Context, idempotencyKey sql.NullString) (Transfer, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserForUpdate(ctx context.Context, username string) (User, error)
+	GetWebhook(ctx context.Context, id int64) (Webhook, error)
+	GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	ListAccountStatusChanges(ctx context.Context, accountID int64) ([]AccountStatusChange, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListBalanceMismatches(ctx context.Context) ([]ListBalanceMismatchesRow, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
+	ListCurrencyImbalances(ctx context.Context) ([]ListCurrencyImbalancesRow, error)
+	ListEntries(ctx context.Context, arg ListEntriesParams) ([]ListEntriesRow, error)
+	ListFeeRules(ctx context.Context) ([]FeeRule, error)
+	ListInterestPostings(ctx context.Context, accountID int64) ([]InterestPosting, error)
+	ListProducts(ctx context.Context) ([]Product, error)
+	ListScheduledTransferRuns(ctx context.Context, scheduledTransferID int64) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListSessions(ctx context.Context, arg ListSessionsParams) ([]Session, error)
+	ListTransferEntries(ctx context.Context, transferID sql.NullInt64) ([]Entry, error)
+	// Fee legs share the transfer id but are checked through the currency totals.
+	ListTransferEntryMismatches(ctx context.Context) ([]ListTransferEntryMismatchesRow, error)
+	ListTransferFees(ctx context.Context, transferID int64) ([]TransferFee, error)
+	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListUnpostedInterestPeriods(ctx context.Context, before time.Time) ([]ListUnpostedInterestPeriodsRow, error)
+	ListUnpublishedOutboxEvents(ctx context.Context, limit int32) ([]Outbox, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhooks(ctx context.Context, owner string) ([]Webhook, error)
+	MarkOutboxEventsPublished(ctx context.Context, ids []int64) (int64, error)
+	// Takes over a key for the same request when its response was never recorded,
+	// as when the server died midway, once it's older than stale_seconds.
+	ReclaimIdempotencyKey(ctx context.Context, arg ReclaimIdempotencyKeyParams) (IdempotencyKey, error)
+	RecordWebhookDeliveryAttempt(ctx context.Context, arg RecordWebhookDeliveryAttemptParams) (WebhookDelivery, error)
+	// Queues a delivery that's no longer pending to be sent again from scratch.
+	ReplayWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	RetryScheduledTransfer(ctx context.Context, arg RetryScheduledTransferParams) (int64, error)
+	// Sums balance times rate exactly per day count, leaving the division to the
+	// caller.
+	SumInterestAccruals(ctx context.Context, arg SumInterestAccrualsParams) ([]SumInterestAccrualsRow, error)
+	// Takes a lock held until the end of the transaction, or returns false at once
+	// if another transaction holds it.
+	TryAdvisoryXactLock(ctx context.Context, key int64) (bool, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) error
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateHoldStatus(ctx context.Context, arg UpdateHoldStatusParams) (Hold, error)
+	UpdateIdempotencyKeyResponse(ctx context.Context, arg UpdateIdempotencyKeyResponseParams) error
+	UpdateProductInterest(ctx context.Context, arg UpdateProductInterestParams) (Product, error)
+	// Rescheduling starts the recurrence over from the new start time.
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpdateUserTier(ctx context.Context, arg UpdateUserTierParams) (User, error)
+	UpsertAccountLimit(ctx context.Context, arg UpsertAccountLimitParams) (AccountLimit, error)
+	UpsertFeeRule(ctx context.Context, arg UpsertFeeRuleParams) (FeeRule, error)
+	UpsertRate(ctx context.Context, arg UpsertRateParams) (Rate, error)
+	UpsertTierLimit(ctx context.Context, arg UpsertTierLimitParams) (TierLimit, error)
 }
 
 var _ Querier = (*Queries)(nil)
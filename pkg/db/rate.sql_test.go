@@ -0,0 +1,35 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertRate(t *testing.T) {
+	arg := UpsertRateParams{
+		BaseCurrency:  gofakeit.CurrencyShort(),
+		QuoteCurrency: gofakeit.CurrencyShort(),
+		Rate:          int64(gofakeit.IntRange(1, 1000*int(FxRateScale))),
+	}
+
+	rate1, err := testQueries.UpsertRate(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Rate, rate1.Rate)
+
+	arg.Rate++
+
+	rate2, err := testQueries.UpsertRate(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Rate, rate2.Rate)
+	require.False(t, rate2.UpdatedAt.Before(rate1.UpdatedAt))
+
+	rate3, err := testQueries.GetRate(context.Background(), GetRateParams{
+		BaseCurrency:  arg.BaseCurrency,
+		QuoteCurrency: arg.QuoteCurrency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, rate2, rate3)
+}
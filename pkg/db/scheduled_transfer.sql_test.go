@@ -0,0 +1,161 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createTestScheduledTransfer(t *testing.T, startAt time.Time) ScheduledTransfer {
+	account1 := createTestAccountWithCurrency(t, "USD")
+	account2 := createTestAccountWithCurrency(t, account1.Currency)
+
+	arg := CreateScheduledTransferParams{
+		Owner:         account1.Owner,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      account1.Currency,
+		Recurrence:    sql.NullString{String: "FREQ=DAILY;INTERVAL=1", Valid: true},
+		StartAt:       startAt,
+	}
+
+	schedule, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.NotZero(t, schedule.ID)
+	require.Equal(t, arg.Owner, schedule.Owner)
+	require.Equal(t, arg.Amount, schedule.Amount)
+	require.Equal(t, arg.Recurrence, schedule.Recurrence)
+	require.Equal(t, "active", schedule.Status)
+	require.WithinDuration(t, startAt, schedule.NextRunAt.Time, time.Second)
+	require.WithinDuration(t, startAt, schedule.DueAt.Time, time.Second)
+
+	return schedule
+}
+
+func TestCreateScheduledTransfer(t *testing.T) {
+	createTestScheduledTransfer(t, time.Now().Add(time.Hour))
+}
+
+func TestClaimDueScheduledTransfer(t *testing.T) {
+	due := createTestScheduledTransfer(t, time.Now().Add(-time.Minute))
+	notDue := createTestScheduledTransfer(t, time.Now().Add(time.Hour))
+
+	var claimed ScheduledTransfer
+
+	// other tests may have left due schedules behind
+	for claimed.ID != due.ID {
+		schedule, err := testQueries.ClaimDueScheduledTransfer(context.Background(), 60)
+		require.NoError(t, err)
+		require.NotEqual(t, notDue.ID, schedule.ID)
+
+		claimed = schedule
+	}
+
+	require.True(t, claimed.LockedUntil.Valid)
+	require.WithinDuration(t, time.Now().Add(time.Minute), claimed.LockedUntil.Time, 5*time.Second)
+
+	// leased schedules can't be claimed again
+	for {
+		schedule, err := testQueries.ClaimDueScheduledTransfer(context.Background(), 60)
+
+		if err == sql.ErrNoRows {
+			break
+		}
+
+		require.NoError(t, err)
+		require.NotEqual(t, due.ID, schedule.ID)
+	}
+}
+
+func TestRetryAndAdvanceScheduledTransfer(t *testing.T) {
+	schedule := createTestScheduledTransfer(t, time.Now().Add(time.Hour))
+
+	rows, err := testQueries.RetryScheduledTransfer(context.Background(), RetryScheduledTransferParams{
+		DueAt:        sql.NullTime{Time: schedule.StartAt.Add(time.Minute), Valid: true},
+		LastError:    sql.NullString{String: "insufficient funds", Valid: true},
+		ID:           schedule.ID,
+		ScheduledFor: schedule.NextRunAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	retried, err := testQueries.GetScheduledTransfer(context.Background(), schedule.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), retried.Attempts)
+	require.Equal(t, "insufficient funds", retried.LastError.String)
+	require.WithinDuration(t, schedule.StartAt.Add(time.Minute), retried.DueAt.Time, time.Second)
+
+	next := schedule.StartAt.AddDate(0, 0, 1)
+
+	rows, err = testQueries.AdvanceScheduledTransfer(context.Background(), AdvanceScheduledTransferParams{
+		Status:       "active",
+		NextRunAt:    sql.NullTime{Time: next, Valid: true},
+		ID:           schedule.ID,
+		ScheduledFor: schedule.NextRunAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	// the occurrence has moved on, so a late worker can't advance it again
+	rows, err = testQueries.AdvanceScheduledTransfer(context.Background(), AdvanceScheduledTransferParams{
+		Status:       "active",
+		NextRunAt:    sql.NullTime{Time: next, Valid: true},
+		ID:           schedule.ID,
+		ScheduledFor: schedule.NextRunAt,
+	})
+	require.NoError(t, err)
+	require.Zero(t, rows)
+
+	advanced, err := testQueries.GetScheduledTransfer(context.Background(), schedule.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), advanced.RunCount)
+	require.Zero(t, advanced.Attempts)
+	require.False(t, advanced.LastError.Valid)
+	require.WithinDuration(t, next, advanced.NextRunAt.Time, time.Second)
+	require.WithinDuration(t, next, advanced.DueAt.Time, time.Second)
+}
+
+func TestCancelScheduledTransfer(t *testing.T) {
+	schedule := createTestScheduledTransfer(t, time.Now().Add(time.Hour))
+
+	cancelled, err := testQueries.CancelScheduledTransfer(context.Background(), schedule.ID)
+	require.NoError(t, err)
+	require.Equal(t, "cancelled", cancelled.Status)
+	require.False(t, cancelled.NextRunAt.Valid)
+	require.False(t, cancelled.DueAt.Valid)
+
+	_, err = testQueries.CancelScheduledTransfer(context.Background(), schedule.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:      schedule.ID,
+		Amount:  20,
+		StartAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestListScheduledTransferRuns(t *testing.T) {
+	schedule := createTestScheduledTransfer(t, time.Now().Add(time.Hour))
+
+	for attempt := int32(1); attempt <= 2; attempt++ {
+		_, err := testQueries.CreateScheduledTransferRun(context.Background(), CreateScheduledTransferRunParams{
+			ScheduledTransferID: schedule.ID,
+			ScheduledFor:        schedule.StartAt,
+			Attempt:             attempt,
+			Error:               sql.NullString{String: "insufficient funds", Valid: true},
+		})
+		require.NoError(t, err)
+	}
+
+	runs, err := testQueries.ListScheduledTransferRuns(context.Background(), schedule.ID)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	require.Equal(t, int32(1), runs[0].Attempt)
+	require.Equal(t, int32(2), runs[1].Attempt)
+}
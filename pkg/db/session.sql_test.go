@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestSession(t *testing.T, user User) Session {
+	arg := CreateSessionParams{
+		ID:           uuid.New(),
+		Username:     user.Username,
+		RefreshToken: gofakeit.LetterN(64),
+		UserAgent:    gofakeit.UserAgent(),
+		ClientIp:     gofakeit.IPv4Address(),
+		IsBlocked:    false,
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+
+	session, err := testQueries.CreateSession(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, session)
+
+	require.Equal(t, arg.ID, session.ID)
+	require.Equal(t, arg.Username, session.Username)
+	require.Equal(t, arg.RefreshToken, session.RefreshToken)
+	require.Equal(t, arg.UserAgent, session.UserAgent)
+	require.Equal(t, arg.ClientIp, session.ClientIp)
+	require.False(t, session.IsBlocked)
+	require.WithinDuration(t, arg.ExpiresAt, session.ExpiresAt, time.Second)
+	require.NotZero(t, session.CreatedAt)
+
+	return session
+}
+
+func TestCreateSession(t *testing.T) {
+	createTestSession(t, createTestUser(t))
+}
+
+func TestBlockSession(t *testing.T) {
+	session1 := createTestSession(t, createTestUser(t))
+
+	err := testQueries.BlockSession(context.Background(), session1.ID)
+	require.NoError(t, err)
+
+	session2, err := testQueries.GetSession(context.Background(), session1.ID)
+
+	require.NoError(t, err)
+	require.True(t, session2.IsBlocked)
+	require.Equal(t, session1.RefreshToken, session2.RefreshToken)
+}
+
+func TestListSessions(t *testing.T) {
+	user := createTestUser(t)
+
+	for range 3 {
+		createTestSession(t, user)
+	}
+
+	sessions, err := testQueries.ListSessions(context.Background(), ListSessionsParams{
+		Username: user.Username,
+		Limit:    5,
+		Offset:   0,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, sessions, 3)
+
+	for _, session := range sessions {
+		require.Equal(t, user.Username, session.Username)
+	}
+}
@@ -3,12 +3,73 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/lib/pq"
+)
+
+// maxTxAttempts bounds how many times a transaction is run when postgres
+// aborts it with a serialization failure or a detected deadlock.
+const maxTxAttempts = 3
+
+// SystemAccountOwner owns the per-currency cash accounts that money enters
+// and leaves the bank through.
+const SystemAccountOwner = "system"
+
+// Account statuses. Frozen accounts can still be credited but not debited,
+// and closed accounts can't be posted to at all.
+const (
+	AccountActive = "active"
+	AccountFrozen = "frozen"
+	AccountClosed = "closed"
 )
 
+// statusTransitions lists the statuses an account may move to from each
+// status. Closing an account is final.
+var statusTransitions = map[string][]string{
+	AccountActive: {AccountFrozen, AccountClosed},
+	AccountFrozen: {AccountActive, AccountClosed},
+}
+
+var (
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrSystemAccount     = errors.New("system account")
+	ErrAlreadyReversed   = errors.New("transfer already reversed")
+	ErrNotReversible     = errors.New("transfer can't be reversed")
+	ErrMissingFxRate     = errors.New("missing fx rate")
+	ErrAccountFrozen     = errors.New("account frozen")
+	ErrAccountClosed     = errors.New("account closed")
+	ErrStatusChange      = errors.New("invalid account status change")
+	ErrNonZeroBalance    = errors.New("account balance isn't zero")
+)
+
+// FxRateScale is the fixed-point scale of transfer fx rates: a rate of
+// FxRateScale converts one major unit into one major unit. Amounts are kept in
+// minor units, so converting one also applies the difference in the minor
+// units of the two currencies; see fx.Convert.
+const FxRateScale int64 = 100_000_000
+
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg CreateTransferParams) (TransferTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (TransferTxResult, error)
+	AdjustBalanceTx(ctx context.Context, arg AdjustBalanceTxParams) (AdjustBalanceTxResult, error)
+	DepositTx(ctx context.Context, arg CashTxParams) (CashTxResult, error)
+	WithdrawTx(ctx context.Context, arg CashTxParams) (CashTxResult, error)
+	ChangeAccountStatusTx(ctx context.Context, arg ChangeAccountStatusTxParams) (ChangeAccountStatusTxResult, error)
+	CreateHoldTx(ctx context.Context, arg CreateHoldTxParams) (HoldTxResult, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error)
+	VoidHoldTx(ctx context.Context, holdID int64) (HoldTxResult, error)
+	ExpireHoldTx(ctx context.Context) (HoldTxResult, error)
+	PostInterestTx(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error)
+	RelayOutboxTx(ctx context.Context, arg RelayOutboxTxParams) (int, error)
 }
 
 type SQLStore struct {
@@ -44,63 +105,703 @@ func (s *SQLStore) execTx(ctx context.Context, cb func(q *Queries) error) error
 	return tx.Commit()
 }
 
+// execTxWithRetry runs cb through execTx and runs it again, up to
+// maxTxAttempts times, when the transaction fails for a retryable reason.
+func (s *SQLStore) execTxWithRetry(ctx context.Context, cb func(q *Queries) error) error {
+	var err error
+
+	for range maxTxAttempts {
+		err = s.execTx(ctx, cb)
+
+		if !isRetryableTxError(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func isRetryableTxError(err error) bool {
+	var pqError *pq.Error
+
+	if !errors.As(err, &pqError) {
+		return false
+	}
+
+	switch pqError.Code.Name() {
+	case "serialization_failure", "deadlock_detected":
+		return true
+	}
+
+	return false
+}
+
 type TransferTxResult struct {
 	Transfer    Transfer `json:"transfer"`
 	FromAccount Account  `json:"from_account"`
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// Fees lists each fee charged to the sender on top of the amount.
+	Fees []TransferFee `json:"fees"`
 }
 
+// TransferTx moves amount between two accounts in a single transaction. Both
+// account rows are locked in ascending id order so that concurrent transfers
+// in opposite directions can't deadlock each other. A transfer that would take
+// the sender over one of its outgoing limits fails with a *LimitError. Fees
+// due under the sender's fee rule are charged in the same transaction.
 func (s *SQLStore) TransferTx(ctx context.Context, arg CreateTransferParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
-	err := s.execTx(ctx, func(q *Queries) error {
+	err := s.execTxWithRetry(ctx, func(q *Queries) error {
 		var err error
 
-		result.Transfer, err = s.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
+		result, err = transfer(ctx, q, arg)
+
+		return err
+	})
+
+	return result, err
+}
+
+// IsTransferRejected reports whether err from TransferTx means the transfer
+// was refused, such as for insufficient funds or a limit, rather than that it
+// failed.
+func IsTransferRejected(err error) bool {
+	return errors.Is(err, ErrLimitExceeded) || errors.Is(err, ErrInsufficientFunds) || errors.Is(err, money.ErrOverflow) ||
+		errors.Is(err, ErrAccountFrozen) || errors.Is(err, ErrAccountClosed)
+}
+
+// posting is one entry of a transfer together with where the entry and the
+// updated account end up in the result.
+type posting struct {
+	entry   *Entry
+	account *Account
+	target  Account
+	amount  money.Money
+}
+
+// post writes the entries and balance updates of postings. Every posting is
+// checked before anything is written, so an amount that would overflow a
+// balance, land in the wrong currency or move money on a frozen or closed
+// account fails the transaction up front.
+func post(ctx context.Context, q *Queries, transferID sql.NullInt64, postings []posting) error {
+	for _, p := range postings {
+		if err := checkStatus(p.target, p.amount); err != nil {
+			return err
+		}
+
+		if _, err := balanceOf(p.target).Add(p.amount); err != nil {
+			return fmt.Errorf("account %d: %w", p.target.ID, err)
+		}
+	}
+
+	for _, p := range postings {
+		entry, err := q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  p.target.ID,
+			Amount:     p.amount.Amount,
+			TransferID: transferID,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if p.entry != nil {
+			*p.entry = entry
+		}
+	}
+
+	for _, p := range postings {
+		account, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{ID: p.target.ID, Amount: p.amount.Amount})
+
+		if err != nil {
+			return err
+		}
+
+		if p.account != nil {
+			*p.account = account
+		}
+	}
+
+	return nil
+}
+
+// balanceOf returns the balance of account as money in its currency.
+func balanceOf(account Account) money.Money {
+	return money.New(account.Balance, account.Currency)
+}
+
+// checkStatus makes sure amount can be posted to account given its status.
+func checkStatus(account Account, amount money.Money) error {
+	switch {
+	case account.Status == AccountClosed:
+		return fmt.Errorf("%w: account %d", ErrAccountClosed, account.ID)
+	case account.Status == AccountFrozen && amount.IsNegative():
+		return fmt.Errorf("%w: account %d can't be debited", ErrAccountFrozen, account.ID)
+	}
+
+	return nil
+}
+
+// availableOf returns the balance of account less what active holds reserve.
+func availableOf(account Account) money.Money {
+	return money.New(account.AvailableBalance, account.Currency)
+}
+
+// checkFunds makes sure account can be debited amount without touching funds
+// reserved by holds.
+func checkFunds(account Account, amount money.Money) error {
+	cmp, err := availableOf(account).Cmp(amount)
+
+	if err != nil {
+		return err
+	}
+
+	if cmp < 0 {
+		return fmt.Errorf("%w: account %d has available balance %s, needs %s", ErrInsufficientFunds, account.ID, availableOf(account), amount)
+	}
+
+	return nil
+}
+
+// transfer does the work of TransferTx inside a transaction that's already
+// open. Between accounts in different currencies the sender is debited amount
+// and the recipient credited to_amount, with the difference carried by the
+// cash accounts of both currencies so each currency still nets to zero. Each
+// fee is a further debit from the sender credited to the fee income account of
+// its currency.
+func transfer(ctx context.Context, q *Queries, arg CreateTransferParams) (TransferTxResult, error) {
+	pending, err := lockTransfer(ctx, q, arg)
+
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	return pending.post(ctx, q)
+}
+
+// pendingTransfer is a transfer whose accounts are locked but that hasn't
+// been posted yet.
+type pendingTransfer struct {
+	arg             CreateTransferParams
+	fromAccount     Account
+	toAccount       Account
+	fromCashAccount Account
+	toCashAccount   Account
+	feeAccount      Account
+	fees            []fee
+	owner           User
+	// accounts holds the locked rows of every account the transfer posts to.
+	accounts map[int64]Account
+}
+
+// lockTransfer finds the accounts, fees and fx legs of a transfer and locks
+// the sender's owner and then every account it posts to, the cash and fee
+// income accounts included, in a single ascending pass.
+func lockTransfer(ctx context.Context, q *Queries, arg CreateTransferParams) (*pendingTransfer, error) {
+	fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	toAccount, err := q.GetAccount(ctx, arg.ToAccountID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	arg.Currency = fromAccount.Currency
+	arg.ToCurrency = toAccount.Currency
+
+	t := &pendingTransfer{
+		fromAccount: fromAccount,
+		toAccount:   toAccount,
+	}
+
+	accountIDs := []int64{fromAccount.ID, toAccount.ID}
+
+	if fromAccount.Currency == toAccount.Currency {
+		arg.ToAmount = arg.Amount
+		arg.FxRate = FxRateScale
+		arg.FxSpreadBps = 0
+	} else {
+		if arg.ToAmount <= 0 || arg.FxRate <= 0 {
+			return nil, fmt.Errorf("%w: %s to %s", ErrMissingFxRate, fromAccount.Currency, toAccount.Currency)
+		}
+
+		t.fromCashAccount, err = openSystemAccount(ctx, q, fromAccount.Currency, ProductCash)
+
+		if err != nil {
+			return nil, err
+		}
+
+		t.toCashAccount, err = openSystemAccount(ctx, q, toAccount.Currency, ProductCash)
+
+		if err != nil {
+			return nil, err
+		}
+
+		accountIDs = append(accountIDs, t.fromCashAccount.ID, t.toCashAccount.ID)
+	}
+
+	t.arg = arg
+
+	// reversals give money back and are free
+	if !arg.ReversalOf.Valid {
+		t.fees, err = findFees(ctx, q, fromAccount, toAccount, arg.Amount)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(t.fees) > 0 {
+		t.feeAccount, err = openSystemAccount(ctx, q, fromAccount.Currency, ProductFeeIncome)
+
+		if err != nil {
+			return nil, err
+		}
+
+		accountIDs = append(accountIDs, t.feeAccount.ID)
+	}
+
+	// outgoing limits are enforced on the owner's totals, so the owner is
+	// locked before any account to serialize their transfers. Reversals give
+	// money back and aren't limited.
+	if !arg.ReversalOf.Valid {
+		t.owner, err = q.GetUserForUpdate(ctx, fromAccount.Owner)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	t.accounts, err = lockAccounts(ctx, q, accountIDs...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// post checks funds and limits against the locked accounts and writes the
+// transfer, its entries and fees and its event.
+func (t *pendingTransfer) post(ctx context.Context, q *Queries) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	arg := t.arg
+	fromAccount := t.accounts[t.fromAccount.ID]
+
+	sent := money.New(arg.Amount, arg.Currency)
+	received := money.New(arg.ToAmount, arg.ToCurrency)
+
+	charged, err := totalOf(sent, t.fees)
+
+	if err != nil {
+		return result, err
+	}
+
+	if err := checkFunds(fromAccount, charged); err != nil {
+		return result, err
+	}
+
+	if !arg.ReversalOf.Valid {
+		if err := checkLimits(ctx, q, fromAccount, t.owner, sent, time.Now()); err != nil {
+			return result, err
+		}
+	}
+
+	debit, err := sent.Neg()
+
+	if err != nil {
+		return result, err
+	}
+
+	paidOut, err := received.Neg()
+
+	if err != nil {
+		return result, err
+	}
+
+	result.Transfer, err = q.CreateTransfer(ctx, arg)
+
+	if err != nil {
+		return result, err
+	}
+
+	postings := []posting{
+		{&result.FromEntry, &result.FromAccount, fromAccount, debit},
+		{&result.ToEntry, &result.ToAccount, t.accounts[t.toAccount.ID], received},
+	}
+
+	if t.fromCashAccount.ID != 0 {
+		postings = append(postings,
+			posting{target: t.accounts[t.fromCashAccount.ID], amount: sent},
+			posting{target: t.accounts[t.toCashAccount.ID], amount: paidOut},
+		)
+	}
+
+	feeEntries := make([]Entry, 2*len(t.fees))
+
+	for i, f := range t.fees {
+		charge, err := f.amount.Neg()
+
+		if err != nil {
+			return result, err
+		}
+
+		postings = append(postings,
+			posting{&feeEntries[2*i], &result.FromAccount, fromAccount, charge},
+			posting{entry: &feeEntries[2*i+1], target: t.accounts[t.feeAccount.ID], amount: f.amount},
+		)
+	}
+
+	err = post(ctx, q, sql.NullInt64{Int64: result.Transfer.ID, Valid: true}, postings)
+
+	if err != nil {
+		return result, err
+	}
+
+	for i, f := range t.fees {
+		transferFee, err := q.CreateTransferFee(ctx, CreateTransferFeeParams{
+			TransferID:    result.Transfer.ID,
+			Kind:          f.kind,
+			Amount:        f.amount.Amount,
+			Currency:      f.amount.Currency,
+			DebitEntryID:  feeEntries[2*i].ID,
+			CreditEntryID: feeEntries[2*i+1].ID,
 		})
 
+		if err != nil {
+			return result, err
+		}
+
+		result.Fees = append(result.Fees, transferFee)
+	}
+
+	event := TransferCompletedEvent{
+		Transfer: result.Transfer,
+		Fees:     result.Fees,
+	}
+
+	// the event is written under the key of each account so it's ordered
+	// with the other events of both; webhooks are sent the sender's copy
+	err = enqueue(ctx, q, EventTransferCompleted, AccountKey(t.fromAccount.ID), event, t.fromAccount.Owner, t.toAccount.Owner)
+
+	if err != nil {
+		return result, err
+	}
+
+	err = enqueue(ctx, q, EventTransferCompleted, AccountKey(t.toAccount.ID), event)
+
+	return result, err
+}
+
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount to send back, in the currency the recipient received. Zero
+	// reverses whatever hasn't been reversed yet.
+	Amount money.Money `json:"amount"`
+}
+
+// ReverseTransferTx sends all or part of a transfer back from the recipient
+// to the sender as a new transfer linked through reversal_of. The original
+// transfer row is locked so that concurrent reversals can't together return
+// more than was sent.
+func (s *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := s.execTxWithRetry(ctx, func(q *Queries) error {
+		result = TransferTxResult{}
+
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+
+		if err != nil {
+			return err
+		}
+
+		if original.ReversalOf.Valid {
+			return fmt.Errorf("%w: transfer %d reverses transfer %d", ErrNotReversible, original.ID, original.ReversalOf.Int64)
+		}
+
+		// money the bank paid out, like interest, is recorded as paid
+		// elsewhere, so the recipient can't send it back
+		sender, err := q.GetAccount(ctx, original.FromAccountID)
+
 		if err != nil {
 			return err
 		}
 
-		result.FromEntry, err = s.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
+		if sender.Owner == SystemAccountOwner {
+			return fmt.Errorf("%w: transfer %d was paid from system account %d", ErrNotReversible, original.ID, sender.ID)
+		}
+
+		reversed, err := q.GetReversedAmount(ctx, sql.NullInt64{Int64: original.ID, Valid: true})
+
+		if err != nil {
+			return err
+		}
+
+		// reversals are measured in what the recipient got, and the sender
+		// is paid back the same share of what they were debited
+		remaining := original.ToAmount - reversed.Amount
+		amount := remaining
+
+		if !arg.Amount.IsZero() {
+			if arg.Amount.Currency != original.ToCurrency {
+				return fmt.Errorf("%w: transfer %d was received in %s, not %s", money.ErrCurrencyMismatch, original.ID, original.ToCurrency, arg.Amount.Currency)
+			}
+
+			amount = arg.Amount.Amount
+		}
+
+		if remaining == 0 || amount > remaining {
+			return fmt.Errorf("%w: transfer %d has %d of %d left to reverse", ErrAlreadyReversed, original.ID, remaining, original.ToAmount)
+		}
+
+		toAmount := original.Amount - reversed.ToAmount
+
+		if amount < remaining {
+			toAmount = mulDiv(amount, original.Amount, original.ToAmount)
+		}
+
+		if toAmount <= 0 {
+			return fmt.Errorf("%w: %d is too small to pay back", ErrNotReversible, amount)
+		}
+
+		result, err = transfer(ctx, q, CreateTransferParams{
+			FromAccountID: original.ToAccountID,
+			ToAccountID:   original.FromAccountID,
+			Amount:        amount,
+			ReversalOf:    sql.NullInt64{Int64: original.ID, Valid: true},
+			ToAmount:      toAmount,
+			FxRate:        mulDiv(FxRateScale, FxRateScale, original.FxRate),
 		})
 
+		return err
+	})
+
+	return result, err
+}
+
+// lockAccounts takes row locks on the accounts in ascending id order so that
+// transactions locking overlapping accounts can't deadlock each other.
+func lockAccounts(ctx context.Context, q *Queries, accountIDs ...int64) (map[int64]Account, error) {
+	ids := slices.Clone(accountIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	accounts := make(map[int64]Account, len(ids))
+
+	for _, id := range ids {
+		account, err := q.GetAccountForUpdate(ctx, id)
+
+		if err != nil {
+			return nil, err
+		}
+
+		accounts[id] = account
+	}
+
+	return accounts, nil
+}
+
+// openSystemAccount returns the system account of product for currency,
+// opening it the first time it's used.
+func openSystemAccount(ctx context.Context, q *Queries, currency, product string) (Account, error) {
+	arg := CreateSystemAccountParams{Currency: currency, Product: product}
+
+	if err := q.CreateSystemAccount(ctx, arg); err != nil {
+		return Account{}, err
+	}
+
+	return q.GetSystemAccount(ctx, GetSystemAccountParams(arg))
+}
+
+type AdjustBalanceTxParams struct {
+	AccountID int64       `json:"account_id"`
+	Amount    money.Money `json:"amount"`
+}
+
+type AdjustBalanceTxResult struct {
+	Account Account `json:"account"`
+	Entry   Entry   `json:"entry"`
+}
+
+// AdjustBalanceTx posts a manual correction to a single account, balanced
+// against the cash account of its currency. The balance is not allowed to go
+// below zero.
+func (s *SQLStore) AdjustBalanceTx(ctx context.Context, arg AdjustBalanceTxParams) (AdjustBalanceTxResult, error) {
+	result, err := s.postCashTx(ctx, EventBalanceAdjusted, arg.AccountID, arg.Amount)
+
+	return AdjustBalanceTxResult{
+		Account: result.Account,
+		Entry:   result.Entry,
+	}, err
+}
+
+type CashTxParams struct {
+	AccountID int64       `json:"account_id"`
+	Amount    money.Money `json:"amount"`
+}
+
+type CashTxResult struct {
+	Account     Account `json:"account"`
+	CashAccount Account `json:"cash_account"`
+	Entry       Entry   `json:"entry"`
+	CashEntry   Entry   `json:"cash_entry"`
+}
+
+// DepositTx credits the account with cash paid in at a teller.
+func (s *SQLStore) DepositTx(ctx context.Context, arg CashTxParams) (CashTxResult, error) {
+	return s.postCashTx(ctx, EventCashDeposited, arg.AccountID, arg.Amount)
+}
+
+// WithdrawTx debits the account for cash paid out at a teller.
+func (s *SQLStore) WithdrawTx(ctx context.Context, arg CashTxParams) (CashTxResult, error) {
+	amount, err := arg.Amount.Neg()
+
+	if err != nil {
+		return CashTxResult{}, err
+	}
+
+	return s.postCashTx(ctx, EventCashWithdrawn, arg.AccountID, amount)
+}
+
+// postCashTx adds amount to the account and takes it from the cash account of
+// the same currency, so entries keep summing to zero per currency. Only the
+// customer account is checked for funds; the cash account goes negative as
+// money comes in. The posting is written to the outbox as eventType.
+func (s *SQLStore) postCashTx(ctx context.Context, eventType string, accountID int64, amount money.Money) (CashTxResult, error) {
+	var result CashTxResult
+
+	err := s.execTxWithRetry(ctx, func(q *Queries) error {
+		result = CashTxResult{}
+
+		account, err := q.GetAccount(ctx, accountID)
+
+		if err != nil {
+			return err
+		}
+
+		if account.Owner == SystemAccountOwner {
+			return fmt.Errorf("%w: account %d can't be posted to directly", ErrSystemAccount, account.ID)
+		}
+
+		cashAccount, err := openSystemAccount(ctx, q, account.Currency, ProductCash)
+
+		if err != nil {
+			return err
+		}
+
+		accounts, err := lockAccounts(ctx, q, account.ID, cashAccount.ID)
+
 		if err != nil {
 			return err
 		}
 
-		result.ToEntry, err = s.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+		cashAmount, err := amount.Neg()
+
+		if err != nil {
+			return err
+		}
+
+		if amount.IsNegative() {
+			if err := checkFunds(accounts[account.ID], cashAmount); err != nil {
+				return err
+			}
+		}
+
+		err = post(ctx, q, sql.NullInt64{}, []posting{
+			{&result.Entry, &result.Account, accounts[account.ID], amount},
+			{&result.CashEntry, &result.CashAccount, accounts[cashAccount.ID], cashAmount},
 		})
 
 		if err != nil {
 			return err
 		}
 
-		result.FromAccount, err = s.AddAccountBalance(ctx, AddAccountBalanceParams{ID: arg.FromAccountID, Amount: -arg.Amount})
+		return enqueue(ctx, q, eventType, AccountKey(account.ID), CashPostedEvent{
+			Entry:    result.Entry,
+			Currency: account.Currency,
+		}, account.Owner)
+	})
+
+	return result, err
+}
+
+type ChangeAccountStatusTxParams struct {
+	AccountID int64  `json:"account_id"`
+	Status    string `json:"status"`
+	ChangedBy string `json:"changed_by"`
+	Reason    string `json:"reason"`
+}
+
+type ChangeAccountStatusTxResult struct {
+	Account Account             `json:"account"`
+	Change  AccountStatusChange `json:"change"`
+}
+
+// ChangeAccountStatusTx moves an account to a new status and records who did
+// it and why. The account row is locked so that it can't be posted to while
+// it's being closed, and it may only be closed with a zero balance.
+func (s *SQLStore) ChangeAccountStatusTx(ctx context.Context, arg ChangeAccountStatusTxParams) (ChangeAccountStatusTxResult, error) {
+	var result ChangeAccountStatusTxResult
+
+	err := s.execTxWithRetry(ctx, func(q *Queries) error {
+		result = ChangeAccountStatusTxResult{}
+
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
 
 		if err != nil {
 			return err
 		}
 
-		result.ToAccount, err = s.AddAccountBalance(ctx, AddAccountBalanceParams{ID: arg.ToAccountID, Amount: arg.Amount})
+		if account.Owner == SystemAccountOwner {
+			return fmt.Errorf("%w: account %d can't change status", ErrSystemAccount, account.ID)
+		}
+
+		if !slices.Contains(statusTransitions[account.Status], arg.Status) {
+			return fmt.Errorf("%w: account %d can't go from %s to %s", ErrStatusChange, account.ID, account.Status, arg.Status)
+		}
+
+		if arg.Status == AccountClosed && account.Balance != 0 {
+			return fmt.Errorf("%w: account %d has balance %s", ErrNonZeroBalance, account.ID, balanceOf(account))
+		}
+
+		result.Account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			ID:     account.ID,
+			Status: arg.Status,
+		})
 
 		if err != nil {
 			return err
 		}
 
-		return nil
+		result.Change, err = q.CreateAccountStatusChange(ctx, CreateAccountStatusChangeParams{
+			AccountID:  account.ID,
+			FromStatus: account.Status,
+			ToStatus:   arg.Status,
+			ChangedBy:  arg.ChangedBy,
+			Reason:     arg.Reason,
+		})
 
+		if err != nil {
+			return err
+		}
+
+		return enqueue(ctx, q, EventAccountStatus, AccountKey(account.ID), result.Change, account.Owner)
 	})
 
 	return result, err
 }
+
+// mulDiv returns a*b/c rounded down without overflowing on the product.
+func mulDiv(a, b, c int64) int64 {
+	product := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+	return product.Quo(product, big.NewInt(c)).Int64()
+}
@@ -2,8 +2,15 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
 	"testing"
+	"time"
 
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/brianvoe/gofakeit/v7"
 	"github.com/stretchr/testify/require"
 )
 
@@ -11,7 +18,7 @@ func TestTransferTx(t *testing.T) {
 	s := NewStore(testDB)
 
 	fromAccount := createTestAccount(t)
-	toAccount := createTestAccount(t)
+	toAccount := createTestAccountWithCurrency(t, fromAccount.Currency)
 
 	arg := CreateTransferParams{
 		FromAccountID: fromAccount.ID,
@@ -81,3 +88,895 @@ func TestTransferTx(t *testing.T) {
 	}
 
 }
+
+func TestTransferTxDeadlock(t *testing.T) {
+	s := NewStore(testDB)
+
+	account1 := createTestAccount(t)
+	account2 := createTestAccountWithCurrency(t, account1.Currency)
+
+	n := 10
+	amount := int64(10)
+
+	errs := make(chan error)
+
+	for i := range n {
+		fromAccountID, toAccountID := account1.ID, account2.ID
+
+		if i%2 == 1 {
+			fromAccountID, toAccountID = account2.ID, account1.ID
+		}
+
+		go func() {
+			_, err := s.TransferTx(context.Background(), CreateTransferParams{
+				FromAccountID: fromAccountID,
+				ToAccountID:   toAccountID,
+				Amount:        amount,
+			})
+
+			errs <- err
+		}()
+	}
+
+	for range n {
+		require.NoError(t, <-errs)
+	}
+
+	// equal number of transfers in each direction leaves balances untouched
+	updatedAccount1, err := s.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	updatedAccount2, err := s.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+}
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	s := NewStore(testDB)
+
+	fromAccount := createTestAccount(t)
+	toAccount := createTestAccountWithCurrency(t, fromAccount.Currency)
+
+	arg := CreateTransferParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        fromAccount.Balance + 1,
+	}
+
+	result, err := s.TransferTx(context.Background(), arg)
+
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	require.Empty(t, result.Transfer)
+
+	updatedFromAccount, err := s.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance, updatedFromAccount.Balance)
+
+	updatedToAccount, err := s.GetAccount(context.Background(), toAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, toAccount.Balance, updatedToAccount.Balance)
+}
+
+func TestAdjustBalanceTx(t *testing.T) {
+	s := NewStore(testDB)
+
+	account := createTestAccount(t)
+
+	result, err := s.AdjustBalanceTx(context.Background(), AdjustBalanceTxParams{
+		AccountID: account.ID,
+		Amount:    money.New(-10, account.Currency),
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, account.Balance-10, result.Account.Balance)
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.Equal(t, int64(-10), result.Entry.Amount)
+
+	_, err = s.AdjustBalanceTx(context.Background(), AdjustBalanceTxParams{
+		AccountID: account.ID,
+		Amount:    money.New(-result.Account.Balance-1, account.Currency),
+	})
+
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestDepositAndWithdrawTx(t *testing.T) {
+	s := NewStore(testDB)
+
+	account := createTestAccount(t)
+
+	deposit, err := s.DepositTx(context.Background(), CashTxParams{AccountID: account.ID, Amount: money.New(100, account.Currency)})
+
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+100, deposit.Account.Balance)
+	require.Equal(t, SystemAccountOwner, deposit.CashAccount.Owner)
+	require.Equal(t, account.Currency, deposit.CashAccount.Currency)
+	require.Equal(t, int64(100), deposit.Entry.Amount)
+	require.Equal(t, int64(-100), deposit.CashEntry.Amount)
+
+	withdrawal, err := s.WithdrawTx(context.Background(), CashTxParams{AccountID: account.ID, Amount: money.New(40, account.Currency)})
+
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+60, withdrawal.Account.Balance)
+	require.Equal(t, deposit.CashAccount.ID, withdrawal.CashAccount.ID)
+	require.Equal(t, deposit.CashAccount.Balance+40, withdrawal.CashAccount.Balance)
+
+	_, err = s.WithdrawTx(context.Background(), CashTxParams{AccountID: account.ID, Amount: money.New(withdrawal.Account.Balance+1, account.Currency)})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = s.DepositTx(context.Background(), CashTxParams{AccountID: account.ID, Amount: money.New(math.MaxInt64, account.Currency)})
+	require.ErrorIs(t, err, money.ErrOverflow)
+
+	otherCurrency := "USD"
+	if account.Currency == otherCurrency {
+		otherCurrency = "EUR"
+	}
+
+	_, err = s.DepositTx(context.Background(), CashTxParams{AccountID: account.ID, Amount: money.New(100, otherCurrency)})
+	require.ErrorIs(t, err, money.ErrCurrencyMismatch)
+
+	_, err = s.DepositTx(context.Background(), CashTxParams{AccountID: deposit.CashAccount.ID, Amount: money.New(100, account.Currency)})
+	require.ErrorIs(t, err, ErrSystemAccount)
+}
+
+func TestReverseTransferTx(t *testing.T) {
+	s := NewStore(testDB)
+
+	account1 := createTestAccount(t)
+	account2 := createTestAccountWithCurrency(t, account1.Currency)
+
+	original, err := s.TransferTx(context.Background(), CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	partial, err := s.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     money.New(4, account2.Currency),
+	})
+	require.NoError(t, err)
+	require.Equal(t, account2.ID, partial.Transfer.FromAccountID)
+	require.Equal(t, account1.ID, partial.Transfer.ToAccountID)
+	require.Equal(t, int64(4), partial.Transfer.Amount)
+	require.Equal(t, original.Transfer.ID, partial.Transfer.ReversalOf.Int64)
+
+	_, err = s.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     money.New(7, account2.Currency),
+	})
+	require.ErrorIs(t, err, ErrAlreadyReversed)
+
+	rest, err := s.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: original.Transfer.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(6), rest.Transfer.Amount)
+	require.Equal(t, account1.Balance, rest.ToAccount.Balance)
+	require.Equal(t, account2.Balance, rest.FromAccount.Balance)
+
+	_, err = s.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: original.Transfer.ID})
+	require.ErrorIs(t, err, ErrAlreadyReversed)
+
+	_, err = s.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: rest.Transfer.ID})
+	require.ErrorIs(t, err, ErrNotReversible)
+}
+
+func TestTransferTxCrossCurrency(t *testing.T) {
+	s := NewStore(testDB)
+
+	fromAccount := createTestAccountWithCurrency(t, "USD")
+	toAccount := createTestAccountWithCurrency(t, "EUR")
+
+	_, err := s.TransferTx(context.Background(), CreateTransferParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        100,
+	})
+	require.ErrorIs(t, err, ErrMissingFxRate)
+
+	result, err := s.TransferTx(context.Background(), CreateTransferParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        100,
+		ToAmount:      91,
+		FxRate:        91_540_000,
+		FxSpreadBps:   50,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(100), result.Transfer.Amount)
+	require.Equal(t, int64(91), result.Transfer.ToAmount)
+	require.Equal(t, int64(91_540_000), result.Transfer.FxRate)
+	require.Equal(t, int32(50), result.Transfer.FxSpreadBps)
+	require.Equal(t, int64(-100), result.FromEntry.Amount)
+	require.Equal(t, int64(91), result.ToEntry.Amount)
+	require.Equal(t, fromAccount.Balance-100, result.FromAccount.Balance)
+	require.Equal(t, toAccount.Balance+91, result.ToAccount.Balance)
+
+	mismatches, err := s.ListTransferEntryMismatches(context.Background())
+	require.NoError(t, err)
+
+	for _, mismatch := range mismatches {
+		require.NotEqual(t, result.Transfer.ID, mismatch.TransferID)
+	}
+
+	reversal, err := s.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: result.Transfer.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(91), reversal.Transfer.Amount)
+	require.Equal(t, int64(100), reversal.Transfer.ToAmount)
+	require.Equal(t, fromAccount.Balance, reversal.ToAccount.Balance)
+	require.Equal(t, toAccount.Balance, reversal.FromAccount.Balance)
+}
+
+func TestChangeAccountStatusTx(t *testing.T) {
+	s := NewStore(testDB)
+
+	account1 := createTestAccount(t)
+	account2 := createTestAccountWithCurrency(t, account1.Currency)
+
+	frozen, err := s.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountFrozen,
+		ChangedBy: account2.Owner,
+		Reason:    "suspected fraud",
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountFrozen, frozen.Account.Status)
+	require.Equal(t, AccountActive, frozen.Change.FromStatus)
+	require.Equal(t, AccountFrozen, frozen.Change.ToStatus)
+	require.Equal(t, account2.Owner, frozen.Change.ChangedBy)
+
+	_, err = s.TransferTx(context.Background(), CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = s.TransferTx(context.Background(), CreateTransferParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	_, err = s.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountClosed,
+		ChangedBy: account1.Owner,
+		Reason:    "moving banks",
+	})
+	require.ErrorIs(t, err, ErrNonZeroBalance)
+
+	active, err := s.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountActive,
+		ChangedBy: account2.Owner,
+		Reason:    "cleared",
+	})
+	require.NoError(t, err)
+
+	_, err = s.WithdrawTx(context.Background(), CashTxParams{
+		AccountID: account1.ID,
+		Amount:    money.New(active.Account.Balance, account1.Currency),
+	})
+	require.NoError(t, err)
+
+	closed, err := s.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountClosed,
+		ChangedBy: account1.Owner,
+		Reason:    "moving banks",
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountClosed, closed.Account.Status)
+
+	_, err = s.TransferTx(context.Background(), CreateTransferParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	_, err = s.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountActive,
+		ChangedBy: account2.Owner,
+		Reason:    "reopen",
+	})
+	require.ErrorIs(t, err, ErrStatusChange)
+
+	changes, err := s.ListAccountStatusChanges(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+}
+
+func TestTransferTxLimits(t *testing.T) {
+	s := NewStore(testDB)
+
+	account1 := createTestAccountWithCurrency(t, "USD")
+	account2 := createTestAccountWithCurrency(t, account1.Currency)
+
+	_, err := s.UpsertAccountLimit(context.Background(), UpsertAccountLimitParams{
+		AccountID:        account1.ID,
+		PerTransferLimit: sql.NullInt64{Int64: 100, Valid: true},
+		DailyLimit:       sql.NullInt64{Int64: 150, Valid: true},
+	})
+	require.NoError(t, err)
+
+	transfer := func(amount int64) error {
+		_, err := s.TransferTx(context.Background(), CreateTransferParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+		})
+
+		return err
+	}
+
+	require.NoError(t, transfer(100))
+
+	var limitErr *LimitError
+
+	err = transfer(101)
+	require.ErrorIs(t, err, ErrLimitExceeded)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitScopeAccount, limitErr.Scope)
+	require.Equal(t, LimitPerTransfer, limitErr.Limit)
+	require.Equal(t, money.New(100, account1.Currency), limitErr.Remaining)
+
+	err = transfer(60)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitScopeAccount, limitErr.Scope)
+	require.Equal(t, LimitDaily, limitErr.Limit)
+	require.Equal(t, money.New(50, account1.Currency), limitErr.Remaining)
+
+	_, err = s.UpdateUserTier(context.Background(), UpdateUserTierParams{Username: account1.Owner, Tier: TierPremium})
+	require.NoError(t, err)
+
+	_, err = s.UpsertTierLimit(context.Background(), UpsertTierLimitParams{
+		Tier:         TierPremium,
+		Currency:     account1.Currency,
+		MonthlyLimit: sql.NullInt64{Int64: 120, Valid: true},
+	})
+	require.NoError(t, err)
+
+	// the tier limit applies to every premium user, so it mustn't outlive the test
+	defer func() {
+		err := s.DeleteTierLimit(context.Background(), DeleteTierLimitParams{
+			Tier:     TierPremium,
+			Currency: account1.Currency,
+		})
+		require.NoError(t, err)
+	}()
+
+	err = transfer(30)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitScopeUser, limitErr.Scope)
+	require.Equal(t, LimitMonthly, limitErr.Limit)
+	require.Equal(t, money.New(120, account1.Currency), limitErr.Max)
+	require.Equal(t, money.New(20, account1.Currency), limitErr.Remaining)
+
+	require.NoError(t, transfer(20))
+
+	updatedAccount, err := s.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-120, updatedAccount.Balance)
+}
+
+func TestTransferTxIdempotencyKey(t *testing.T) {
+	s := NewStore(testDB)
+
+	account1 := createTestAccount(t)
+	account2 := createTestAccountWithCurrency(t, account1.Currency)
+
+	arg := CreateTransferParams{
+		FromAccountID:  account1.ID,
+		ToAccountID:    account2.ID,
+		Amount:         10,
+		IdempotencyKey: sql.NullString{String: fmt.Sprintf("test:%d", account1.ID), Valid: true},
+	}
+
+	result, err := s.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.IdempotencyKey, result.Transfer.IdempotencyKey)
+
+	_, err = s.TransferTx(context.Background(), arg)
+	require.Error(t, err)
+
+	transfer, err := s.GetTransferByIdempotencyKey(context.Background(), arg.IdempotencyKey)
+	require.NoError(t, err)
+	require.Equal(t, result.Transfer.ID, transfer.ID)
+
+	entries, err := s.ListTransferEntries(context.Background(), sql.NullInt64{Int64: transfer.ID, Valid: true})
+	require.NoError(t, err)
+	require.Equal(t, []Entry{result.FromEntry, result.ToEntry}, entries)
+
+	updatedAccount, err := s.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-10, updatedAccount.Balance)
+}
+
+func TestCaptureHoldTx(t *testing.T) {
+	s := NewStore(testDB)
+
+	account1 := createTestAccountWithCurrency(t, "USD")
+	account2 := createTestAccountWithCurrency(t, account1.Currency)
+
+	_, err := s.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      money.New(account1.Balance+1, account1.Currency),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	held, err := s.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      money.New(100, account1.Currency),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, HoldActive, held.Hold.Status)
+	require.Equal(t, account1.Balance, held.Account.Balance)
+	require.Equal(t, account1.Balance-100, held.Account.AvailableBalance)
+
+	// held funds can't be spent elsewhere
+	_, err = s.TransferTx(context.Background(), CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance - 99,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = s.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: held.Hold.ID,
+		Amount: money.New(101, account1.Currency),
+	})
+	require.ErrorIs(t, err, ErrHoldExceeded)
+
+	captured, err := s.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: held.Hold.ID,
+		Amount: money.New(60, account1.Currency),
+	})
+	require.NoError(t, err)
+	require.Equal(t, HoldCaptured, captured.Hold.Status)
+	require.Equal(t, captured.Transfer.Transfer.ID, captured.Hold.TransferID.Int64)
+	require.Equal(t, int64(60), captured.Transfer.Transfer.Amount)
+
+	// capturing less than was held frees the rest
+	require.Equal(t, account1.Balance-60, captured.Transfer.FromAccount.Balance)
+	require.Equal(t, account1.Balance-60, captured.Transfer.FromAccount.AvailableBalance)
+	require.Equal(t, account2.Balance+60, captured.Transfer.ToAccount.AvailableBalance)
+
+	_, err = s.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: held.Hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestVoidAndExpireHoldTx(t *testing.T) {
+	s := NewStore(testDB)
+
+	account1 := createTestAccountWithCurrency(t, "USD")
+	account2 := createTestAccountWithCurrency(t, account1.Currency)
+
+	createHold := func(expiresAt time.Time) Hold {
+		result, err := s.CreateHoldTx(context.Background(), CreateHoldTxParams{
+			AccountID:   account1.ID,
+			ToAccountID: account2.ID,
+			Amount:      money.New(10, account1.Currency),
+			ExpiresAt:   expiresAt,
+		})
+		require.NoError(t, err)
+
+		return result.Hold
+	}
+
+	hold := createHold(time.Now().Add(time.Hour))
+
+	voided, err := s.VoidHoldTx(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldVoided, voided.Hold.Status)
+	require.Equal(t, account1.Balance, voided.Account.AvailableBalance)
+
+	_, err = s.VoidHoldTx(context.Background(), hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotActive)
+
+	expiring := createHold(time.Now().Add(-time.Second))
+
+	_, err = s.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: expiring.ID})
+	require.ErrorIs(t, err, ErrHoldExpired)
+
+	// other tests may have left expired holds behind
+	for {
+		result, err := s.ExpireHoldTx(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, HoldExpired, result.Hold.Status)
+
+		if result.Hold.ID == expiring.ID {
+			require.Equal(t, account1.Balance, result.Account.AvailableBalance)
+			break
+		}
+	}
+}
+
+func TestPostInterestTx(t *testing.T) {
+	s := NewStore(testDB)
+
+	account := createTestAccountWithProduct(t, "USD", ProductSavings)
+
+	// days far enough ahead that no entries land after them
+	period := time.Date(time.Now().Year()+2, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, day := range []time.Time{period.AddDate(0, 0, 29), period.AddDate(0, 0, 30), period.AddDate(0, 1, 0)} {
+		_, err := testQueries.AccrueInterest(context.Background(), AccrueInterestParams{
+			AccrualDate: day,
+			DayEnd:      day.AddDate(0, 0, 1),
+		})
+		require.NoError(t, err)
+	}
+
+	daily := account.Balance * 200
+
+	result, err := s.PostInterestTx(context.Background(), PostInterestTxParams{AccountID: account.ID, Period: period})
+	require.NoError(t, err)
+
+	january := 2 * daily / (bpsScale * 365)
+	require.Equal(t, january, result.Posting.Amount)
+	require.Equal(t, account.Balance+january, result.Account.Balance)
+	require.Equal(t, result.Transfer.ID, result.Posting.TransferID.Int64)
+	require.Equal(t, account.ID, result.Transfer.ToAccountID)
+	require.Equal(t, ProductInterestExpense, result.InterestAccount.Product)
+	require.Equal(t, SystemAccountOwner, result.InterestAccount.Owner)
+
+	_, err = s.PostInterestTx(context.Background(), PostInterestTxParams{AccountID: account.ID, Period: period})
+	require.ErrorIs(t, err, ErrInterestPosted)
+
+	// the fraction left over in January is paid with February
+	result, err = s.PostInterestTx(context.Background(), PostInterestTxParams{AccountID: account.ID, Period: period.AddDate(0, 1, 0)})
+	require.NoError(t, err)
+	require.Equal(t, 3*daily/(bpsScale*365)-january, result.Posting.Amount)
+
+	postings, err := testQueries.ListInterestPostings(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, postings, 2)
+
+	var posted []InterestPostedEvent
+
+	publish := func(ctx context.Context, event Outbox) error {
+		if event.Key == AccountKey(account.ID) && event.EventType == EventInterestPosted {
+			var payload InterestPostedEvent
+			require.NoError(t, json.Unmarshal(event.Payload, &payload))
+			posted = append(posted, payload)
+		}
+		return nil
+	}
+
+	for len(posted) < 2 {
+		published, err := s.RelayOutboxTx(context.Background(), RelayOutboxTxParams{Limit: 100, Publish: publish})
+		require.NoError(t, err)
+		require.NotZero(t, published)
+	}
+
+	require.Equal(t, result.Transfer.ID, posted[1].Transfer.ID)
+	require.Equal(t, result.Posting.Amount, posted[1].Posting.Amount)
+
+	// the recipient can't send posted interest back
+	_, err = s.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: result.Transfer.ID})
+	require.ErrorIs(t, err, ErrNotReversible)
+}
+
+func TestTransferTxFees(t *testing.T) {
+	s := NewStore(testDB)
+
+	fromAccount := createTestAccountWithProduct(t, "USD", ProductSavings)
+	toAccount := createTestAccountWithCurrency(t, "USD")
+
+	rule := UpsertFeeRuleParams{
+		Currency:     "USD",
+		Product:      ProductSavings,
+		TransferType: TransferExternal,
+		FlatAmount:   25,
+		PercentBps:   100,
+		PercentMin:   10,
+		PercentMax:   sql.NullInt64{Int64: 500, Valid: true},
+	}
+
+	_, err := testQueries.UpsertFeeRule(context.Background(), rule)
+	require.NoError(t, err)
+
+	defer func() {
+		_, err := testQueries.UpsertFeeRule(context.Background(), UpsertFeeRuleParams{
+			Currency:     rule.Currency,
+			Product:      rule.Product,
+			TransferType: rule.TransferType,
+		})
+		require.NoError(t, err)
+	}()
+
+	// the fees can't be paid out of what's left
+	_, err = s.TransferTx(context.Background(), CreateTransferParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        fromAccount.Balance - 30,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	result, err := s.TransferTx(context.Background(), CreateTransferParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance-135, result.FromAccount.Balance)
+	require.Equal(t, toAccount.Balance+100, result.ToAccount.Balance)
+	require.Equal(t, int64(-100), result.FromEntry.Amount)
+
+	require.Len(t, result.Fees, 2)
+	require.Equal(t, FeeFlat, result.Fees[0].Kind)
+	require.Equal(t, int64(25), result.Fees[0].Amount)
+	require.Equal(t, FeePercent, result.Fees[1].Kind)
+	require.Equal(t, int64(10), result.Fees[1].Amount)
+
+	feeAccount, err := testQueries.GetSystemAccount(context.Background(), GetSystemAccountParams{
+		Currency: "USD",
+		Product:  ProductFeeIncome,
+	})
+	require.NoError(t, err)
+
+	for _, fee := range result.Fees {
+		debit, err := testQueries.GetEntry(context.Background(), fee.DebitEntryID)
+		require.NoError(t, err)
+		require.Equal(t, fromAccount.ID, debit.AccountID)
+		require.Equal(t, -fee.Amount, debit.Amount)
+
+		credit, err := testQueries.GetEntry(context.Background(), fee.CreditEntryID)
+		require.NoError(t, err)
+		require.Equal(t, feeAccount.ID, credit.AccountID)
+		require.Equal(t, fee.Amount, credit.Amount)
+		require.Equal(t, result.Transfer.ID, credit.TransferID.Int64)
+	}
+
+	fees, err := testQueries.ListTransferFees(context.Background(), result.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Fees, fees)
+
+	mismatches, err := s.ListTransferEntryMismatches(context.Background())
+	require.NoError(t, err)
+
+	for _, mismatch := range mismatches {
+		require.NotEqual(t, result.Transfer.ID, mismatch.TransferID)
+	}
+
+	// reversals don't charge fees or refund them
+	reversal, err := s.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: result.Transfer.ID})
+	require.NoError(t, err)
+	require.Empty(t, reversal.Fees)
+	require.Equal(t, fromAccount.Balance-35, reversal.ToAccount.Balance)
+}
+
+func TestOutboxEvents(t *testing.T) {
+	s := NewStore(testDB)
+
+	user, err := s.CreateUserTx(context.Background(), CreateUserParams{
+		Username:       gofakeit.Username(),
+		HashedPassword: gofakeit.Password(true, true, true, true, true, 15),
+		FullName:       gofakeit.Name(),
+		Email:          gofakeit.Email(),
+	})
+	require.NoError(t, err)
+
+	account, err := s.CreateAccountTx(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  1000,
+		Currency: "USD",
+		Product:  ProductChecking,
+	})
+	require.NoError(t, err)
+
+	toAccount := createTestAccountWithCurrency(t, "USD")
+
+	result, err := s.TransferTx(context.Background(), CreateTransferParams{
+		FromAccountID: account.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// other tests leave events behind, so relay until ours are out
+	var ours []Outbox
+
+	publish := func(ctx context.Context, event Outbox) error {
+		if event.Key == UserKey(user.Username) || event.Key == AccountKey(account.ID) {
+			ours = append(ours, event)
+		}
+		return nil
+	}
+
+	for len(ours) < 3 {
+		published, err := s.RelayOutboxTx(context.Background(), RelayOutboxTxParams{Limit: 100, Publish: publish})
+		require.NoError(t, err)
+		require.NotZero(t, published)
+	}
+
+	require.Len(t, ours, 3)
+	require.Equal(t, EventUserRegistered, ours[0].EventType)
+	require.NotContains(t, string(ours[0].Payload), user.HashedPassword)
+	require.Equal(t, EventAccountCreated, ours[1].EventType)
+	require.Equal(t, EventTransferCompleted, ours[2].EventType)
+
+	var completed TransferCompletedEvent
+	require.NoError(t, json.Unmarshal(ours[2].Payload, &completed))
+	require.Equal(t, result.Transfer.ID, completed.Transfer.ID)
+
+	// a failed publish leaves the event for the next relay
+	event := createTestOutboxEvent(t)
+
+	for {
+		published, err := s.RelayOutboxTx(context.Background(), RelayOutboxTxParams{
+			Limit: 100,
+			Publish: func(ctx context.Context, e Outbox) error {
+				if e.ID == event.ID {
+					return sql.ErrConnDone
+				}
+				return nil
+			},
+		})
+
+		if err != nil {
+			require.ErrorIs(t, err, sql.ErrConnDone)
+			break
+		}
+
+		require.NotZero(t, published)
+	}
+
+	pending, err := s.ListUnpublishedOutboxEvents(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, event.ID, pending[0].ID)
+}
+
+func TestOutboxEventsRecipientOrder(t *testing.T) {
+	s := NewStore(testDB)
+
+	fromAccount := createTestAccountWithCurrency(t, "USD")
+
+	toAccount, err := s.CreateAccountTx(context.Background(), CreateAccountParams{
+		Owner:    createTestUser(t).Username,
+		Currency: "USD",
+		Product:  ProductChecking,
+	})
+	require.NoError(t, err)
+
+	result, err := s.TransferTx(context.Background(), CreateTransferParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	_, err = s.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: toAccount.ID,
+		Status:    AccountFrozen,
+		ChangedBy: toAccount.Owner,
+		Reason:    "lost card",
+	})
+	require.NoError(t, err)
+
+	// a consumer reading the recipient's key sees the transfer in between
+	var ours []Outbox
+
+	publish := func(ctx context.Context, event Outbox) error {
+		if event.Key == AccountKey(toAccount.ID) {
+			ours = append(ours, event)
+		}
+		return nil
+	}
+
+	for len(ours) < 3 {
+		published, err := s.RelayOutboxTx(context.Background(), RelayOutboxTxParams{Limit: 100, Publish: publish})
+		require.NoError(t, err)
+		require.NotZero(t, published)
+	}
+
+	require.Len(t, ours, 3)
+	require.Equal(t, EventAccountCreated, ours[0].EventType)
+	require.Equal(t, EventTransferCompleted, ours[1].EventType)
+	require.Equal(t, EventAccountStatus, ours[2].EventType)
+
+	var completed TransferCompletedEvent
+	require.NoError(t, json.Unmarshal(ours[1].Payload, &completed))
+	require.Equal(t, result.Transfer.ID, completed.Transfer.ID)
+}
+
+func TestOutboxEventsPostings(t *testing.T) {
+	s := NewStore(testDB)
+
+	account := createTestAccountWithCurrency(t, "USD")
+
+	deposit, err := s.DepositTx(context.Background(), CashTxParams{AccountID: account.ID, Amount: money.New(100, account.Currency)})
+	require.NoError(t, err)
+
+	_, err = s.WithdrawTx(context.Background(), CashTxParams{AccountID: account.ID, Amount: money.New(40, account.Currency)})
+	require.NoError(t, err)
+
+	_, err = s.AdjustBalanceTx(context.Background(), AdjustBalanceTxParams{AccountID: account.ID, Amount: money.New(-10, account.Currency)})
+	require.NoError(t, err)
+
+	var ours []Outbox
+
+	publish := func(ctx context.Context, event Outbox) error {
+		if event.Key == AccountKey(account.ID) {
+			ours = append(ours, event)
+		}
+		return nil
+	}
+
+	for len(ours) < 3 {
+		published, err := s.RelayOutboxTx(context.Background(), RelayOutboxTxParams{Limit: 100, Publish: publish})
+		require.NoError(t, err)
+		require.NotZero(t, published)
+	}
+
+	require.Len(t, ours, 3)
+	require.Equal(t, EventCashDeposited, ours[0].EventType)
+	require.Equal(t, EventCashWithdrawn, ours[1].EventType)
+	require.Equal(t, EventBalanceAdjusted, ours[2].EventType)
+
+	var posted CashPostedEvent
+	require.NoError(t, json.Unmarshal(ours[0].Payload, &posted))
+	require.Equal(t, deposit.Entry.ID, posted.Entry.ID)
+	require.Equal(t, account.Currency, posted.Currency)
+
+	require.NoError(t, json.Unmarshal(ours[1].Payload, &posted))
+	require.Equal(t, int64(-40), posted.Entry.Amount)
+}
+
+func TestWebhookDeliveriesQueued(t *testing.T) {
+	s := NewStore(testDB)
+
+	user := createTestUser(t)
+	webhook := createTestWebhook(t, user.Username)
+
+	account, err := s.CreateAccountTx(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  1000,
+		Currency: "USD",
+		Product:  ProductChecking,
+	})
+	require.NoError(t, err)
+
+	// the recipient's webhooks get the transfer too
+	toAccount := createTestAccountWithCurrency(t, "USD")
+	toWebhook := createTestWebhook(t, toAccount.Owner, EventTransferCompleted)
+
+	_, err = s.TransferTx(context.Background(), CreateTransferParams{
+		FromAccountID: account.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	eventTypes := func(webhook Webhook) []string {
+		deliveries, err := s.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+			WebhookID: webhook.ID,
+			PageSize:  10,
+		})
+		require.NoError(t, err)
+
+		var types []string
+
+		for _, delivery := range deliveries {
+			event, err := s.GetOutboxEvent(context.Background(), delivery.EventID)
+			require.NoError(t, err)
+
+			types = append(types, event.EventType)
+		}
+
+		return types
+	}
+
+	require.Equal(t, []string{EventTransferCompleted, EventAccountCreated}, eventTypes(webhook))
+	require.Equal(t, []string{EventTransferCompleted}, eventTypes(toWebhook))
+}
@@ -7,22 +7,52 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
-INSERT INTO transfers (from_account_id, to_account_id, amount)
-VALUES ($1, $2, $3)
-RETURNING id, from_account_id, to_account_id, amount, created_at
+INSERT INTO transfers (
+    from_account_id,
+    to_account_id,
+    amount,
+    reversal_of,
+    to_amount,
+    fx_rate,
+    fx_spread_bps,
+    currency,
+    to_currency,
+    idempotency_key
+  )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, from_account_id, to_account_id, amount, created_at, reversal_of, to_amount, fx_rate, fx_spread_bps, currency, to_currency, idempotency_key
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID  int64          `json:"from_account_id"`
+	ToAccountID    int64          `json:"to_account_id"`
+	Amount         int64          `json:"amount"`
+	ReversalOf     sql.NullInt64  `json:"reversal_of"`
+	ToAmount       int64          `json:"to_amount"`
+	FxRate         int64          `json:"fx_rate"`
+	FxSpreadBps    int32          `json:"fx_spread_bps"`
+	Currency       string         `json:"currency"`
+	ToCurrency     string         `json:"to_currency"`
+	IdempotencyKey sql.NullString `json:"idempotency_key"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ReversalOf,
+		arg.ToAmount,
+		arg.FxRate,
+		arg.FxSpreadBps,
+		arg.Currency,
+		arg.ToCurrency,
+		arg.IdempotencyKey,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -30,12 +60,41 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ReversalOf,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.FxSpreadBps,
+		&i.Currency,
+		&i.ToCurrency,
+		&i.IdempotencyKey,
+	)
+	return i, err
+}
+
+const getReversedAmount = `-- name: GetReversedAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS amount,
+  COALESCE(SUM(to_amount), 0)::bigint AS to_amount
+FROM transfers
+WHERE reversal_of = $1
+`
+
+type GetReversedAmountRow struct {
+	Amount   int64 `json:"amount"`
+	ToAmount int64 `json:"to_amount"`
+}
+
+func (q *Queries) GetReversedAmount(ctx context.Context, reversalOf sql.NullInt64) (GetReversedAmountRow, error) {
+	row := q.db.QueryRowContext(ctx, getReversedAmount, reversalOf)
+	var i GetReversedAmountRow
+	err := row.Scan(
+		&i.Amount,
+		&i.ToAmount,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of, to_amount, fx_rate, fx_spread_bps, currency, to_currency, idempotency_key
 FROM transfers
 WHERE id = $1
 LIMIT 1
@@ -50,6 +109,185 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ReversalOf,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.FxSpreadBps,
+		&i.Currency,
+		&i.ToCurrency,
+		&i.IdempotencyKey,
 	)
 	return i, err
 }
+
+const getTransferByIdempotencyKey = `-- name: GetTransferByIdempotencyKey :one
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of, to_amount, fx_rate, fx_spread_bps, currency, to_currency, idempotency_key
+FROM transfers
+WHERE idempotency_key = $1
+LIMIT 1
+`
+
+func (q *Queries) GetTransferByIdempotencyKey(ctx context.Context, idempotencyKey sql.NullString) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferByIdempotencyKey, idempotencyKey)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ReversalOf,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.FxSpreadBps,
+		&i.Currency,
+		&i.ToCurrency,
+		&i.IdempotencyKey,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of, to_amount, fx_rate, fx_spread_bps, currency, to_currency, idempotency_key
+FROM transfers
+WHERE id = $1
+LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ReversalOf,
+		&i.ToAmount,
+		&i.FxRate,
+		&i.FxSpreadBps,
+		&i.Currency,
+		&i.ToCurrency,
+		&i.IdempotencyKey,
+	)
+	return i, err
+}
+
+const listTransfers = `-- name: ListTransfers :many
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of, to_amount, fx_rate, fx_spread_bps, currency, to_currency, idempotency_key
+FROM transfers
+WHERE (
+    from_account_id = $1
+    OR to_account_id = $1
+  )
+  AND (
+    $2::varchar IS NULL
+    OR (
+      $2 = 'outgoing'
+      AND from_account_id = $1
+    )
+    OR (
+      $2 = 'incoming'
+      AND to_account_id = $1
+    )
+  )
+  AND (
+    $3::bigint IS NULL
+    OR (
+      from_account_id = $1
+      AND to_account_id = $3
+    )
+    OR (
+      to_account_id = $1
+      AND from_account_id = $3
+    )
+  )
+  AND (
+    $4::bigint IS NULL
+    OR CASE
+      WHEN from_account_id = $1 THEN amount
+      ELSE to_amount
+    END >= $4
+  )
+  AND (
+    $5::bigint IS NULL
+    OR CASE
+      WHEN from_account_id = $1 THEN amount
+      ELSE to_amount
+    END <= $5
+  )
+  AND (
+    $6::timestamptz IS NULL
+    OR created_at >= $6
+  )
+  AND (
+    $7::timestamptz IS NULL
+    OR created_at < $7
+  )
+  AND (
+    $8::bigint IS NULL
+    OR id < $8
+  )
+ORDER BY id DESC
+LIMIT $9
+`
+
+type ListTransfersParams struct {
+	AccountID      int64          `json:"account_id"`
+	Direction      sql.NullString `json:"direction"`
+	CounterpartyID sql.NullInt64  `json:"counterparty_id"`
+	MinAmount      sql.NullInt64  `json:"min_amount"`
+	MaxAmount      sql.NullInt64  `json:"max_amount"`
+	StartTime      sql.NullTime   `json:"start_time"`
+	EndTime        sql.NullTime   `json:"end_time"`
+	BeforeID       sql.NullInt64  `json:"before_id"`
+	PageSize       int32          `json:"page_size"`
+}
+
+func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listTransfers,
+		arg.AccountID,
+		arg.Direction,
+		arg.CounterpartyID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.StartTime,
+		arg.EndTime,
+		arg.BeforeID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ReversalOf,
+			&i.ToAmount,
+			&i.FxRate,
+			&i.FxSpreadBps,
+			&i.Currency,
+			&i.ToCurrency,
+			&i.IdempotencyKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -17,6 +18,8 @@ func createTestTransfer(t *testing.T) Transfer {
 		FromAccountID: fromAccount.ID,
 		ToAccountID:   toAccount.ID,
 		Amount:        int64(gofakeit.UintRange(10, 100)),
+		Currency:      fromAccount.Currency,
+		ToCurrency:    toAccount.Currency,
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
@@ -54,3 +57,66 @@ func TestGetTransfer(t *testing.T) {
 	require.Equal(t, transfer1.ToAccountID, transfer2.ToAccountID)
 	require.WithinDuration(t, transfer1.CreatedAt, transfer2.CreatedAt, time.Second)
 }
+
+func TestListTransfers(t *testing.T) {
+	account := createTestAccount(t)
+	counterparty := createTestAccount(t)
+
+	for i := range 6 {
+		arg := CreateTransferParams{
+			FromAccountID: account.ID,
+			ToAccountID:   counterparty.ID,
+			Amount:        int64(10 * (i + 1)),
+			Currency:      account.Currency,
+			ToCurrency:    counterparty.Currency,
+		}
+
+		if i%2 == 1 {
+			arg.FromAccountID, arg.ToAccountID = counterparty.ID, account.ID
+		}
+
+		_, err := testQueries.CreateTransfer(context.Background(), arg)
+		require.NoError(t, err)
+	}
+
+	transfers, err := testQueries.ListTransfers(context.Background(), ListTransfersParams{
+		AccountID: account.ID,
+		Direction: sql.NullString{String: "outgoing", Valid: true},
+		MinAmount: sql.NullInt64{Int64: 20, Valid: true},
+		PageSize:  10,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+
+	for i, transfer := range transfers {
+		require.Equal(t, account.ID, transfer.FromAccountID)
+		require.Equal(t, counterparty.ID, transfer.ToAccountID)
+		require.GreaterOrEqual(t, transfer.Amount, int64(20))
+
+		if i > 0 {
+			require.Less(t, transfer.ID, transfers[i-1].ID)
+		}
+	}
+
+	page, err := testQueries.ListTransfers(context.Background(), ListTransfersParams{
+		AccountID:      account.ID,
+		Direction:      sql.NullString{String: "incoming", Valid: true},
+		CounterpartyID: sql.NullInt64{Int64: counterparty.ID, Valid: true},
+		PageSize:       2,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+
+	rest, err := testQueries.ListTransfers(context.Background(), ListTransfersParams{
+		AccountID: account.ID,
+		Direction: sql.NullString{String: "incoming", Valid: true},
+		BeforeID:  sql.NullInt64{Int64: page[1].ID, Valid: true},
+		PageSize:  2,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	require.Equal(t, account.ID, rest[0].ToAccountID)
+}
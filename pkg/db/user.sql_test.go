@@ -27,6 +27,8 @@ func createTestUser(t *testing.T) User {
 	require.Equal(t, user.HashedPassword, arg.HashedPassword)
 	require.Equal(t, user.FullName, arg.FullName)
 	require.Equal(t, user.Email, arg.Email)
+	require.Equal(t, "customer", user.Role)
+	require.Equal(t, TierStandard, user.Tier)
 
 	return user
 }
@@ -50,3 +52,29 @@ func TestGetUser(t *testing.T) {
 	require.Equal(t, user2.FullName, user1.FullName)
 	require.Equal(t, user2.Email, user1.Email)
 }
+
+func TestUpdateUserRole(t *testing.T) {
+	user1 := createTestUser(t)
+
+	user2, err := testQueries.UpdateUserRole(context.Background(), UpdateUserRoleParams{
+		Username: user1.Username,
+		Role:     "teller",
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, user1.Username, user2.Username)
+	require.Equal(t, "teller", user2.Role)
+}
+
+func TestUpdateUserTier(t *testing.T) {
+	user1 := createTestUser(t)
+
+	user2, err := testQueries.UpdateUserTier(context.Background(), UpdateUserTierParams{
+		Username: user1.Username,
+		Tier:     TierPremium,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, user1.Username, user2.Username)
+	require.Equal(t, TierPremium, user2.Tier)
+}
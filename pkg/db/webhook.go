@@ -0,0 +1,21 @@
+package db
+
+// Webhook delivery statuses. A delivery is pending until the endpoint takes
+// it, or dead once it runs out of attempts.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// WebhookEvents are the event types webhooks can be sent. Each is about the
+// accounts of the users it's sent to.
+var WebhookEvents = []string{
+	EventAccountCreated,
+	EventAccountStatus,
+	EventTransferCompleted,
+	EventCashDeposited,
+	EventCashWithdrawn,
+	EventBalanceAdjusted,
+	EventInterestPosted,
+}
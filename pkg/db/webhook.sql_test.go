@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createTestWebhook(t *testing.T, owner string, eventTypes ...string) Webhook {
+	arg := CreateWebhookParams{
+		Owner:      owner,
+		Url:        "https://example.com/hooks",
+		Secret:     "whsec_0123456789abcdef",
+		EventTypes: append([]string{}, eventTypes...),
+	}
+
+	webhook, err := testQueries.CreateWebhook(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, webhook.ID)
+	require.Equal(t, arg.Owner, webhook.Owner)
+	require.Equal(t, arg.Url, webhook.Url)
+	require.Equal(t, arg.Secret, webhook.Secret)
+	require.Equal(t, arg.EventTypes, webhook.EventTypes)
+	require.NotZero(t, webhook.CreatedAt)
+
+	return webhook
+}
+
+func TestListWebhooks(t *testing.T) {
+	user := createTestUser(t)
+
+	webhook1 := createTestWebhook(t, user.Username)
+	webhook2 := createTestWebhook(t, user.Username, EventTransferCompleted)
+	createTestWebhook(t, createTestUser(t).Username)
+
+	webhooks, err := testQueries.ListWebhooks(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, []Webhook{webhook1, webhook2}, webhooks)
+
+	err = testQueries.DeleteWebhook(context.Background(), webhook1.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetWebhook(context.Background(), webhook1.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestCreateWebhookDeliveries(t *testing.T) {
+	user := createTestUser(t)
+
+	all := createTestWebhook(t, user.Username)
+	transfers := createTestWebhook(t, user.Username, EventTransferCompleted)
+	createTestWebhook(t, user.Username, EventAccountCreated)
+
+	event := createTestOutboxEvent(t)
+
+	arg := CreateWebhookDeliveriesParams{
+		EventID:   event.ID,
+		Owners:    []string{user.Username, user.Username},
+		EventType: EventTransferCompleted,
+	}
+
+	queued, err := testQueries.CreateWebhookDeliveries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), queued)
+
+	// an event is queued once per webhook
+	queued, err = testQueries.CreateWebhookDeliveries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, queued)
+
+	for _, webhook := range []Webhook{all, transfers} {
+		deliveries, err := testQueries.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+			WebhookID: webhook.ID,
+			PageSize:  10,
+		})
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		require.Equal(t, event.ID, deliveries[0].EventID)
+		require.Equal(t, DeliveryPending, deliveries[0].Status)
+		require.Zero(t, deliveries[0].Attempts)
+	}
+}
+
+func TestWebhookDeliveryAttempts(t *testing.T) {
+	webhook := createTestWebhook(t, createTestUser(t).Username)
+	event := createTestOutboxEvent(t)
+
+	_, err := testQueries.CreateWebhookDeliveries(context.Background(), CreateWebhookDeliveriesParams{
+		EventID:   event.ID,
+		Owners:    []string{webhook.Owner},
+		EventType: event.EventType,
+	})
+	require.NoError(t, err)
+
+	deliveries, err := testQueries.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+		WebhookID: webhook.ID,
+		PageSize:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+
+	dead, err := testQueries.RecordWebhookDeliveryAttempt(context.Background(), RecordWebhookDeliveryAttemptParams{
+		Status:         DeliveryDead,
+		NextAttemptAt:  time.Now(),
+		LastStatusCode: sql.NullInt32{Int32: 500, Valid: true},
+		ID:             deliveries[0].ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, DeliveryDead, dead.Status)
+	require.Equal(t, int32(1), dead.Attempts)
+	require.Equal(t, int32(500), dead.LastStatusCode.Int32)
+	require.False(t, dead.DeliveredAt.Valid)
+
+	deadLetters, err := testQueries.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+		WebhookID: webhook.ID,
+		Status:    sql.NullString{String: DeliveryDead, Valid: true},
+		PageSize:  10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []WebhookDelivery{dead}, deadLetters)
+
+	replayed, err := testQueries.ReplayWebhookDelivery(context.Background(), dead.ID)
+	require.NoError(t, err)
+	require.Equal(t, DeliveryPending, replayed.Status)
+	require.Zero(t, replayed.Attempts)
+	require.False(t, replayed.LastStatusCode.Valid)
+
+	// pending deliveries can't be replayed
+	_, err = testQueries.ReplayWebhookDelivery(context.Background(), dead.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	delivered, err := testQueries.RecordWebhookDeliveryAttempt(context.Background(), RecordWebhookDeliveryAttemptParams{
+		Status:         DeliveryDelivered,
+		NextAttemptAt:  replayed.NextAttemptAt,
+		LastStatusCode: sql.NullInt32{Int32: 204, Valid: true},
+		ID:             dead.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, DeliveryDelivered, delivered.Status)
+	require.True(t, delivered.DeliveredAt.Valid)
+}
@@ -0,0 +1,98 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// KafkaPublisher publishes events to a Kafka topic through a Kafka REST proxy
+// (the Confluent REST API v2). Events are keyed by their key, so the events of
+// an account land on one partition and are consumed in order.
+type KafkaPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewKafkaPublisher returns a publisher for topic behind the REST proxy at
+// baseURL, like http://localhost:8082.
+func NewKafkaPublisher(baseURL string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{
+		url:    strings.TrimSuffix(baseURL, "/") + "/topics/" + topic,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type kafkaRecord struct {
+	Key   string `json:"key"`
+	Value Event  `json:"value"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition int32   `json:"partition"`
+		Offset    int64   `json:"offset"`
+		ErrorCode *int    `json:"error_code"`
+		Error     *string `json:"error"`
+	} `json:"offsets"`
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(map[string][]kafkaRecord{
+		"records": {{Key: event.Key, Value: event}},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	request.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	response, err := p.client.Do(request)
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("kafka: produce to %s: %s", p.url, response.Status)
+	}
+
+	var produced kafkaProduceResponse
+
+	if err := json.NewDecoder(response.Body).Decode(&produced); err != nil {
+		return fmt.Errorf("kafka: %w", err)
+	}
+
+	// the proxy reports records it couldn't produce with a 200
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil {
+			message := ""
+
+			if offset.Error != nil {
+				message = *offset.Error
+			}
+
+			return fmt.Errorf("kafka: produce to %s: error %d: %s", p.url, *offset.ErrorCode, message)
+		}
+	}
+
+	return nil
+}
+
+func (p *KafkaPublisher) Close() error {
+	return nil
+}
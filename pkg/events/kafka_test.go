@@ -0,0 +1,72 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaPublisher(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   int
+		response string
+		check    func(t *testing.T, err error)
+	}{
+		{
+			name:     "Ok",
+			status:   http.StatusOK,
+			response: `{"offsets":[{"partition":2,"offset":41}]}`,
+			check: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:     "RecordError",
+			status:   http.StatusOK,
+			response: `{"offsets":[{"partition":null,"offset":null,"error_code":50003,"error":"timed out"}]}`,
+			check: func(t *testing.T, err error) {
+				require.ErrorContains(t, err, "timed out")
+			},
+		},
+		{
+			name:     "TopicNotFound",
+			status:   http.StatusNotFound,
+			response: `{"error_code":40401,"message":"Topic not found."}`,
+			check: func(t *testing.T, err error) {
+				require.ErrorContains(t, err, "404")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "/topics/simplebank", r.URL.Path)
+				require.Equal(t, "application/vnd.kafka.json.v2+json", r.Header.Get("Content-Type"))
+
+				var body struct {
+					Records []kafkaRecord `json:"records"`
+				}
+
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				require.Len(t, body.Records, 1)
+				require.Equal(t, "account:3", body.Records[0].Key)
+				require.Equal(t, int64(3), body.Records[0].Value.ID)
+
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.response))
+			}))
+
+			defer server.Close()
+
+			publisher := NewKafkaPublisher(server.URL, "simplebank")
+
+			tc.check(t, publisher.Publish(context.Background(), newTestEvent(3)))
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsTimeout bounds connecting to the server and waiting for it to confirm a
+// publish.
+const natsTimeout = 10 * time.Second
+
+// NATSPublisher publishes events to a NATS server over its text protocol, on
+// the subject prefix.type, e.g. simplebank.transfer.completed. Each event
+// carries its id as Nats-Msg-Id, which JetStream uses to drop events that are
+// published twice. The connection is opened on first use and again after any
+// error.
+type NATSPublisher struct {
+	mu            sync.Mutex
+	addr          string
+	subjectPrefix string
+	conn          net.Conn
+	reader        *bufio.Reader
+}
+
+// NewNATSPublisher returns a publisher for the server at rawURL, like
+// nats://localhost:4222.
+func NewNATSPublisher(rawURL string, subjectPrefix string) (*NATSPublisher, error) {
+	u, err := url.Parse(rawURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "nats" || u.Host == "" {
+		return nil, fmt.Errorf("invalid nats url %q", rawURL)
+	}
+
+	return &NATSPublisher{addr: u.Host, subjectPrefix: subjectPrefix}, nil
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, event Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	data, err := json.Marshal(event)
+
+	if err != nil {
+		return err
+	}
+
+	if err := p.publish(ctx, event, data); err != nil {
+		p.close()
+		return err
+	}
+
+	return nil
+}
+
+func (p *NATSPublisher) publish(ctx context.Context, event Event, data []byte) error {
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	deadline := time.Now().Add(natsTimeout)
+
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	if err := p.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	subject := event.Type
+
+	if p.subjectPrefix != "" {
+		subject = p.subjectPrefix + "." + event.Type
+	}
+
+	headers := fmt.Sprintf("NATS/1.0\r\nNats-Msg-Id: %d\r\n\r\n", event.ID)
+
+	// the PING is answered once the server has taken the message
+	_, err := fmt.Fprintf(p.conn, "HPUB %s %d %d\r\n%s%s\r\nPING\r\n", subject, len(headers), len(headers)+len(data), headers, data)
+
+	if err != nil {
+		return err
+	}
+
+	return p.awaitPong()
+}
+
+// connect dials the server and goes through the handshake.
+func (p *NATSPublisher) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: natsTimeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", p.addr)
+
+	if err != nil {
+		return err
+	}
+
+	p.conn = conn
+	p.reader = bufio.NewReader(conn)
+
+	if err := conn.SetDeadline(time.Now().Add(natsTimeout)); err != nil {
+		return err
+	}
+
+	line, err := p.readLine()
+
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("nats: unexpected greeting %q", line)
+	}
+
+	var info struct {
+		Headers bool `json:"headers"`
+	}
+
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "INFO ")), &info); err != nil {
+		return fmt.Errorf("nats: %w", err)
+	}
+
+	if !info.Headers {
+		return errors.New("nats: server doesn't support headers")
+	}
+
+	if _, err := fmt.Fprint(conn, "CONNECT {\"verbose\":false,\"pedantic\":false,\"headers\":true,\"name\":\"simplebank\"}\r\nPING\r\n"); err != nil {
+		return err
+	}
+
+	return p.awaitPong()
+}
+
+// awaitPong reads until the server answers a PING, replying to its own pings
+// on the way.
+func (p *NATSPublisher) awaitPong() error {
+	for {
+		line, err := p.readLine()
+
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := fmt.Fprint(p.conn, "PONG\r\n"); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (p *NATSPublisher) readLine() (string, error) {
+	line, err := p.reader.ReadString('\n')
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (p *NATSPublisher) close() error {
+	if p.conn == nil {
+		return nil
+	}
+
+	err := p.conn.Close()
+	p.conn = nil
+	p.reader = nil
+
+	return err
+}
+
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.close()
+}
@@ -0,0 +1,117 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// natsMessage is a message a fake server received.
+type natsMessage struct {
+	subject string
+	headers string
+	payload string
+}
+
+// startNATSServer runs a fake NATS server for one connection. It answers the
+// publish numbered failAt, if any, with an error.
+func startNATSServer(t *testing.T, failAt int) (string, <-chan natsMessage) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan natsMessage, 10)
+
+	go func() {
+		defer close(messages)
+
+		conn, err := listener.Accept()
+
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\",\"headers\":true}\r\n")
+
+		published := 0
+
+		for {
+			line, err := reader.ReadString('\n')
+
+			if err != nil {
+				return
+			}
+
+			fields := strings.Fields(line)
+
+			switch fields[0] {
+			case "PING":
+				fmt.Fprint(conn, "PONG\r\n")
+			case "HPUB":
+				headerSize, _ := strconv.Atoi(fields[2])
+				totalSize, _ := strconv.Atoi(fields[3])
+
+				data := make([]byte, totalSize+2)
+
+				if _, err := io.ReadFull(reader, data); err != nil {
+					return
+				}
+
+				published++
+
+				if published == failAt {
+					fmt.Fprint(conn, "-ERR 'Maximum Payload Violation'\r\n")
+					return
+				}
+
+				messages <- natsMessage{
+					subject: fields[1],
+					headers: string(data[:headerSize]),
+					payload: string(data[headerSize:totalSize]),
+				}
+			}
+		}
+	}()
+
+	return "nats://" + listener.Addr().String(), messages
+}
+
+func TestNATSPublisher(t *testing.T) {
+	url, messages := startNATSServer(t, 0)
+
+	publisher, err := NewNATSPublisher(url, "simplebank")
+	require.NoError(t, err)
+
+	defer publisher.Close()
+
+	require.NoError(t, publisher.Publish(context.Background(), newTestEvent(7)))
+
+	message := <-messages
+	require.Equal(t, "simplebank.account.created", message.subject)
+	require.Contains(t, message.headers, "Nats-Msg-Id: 7\r\n")
+	require.Contains(t, message.payload, `"key":"account:7"`)
+}
+
+func TestNATSPublisherError(t *testing.T) {
+	url, _ := startNATSServer(t, 1)
+
+	publisher, err := NewNATSPublisher(url, "simplebank")
+	require.NoError(t, err)
+
+	err = publisher.Publish(context.Background(), newTestEvent(1))
+	require.ErrorContains(t, err, "Maximum Payload Violation")
+
+	// the broken connection is dropped
+	require.Nil(t, publisher.conn)
+}
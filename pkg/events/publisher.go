@@ -0,0 +1,70 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+)
+
+// Event is a domain event as it's published. ID is unique and increasing, so
+// consumers can drop events they've already seen, and Key is what events are
+// ordered by.
+type Event struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	Key       string          `json:"key"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// FromOutbox returns the event of an outbox row.
+func FromOutbox(row db.Outbox) Event {
+	return Event{
+		ID:        row.ID,
+		Type:      row.EventType,
+		Key:       row.Key,
+		Payload:   row.Payload,
+		CreatedAt: row.CreatedAt,
+	}
+}
+
+// Publisher sends events on to where they're consumed. Publish returns once
+// the event has been handed off, and is called with one event at a time in
+// the order events are to be delivered.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+	Close() error
+}
+
+// Publisher kinds NewPublisher accepts.
+const (
+	PublisherMemory = "memory"
+	PublisherStdout = "stdout"
+	PublisherFile   = "file"
+	PublisherNATS   = "nats"
+	PublisherKafka  = "kafka"
+)
+
+// NewPublisher returns a publisher of kind. target is the path of the file
+// publisher, the server url of the NATS publisher and the REST proxy url of
+// the Kafka publisher. topic is the NATS subject prefix or the Kafka topic.
+func NewPublisher(kind string, target string, topic string) (Publisher, error) {
+	switch kind {
+	case PublisherMemory:
+		return NewMemoryPublisher(), nil
+	case PublisherStdout:
+		return NewWriterPublisher(os.Stdout), nil
+	case PublisherFile:
+		return NewFilePublisher(target)
+	case PublisherNATS:
+		return NewNATSPublisher(target, topic)
+	case PublisherKafka:
+		return NewKafkaPublisher(target, topic), nil
+	}
+
+	return nil, fmt.Errorf("unknown event publisher %q", kind)
+}
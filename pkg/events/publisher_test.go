@@ -0,0 +1,83 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEvent(id int64) Event {
+	return Event{
+		ID:        id,
+		Type:      db.EventAccountCreated,
+		Key:       db.AccountKey(id),
+		Payload:   json.RawMessage(`{"id":1}`),
+		CreatedAt: time.Date(2024, 7, 29, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestMemoryPublisher(t *testing.T) {
+	publisher := NewMemoryPublisher()
+
+	for id := int64(1); id <= 3; id++ {
+		require.NoError(t, publisher.Publish(context.Background(), newTestEvent(id)))
+	}
+
+	events := publisher.Events()
+	require.Len(t, events, 3)
+	require.Equal(t, int64(1), events[0].ID)
+	require.Equal(t, int64(3), events[2].ID)
+}
+
+func TestWriterPublisher(t *testing.T) {
+	var buf bytes.Buffer
+
+	publisher := NewWriterPublisher(&buf)
+	require.NoError(t, publisher.Publish(context.Background(), newTestEvent(1)))
+	require.NoError(t, publisher.Publish(context.Background(), newTestEvent(2)))
+	require.NoError(t, publisher.Close())
+
+	scanner := bufio.NewScanner(&buf)
+
+	for id := int64(1); scanner.Scan(); id++ {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		require.Equal(t, newTestEvent(id), event)
+	}
+}
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	for id := int64(1); id <= 2; id++ {
+		publisher, err := NewPublisher(PublisherFile, path, "")
+		require.NoError(t, err)
+		require.NoError(t, publisher.Publish(context.Background(), newTestEvent(id)))
+		require.NoError(t, publisher.Close())
+	}
+
+	// reopening the file appends to it
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Len(t, bytes.Split(bytes.TrimSpace(data), []byte("\n")), 2)
+}
+
+func TestNewPublisher(t *testing.T) {
+	_, err := NewPublisher("carrier-pigeon", "", "")
+	require.Error(t, err)
+
+	_, err = NewPublisher(PublisherNATS, "http://localhost:4222", "simplebank")
+	require.Error(t, err)
+
+	publisher, err := NewPublisher(PublisherKafka, "http://localhost:8082/", "simplebank")
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:8082/topics/simplebank", publisher.(*KafkaPublisher).url)
+}
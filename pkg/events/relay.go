@@ -0,0 +1,71 @@
+package events
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+)
+
+// relayBatchSize is the most events published in one transaction.
+const relayBatchSize = 100
+
+// Relay publishes the events written to the outbox. Relays can run in every
+// server replica: they take turns through a database lock, and the one that
+// has it publishes events in the order they were written.
+type Relay struct {
+	store     db.Store
+	publisher Publisher
+	interval  time.Duration
+}
+
+func NewRelay(store db.Store, publisher Publisher, interval time.Duration) *Relay {
+	return &Relay{store: store, publisher: publisher, interval: interval}
+}
+
+// Start publishes pending events every interval until ctx is done, and then
+// closes the publisher.
+func (r *Relay) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	defer r.publisher.Close()
+
+	for {
+		if _, err := r.RelayPending(ctx); err != nil {
+			log.Println("unable to relay events: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending publishes events until none are left, or another relay has
+// the outbox, and returns how many were published. An event that fails to
+// publish is left for the next run, and the events after it wait so they
+// aren't delivered ahead of it.
+func (r *Relay) RelayPending(ctx context.Context) (int, error) {
+	relayed := 0
+
+	for ctx.Err() == nil {
+		published, err := r.store.RelayOutboxTx(ctx, db.RelayOutboxTxParams{
+			Limit: relayBatchSize,
+			Publish: func(ctx context.Context, row db.Outbox) error {
+				return r.publisher.Publish(ctx, FromOutbox(row))
+			},
+		})
+
+		relayed += published
+
+		if err != nil || published < relayBatchSize {
+			return relayed, err
+		}
+	}
+
+	return relayed, ctx.Err()
+}
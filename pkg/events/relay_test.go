@@ -0,0 +1,127 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// relayRows stubs RelayOutboxTx to hand rows to the publisher the way the
+// store does, stopping at the first error.
+func relayRows(rows []db.Outbox) func(ctx context.Context, arg db.RelayOutboxTxParams) (int, error) {
+	return func(ctx context.Context, arg db.RelayOutboxTxParams) (int, error) {
+		for i, row := range rows {
+			if err := arg.Publish(ctx, row); err != nil {
+				return i, err
+			}
+		}
+
+		return len(rows), nil
+	}
+}
+
+// failingPublisher fails to publish the event with id failID.
+type failingPublisher struct {
+	*MemoryPublisher
+	failID int64
+}
+
+func (p failingPublisher) Publish(ctx context.Context, event Event) error {
+	if event.ID == p.failID {
+		return errors.New("broker unavailable")
+	}
+
+	return p.MemoryPublisher.Publish(ctx, event)
+}
+
+func TestRelayPending(t *testing.T) {
+	rows := func(from, to int64) []db.Outbox {
+		var rows []db.Outbox
+
+		for id := from; id <= to; id++ {
+			rows = append(rows, db.Outbox{ID: id, EventType: db.EventTransferCompleted, Key: db.AccountKey(id % 3)})
+		}
+
+		return rows
+	}
+
+	testCases := []struct {
+		name          string
+		failID        int64
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, relayed int, err error, events []Event)
+	}{
+		{
+			name: "Empty",
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(1).Return(0, nil)
+			},
+			checkResponse: func(t *testing.T, relayed int, err error, events []Event) {
+				require.NoError(t, err)
+				require.Zero(t, relayed)
+				require.Empty(t, events)
+			},
+		},
+		{
+			name: "Batches",
+			buildStub: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(relayRows(rows(1, relayBatchSize))),
+					store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(relayRows(rows(relayBatchSize+1, relayBatchSize+5))),
+				)
+			},
+			checkResponse: func(t *testing.T, relayed int, err error, events []Event) {
+				require.NoError(t, err)
+				require.Equal(t, relayBatchSize+5, relayed)
+				require.Len(t, events, relayed)
+
+				for i, event := range events {
+					require.Equal(t, int64(i+1), event.ID)
+				}
+			},
+		},
+		{
+			name:   "PublishError",
+			failID: 3,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(relayRows(rows(1, 5)))
+			},
+			checkResponse: func(t *testing.T, relayed int, err error, events []Event) {
+				require.ErrorContains(t, err, "broker unavailable")
+				require.Equal(t, 2, relayed)
+				require.Len(t, events, 2)
+			},
+		},
+		{
+			name: "StoreError",
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(1).Return(0, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, relayed int, err error, events []Event) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			publisher := failingPublisher{NewMemoryPublisher(), tc.failID}
+
+			relayed, err := NewRelay(store, publisher, 0).RelayPending(context.Background())
+
+			tc.checkResponse(t, relayed, err, publisher.Events())
+		})
+	}
+}
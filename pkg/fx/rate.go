@@ -0,0 +1,184 @@
+package fx
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/aseerkt/go-simple-bank/pkg/currency"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+)
+
+var (
+	ErrRateNotFound   = errors.New("fx rate not found")
+	ErrInvalidRate    = errors.New("invalid fx rate")
+	ErrAmountTooSmall = errors.New("amount too small to convert")
+	ErrAmountTooLarge = errors.New("amount too large to convert")
+)
+
+// RateProvider looks up the mid-market rate of one unit of base in quote,
+// scaled by db.FxRateScale.
+type RateProvider interface {
+	GetRate(ctx context.Context, base, quote string) (int64, error)
+}
+
+// StoreProvider reads rates from the rates table.
+type StoreProvider struct {
+	q db.Querier
+}
+
+func NewStoreProvider(q db.Querier) *StoreProvider {
+	return &StoreProvider{q: q}
+}
+
+func (p *StoreProvider) GetRate(ctx context.Context, base, quote string) (int64, error) {
+	rate, err := p.q.GetRate(ctx, db.GetRateParams{BaseCurrency: base, QuoteCurrency: quote})
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%w: %s to %s", ErrRateNotFound, base, quote)
+		}
+		return 0, err
+	}
+
+	return rate.Rate, nil
+}
+
+// StaticProvider serves a fixed set of rates keyed by "BASE/QUOTE".
+type StaticProvider map[string]int64
+
+func (p StaticProvider) GetRate(ctx context.Context, base, quote string) (int64, error) {
+	rate, ok := p[base+"/"+quote]
+
+	if !ok {
+		return 0, fmt.Errorf("%w: %s to %s", ErrRateNotFound, base, quote)
+	}
+
+	return rate, nil
+}
+
+// LoadFileProvider reads a JSON object of decimal rates such as
+// {"USD/EUR": "0.92"}.
+func LoadFileProvider(path string) (StaticProvider, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var rates map[string]string
+
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("invalid rates file %s: %w", path, err)
+	}
+
+	provider := make(StaticProvider, len(rates))
+
+	for pair, value := range rates {
+		rate, err := ParseRate(value)
+
+		if err != nil {
+			return nil, fmt.Errorf("rate %s: %w", pair, err)
+		}
+
+		provider[pair] = rate
+	}
+
+	return provider, nil
+}
+
+// ParseRate turns a positive decimal rate into its scaled form. Rates with
+// more precision than db.FxRateScale allows are rejected.
+func ParseRate(value string) (int64, error) {
+	rat, ok := new(big.Rat).SetString(value)
+
+	if !ok || rat.Sign() <= 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidRate, value)
+	}
+
+	scaled := rat.Mul(rat, new(big.Rat).SetInt64(db.FxRateScale))
+
+	if !scaled.IsInt() || !scaled.Num().IsInt64() {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidRate, value)
+	}
+
+	return scaled.Num().Int64(), nil
+}
+
+// FormatRate is the inverse of ParseRate.
+func FormatRate(rate int64) string {
+	return new(big.Rat).SetFrac64(rate, db.FxRateScale).FloatString(8)
+}
+
+// Quote is the result of converting an amount at a rate with the spread taken
+// off.
+type Quote struct {
+	Rate      int64
+	SpreadBps int32
+	ToAmount  int64
+}
+
+// Convert prices amount in the quote currency at midRate less spreadBps basis
+// points. Amounts are in minor units, so fromUnits and toUnits are the minor
+// units of the two currencies. The converted amount is rounded down.
+func Convert(amount int64, midRate int64, spreadBps int32, fromUnits int32, toUnits int32) (Quote, error) {
+	rate := new(big.Int).Mul(big.NewInt(midRate), big.NewInt(10_000-int64(spreadBps)))
+	rate.Quo(rate, big.NewInt(10_000))
+
+	toAmount := new(big.Int).Mul(big.NewInt(amount), rate)
+	toAmount.Mul(toAmount, pow10(toUnits))
+	toAmount.Quo(toAmount, new(big.Int).Mul(big.NewInt(db.FxRateScale), pow10(fromUnits)))
+
+	if !toAmount.IsInt64() {
+		return Quote{}, fmt.Errorf("%w: %d", ErrAmountTooLarge, amount)
+	}
+
+	if toAmount.Sign() <= 0 {
+		return Quote{}, fmt.Errorf("%w: %d", ErrAmountTooSmall, amount)
+	}
+
+	return Quote{
+		Rate:      rate.Int64(),
+		SpreadBps: spreadBps,
+		ToAmount:  toAmount.Int64(),
+	}, nil
+}
+
+// QuoteAmount converts amount into the currency to at the rate provider
+// gives less spreadBps basis points.
+func QuoteAmount(ctx context.Context, provider RateProvider, amount money.Money, to string, spreadBps int32) (Quote, error) {
+	fromCurrency, ok := currency.Get(amount.Currency)
+
+	if !ok {
+		return Quote{}, fmt.Errorf("%w: %s", currency.ErrUnknownCurrency, amount.Currency)
+	}
+
+	toCurrency, ok := currency.Get(to)
+
+	if !ok {
+		return Quote{}, fmt.Errorf("%w: %s", currency.ErrUnknownCurrency, to)
+	}
+
+	rate, err := provider.GetRate(ctx, amount.Currency, to)
+
+	if err != nil {
+		return Quote{}, err
+	}
+
+	return Convert(amount.Amount, rate, spreadBps, fromCurrency.MinorUnits, toCurrency.MinorUnits)
+}
+
+// IsUnquotable reports whether err from QuoteAmount means the amount can't be
+// converted, rather than that quoting failed.
+func IsUnquotable(err error) bool {
+	return errors.Is(err, ErrRateNotFound) || errors.Is(err, ErrAmountTooSmall) || errors.Is(err, ErrAmountTooLarge)
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
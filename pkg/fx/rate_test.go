@@ -0,0 +1,138 @@
+package fx
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aseerkt/go-simple-bank/pkg/currency"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestParseRate(t *testing.T) {
+	rate, err := ParseRate("0.92")
+	require.NoError(t, err)
+	require.Equal(t, int64(92_000_000), rate)
+	require.Equal(t, "0.92000000", FormatRate(rate))
+
+	rate, err = ParseRate("83.125")
+	require.NoError(t, err)
+	require.Equal(t, int64(8_312_500_000), rate)
+
+	for _, value := range []string{"", "abc", "0", "-1.5", "0.000000001"} {
+		_, err := ParseRate(value)
+		require.ErrorIs(t, err, ErrInvalidRate, value)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	quote, err := Convert(10_000, 92_000_000, 0, 2, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(9_200), quote.ToAmount)
+	require.Equal(t, int64(92_000_000), quote.Rate)
+
+	// 50 basis points off 0.92 is 0.9154
+	quote, err = Convert(10_000, 92_000_000, 50, 2, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(91_540_000), quote.Rate)
+	require.Equal(t, int32(50), quote.SpreadBps)
+	require.Equal(t, int64(9_154), quote.ToAmount)
+
+	// rounds down
+	quote, err = Convert(3, 50_000_000, 0, 2, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), quote.ToAmount)
+
+	_, err = Convert(1, 50_000_000, 0, 2, 2)
+	require.ErrorIs(t, err, ErrAmountTooSmall)
+
+	_, err = Convert(1<<62, 8_312_500_000, 0, 2, 2)
+	require.ErrorIs(t, err, ErrAmountTooLarge)
+
+	// 100.00 USD at 156.5 is 15650 JPY, which has no minor units
+	quote, err = Convert(10_000, 15_650_000_000, 0, 2, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(15_650), quote.ToAmount)
+
+	// 15650 JPY at 0.0064 is 100.160 KWD
+	quote, err = Convert(15_650, 640_000, 0, 0, 3)
+	require.NoError(t, err)
+	require.Equal(t, int64(100_160), quote.ToAmount)
+}
+
+func TestQuoteAmount(t *testing.T) {
+	currency.Register(
+		currency.Currency{Code: "USD", NumericCode: 840, MinorUnits: 2, Enabled: true},
+		currency.Currency{Code: "JPY", NumericCode: 392, MinorUnits: 0, Enabled: true},
+	)
+
+	provider := StaticProvider{"USD/JPY": 15_650_000_000}
+
+	quote, err := QuoteAmount(context.Background(), provider, money.New(10_000, "USD"), "JPY", 50)
+	require.NoError(t, err)
+	require.Equal(t, int64(15_571), quote.ToAmount)
+	require.Equal(t, int32(50), quote.SpreadBps)
+
+	_, err = QuoteAmount(context.Background(), provider, money.New(10_000, "JPY"), "USD", 0)
+	require.ErrorIs(t, err, ErrRateNotFound)
+	require.True(t, IsUnquotable(err))
+
+	_, err = QuoteAmount(context.Background(), provider, money.New(10_000, "USD"), "XXX", 0)
+	require.ErrorIs(t, err, currency.ErrUnknownCurrency)
+	require.False(t, IsUnquotable(err))
+}
+
+func TestStaticProvider(t *testing.T) {
+	provider := StaticProvider{"USD/EUR": 92_000_000}
+
+	rate, err := provider.GetRate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, int64(92_000_000), rate)
+
+	_, err = provider.GetRate(context.Background(), "EUR", "USD")
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
+
+func TestLoadFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+
+	err := os.WriteFile(path, []byte(`{"USD/EUR": "0.92", "USD/INR": "83.125"}`), 0o600)
+	require.NoError(t, err)
+
+	provider, err := LoadFileProvider(path)
+	require.NoError(t, err)
+	require.Equal(t, StaticProvider{"USD/EUR": 92_000_000, "USD/INR": 8_312_500_000}, provider)
+
+	err = os.WriteFile(path, []byte(`{"USD/EUR": "nope"}`), 0o600)
+	require.NoError(t, err)
+
+	_, err = LoadFileProvider(path)
+	require.ErrorIs(t, err, ErrInvalidRate)
+}
+
+func TestStoreProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().GetRate(gomock.Any(), gomock.Eq(db.GetRateParams{BaseCurrency: "USD", QuoteCurrency: "EUR"})).
+		Times(1).Return(db.Rate{BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: 92_000_000}, nil)
+	store.EXPECT().GetRate(gomock.Any(), gomock.Eq(db.GetRateParams{BaseCurrency: "EUR", QuoteCurrency: "USD"})).
+		Times(1).Return(db.Rate{}, sql.ErrNoRows)
+
+	provider := NewStoreProvider(store)
+
+	rate, err := provider.GetRate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, int64(92_000_000), rate)
+
+	_, err = provider.GetRate(context.Background(), "EUR", "USD")
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
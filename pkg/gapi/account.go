@@ -0,0 +1,97 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/pb"
+	"github.com/aseerkt/go-simple-bank/pkg/service"
+	"github.com/lib/pq"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	if err := validateCurrency(req.GetCurrency()); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	arg := db.CreateAccountParams{
+		Owner:    getAuthPayload(ctx).Username,
+		Currency: req.GetCurrency(),
+		Balance:  0,
+		Product:  db.ProductChecking,
+	}
+
+	switch req.GetProduct() {
+	case "":
+	case db.ProductChecking, db.ProductSavings:
+		arg.Product = req.GetProduct()
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "product %q must be %s or %s", req.GetProduct(), db.ProductChecking, db.ProductSavings)
+	}
+
+	account, err := s.store.CreateAccountTx(ctx, arg)
+
+	if err != nil {
+		if pqError, ok := err.(*pq.Error); ok {
+			switch pqError.Code.Name() {
+			case "foreign_key_violation", "unique_violation":
+				return nil, status.Error(codes.PermissionDenied, pqError.Error())
+			}
+		}
+		return nil, internalError(err)
+	}
+
+	return &pb.CreateAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (s *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	if err := validateID("id", req.GetId()); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	account, err := s.store.GetAccount(ctx, req.GetId())
+
+	if err != nil {
+		return nil, notFoundOr(err)
+	}
+
+	payload := getAuthPayload(ctx)
+
+	if account.Owner != payload.Username && !service.HasRole(payload, constants.RoleTeller, constants.RoleAdmin) {
+		return nil, denyAccess(ctx, fmt.Sprintf("read account %d owned by %s", account.ID, account.Owner))
+	}
+
+	return &pb.GetAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (s *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	if req.GetPageId() < 1 {
+		return nil, status.Error(codes.InvalidArgument, "page_id must be positive")
+	}
+
+	if req.GetPageSize() < 5 || req.GetPageSize() > 20 {
+		return nil, status.Error(codes.InvalidArgument, "page_size must be between 5 and 20")
+	}
+
+	accounts, err := s.store.ListAccounts(ctx, db.ListAccountsParams{
+		Owner:  getAuthPayload(ctx).Username,
+		Limit:  req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	response := &pb.ListAccountsResponse{Accounts: make([]*pb.Account, len(accounts))}
+
+	for i, account := range accounts {
+		response.Accounts[i] = convertAccount(account)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,93 @@
+package gapi
+
+import (
+	"github.com/aseerkt/go-simple-bank/pkg/currency"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/fx"
+	"github.com/aseerkt/go-simple-bank/pkg/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func convertMoney(amount int64, code string) *pb.Money {
+	return &pb.Money{
+		Amount:   currency.FormatAmount(code, amount),
+		Currency: code,
+	}
+}
+
+func convertUser(user db.User) *pb.User {
+	return &pb.User{
+		Username: user.Username,
+		Email:    user.Email,
+		FullName: user.FullName,
+		Role:     user.Role,
+		Tier:     user.Tier,
+	}
+}
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:               account.ID,
+		Owner:            account.Owner,
+		Balance:          convertMoney(account.Balance, account.Currency),
+		AvailableBalance: convertMoney(account.AvailableBalance, account.Currency),
+		Currency:         account.Currency,
+		Product:          account.Product,
+		Status:           account.Status,
+		CreatedAt:        timestamppb.New(account.CreatedAt),
+	}
+}
+
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	response := &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        convertMoney(transfer.Amount, transfer.Currency),
+		ToAmount:      convertMoney(transfer.ToAmount, transfer.ToCurrency),
+		FxRate:        fx.FormatRate(transfer.FxRate),
+		FxSpreadBps:   transfer.FxSpreadBps,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+	}
+
+	if transfer.ReversalOf.Valid {
+		response.ReversalOf = &transfer.ReversalOf.Int64
+	}
+
+	return response
+}
+
+func convertEntry(entry db.Entry, code string) *pb.Entry {
+	response := &pb.Entry{
+		Id:        entry.ID,
+		AccountId: entry.AccountID,
+		Amount:    convertMoney(entry.Amount, code),
+		CreatedAt: timestamppb.New(entry.CreateAt),
+	}
+
+	if entry.TransferID.Valid {
+		response.TransferId = &entry.TransferID.Int64
+	}
+
+	return response
+}
+
+func convertTransferTxResult(result db.TransferTxResult) *pb.CreateTransferResponse {
+	fees := make([]*pb.TransferFee, len(result.Fees))
+
+	for i, fee := range result.Fees {
+		fees[i] = &pb.TransferFee{
+			Kind:   fee.Kind,
+			Amount: convertMoney(fee.Amount, fee.Currency),
+		}
+	}
+
+	return &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry, result.FromAccount.Currency),
+		ToEntry:     convertEntry(result.ToEntry, result.ToAccount.Currency),
+		Fees:        fees,
+	}
+}
@@ -0,0 +1,45 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/aseerkt/go-simple-bank/pkg/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func invalidArgument(err error) error {
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+func internalError(err error) error {
+	return status.Error(codes.Internal, err.Error())
+}
+
+// notFoundOr returns NotFound for sql.ErrNoRows and an internal error for
+// anything else.
+func notFoundOr(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	return internalError(err)
+}
+
+// serviceError returns the status of the kind of a service error.
+func serviceError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrAccessDenied):
+		return denyAccess(ctx, err.Error())
+	case errors.Is(err, service.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrInvalid):
+		return invalidArgument(err)
+	case errors.Is(err, service.ErrRejected):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	return internalError(err)
+}
@@ -0,0 +1,101 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aseerkt/go-simple-bank/pkg/pb"
+	"github.com/aseerkt/go-simple-bank/pkg/service"
+	"github.com/aseerkt/go-simple-bank/pkg/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeaderKey = "authorization"
+	authorizationTypeKey   = "bearer"
+)
+
+type authPayloadKey struct{}
+
+// publicMethods can be called without an access token.
+var publicMethods = map[string]bool{
+	pb.SimpleBank_CreateUser_FullMethodName: true,
+	pb.SimpleBank_LoginUser_FullMethodName:  true,
+}
+
+// authInterceptor is the gRPC counterpart of the auth middleware: it verifies
+// the bearer token in the authorization metadata and puts its payload in the
+// context of the handler.
+func (s *Server) authInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	payload, err := s.authorize(ctx)
+
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return handler(context.WithValue(ctx, authPayloadKey{}, payload), req)
+}
+
+func (s *Server) authorize(ctx context.Context) (*token.Payload, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+
+	if !ok {
+		return nil, errors.New("missing metadata")
+	}
+
+	values := md.Get(authorizationHeaderKey)
+
+	if len(values) < 1 {
+		return nil, errors.New("authorization header not found")
+	}
+
+	fields := strings.Fields(values[0])
+
+	if len(fields) < 2 {
+		return nil, errors.New("invalid authorization header format")
+	}
+
+	authorizationType := strings.ToLower(fields[0])
+
+	if authorizationType != authorizationTypeKey {
+		return nil, fmt.Errorf("unsupported authorization type: %s", authorizationType)
+	}
+
+	return token.VerifyTokenType(s.tokenMaker, fields[1], token.AccessToken)
+}
+
+func getAuthPayload(ctx context.Context) *token.Payload {
+	return ctx.Value(authPayloadKey{}).(*token.Payload)
+}
+
+// denyAccess logs why a call was refused and returns the only error clients
+// see for it.
+func denyAccess(ctx context.Context, reason string) error {
+	username := ""
+
+	if payload, ok := ctx.Value(authPayloadKey{}).(*token.Payload); ok {
+		username = payload.Username
+	}
+
+	address := ""
+
+	if p, ok := peer.FromContext(ctx); ok {
+		address = p.Addr.String()
+	}
+
+	method, _ := grpc.Method(ctx)
+
+	service.LogDenial(username, address, method, reason)
+
+	return status.Error(codes.PermissionDenied, service.ErrAccessDenied.Error())
+}
@@ -0,0 +1,134 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/aseerkt/go-simple-bank/pkg/pb"
+	"github.com/aseerkt/go-simple-bank/pkg/token"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAuthInterceptor(t *testing.T) {
+	account := db.Account{ID: 1, Owner: "alice", Currency: "USD", Product: db.ProductChecking}
+
+	testCases := []struct {
+		name      string
+		setupAuth func(t *testing.T, s *Server) context.Context
+		buildStub func(store *mockdb.MockStore)
+		code      codes.Code
+	}{
+		{
+			name: "Ok",
+			setupAuth: func(t *testing.T, s *Server) context.Context {
+				return withToken(t, s, account.Owner, constants.RoleCustomer)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name: "NoAuthorization",
+			setupAuth: func(t *testing.T, s *Server) context.Context {
+				return context.Background()
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "UnsupportedType",
+			setupAuth: func(t *testing.T, s *Server) context.Context {
+				return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, "Basic dXNlcjpwYXNz")
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "InvalidFormat",
+			setupAuth: func(t *testing.T, s *Server) context.Context {
+				return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, "Bearer")
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "InvalidToken",
+			setupAuth: func(t *testing.T, s *Server) context.Context {
+				return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, "Bearer not-a-token")
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "RefreshToken",
+			setupAuth: func(t *testing.T, s *Server) context.Context {
+				refreshToken, _, err := s.tokenMaker.CreateToken(account.Owner, constants.RoleCustomer, token.RefreshToken, time.Hour)
+				require.NoError(t, err)
+				return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, "Bearer "+refreshToken)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+		{
+			name: "OtherUser",
+			setupAuth: func(t *testing.T, s *Server) context.Context {
+				return withToken(t, s, "mallory", constants.RoleCustomer)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "Teller",
+			setupAuth: func(t *testing.T, s *Server) context.Context {
+				return withToken(t, s, "terry", constants.RoleTeller)
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			code: codes.OK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server, client := newTestClient(t, store)
+
+			response, err := client.GetAccount(tc.setupAuth(t, server), &pb.GetAccountRequest{Id: account.ID})
+
+			if tc.code != codes.OK {
+				requireCode(t, tc.code, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, account.Owner, response.GetAccount().GetOwner())
+			require.Equal(t, "USD", response.GetAccount().GetBalance().GetCurrency())
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/currency"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/fx"
+	"github.com/aseerkt/go-simple-bank/pkg/pb"
+	"github.com/aseerkt/go-simple-bank/pkg/token"
+	"github.com/aseerkt/go-simple-bank/pkg/utils"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves a Server over an in-memory connection and returns it
+// with a client connected to it.
+func newTestClient(t *testing.T, store db.Store) (*Server, pb.SimpleBankClient) {
+	config := utils.LoadConfig("../..")
+
+	keySet, err := token.ParseKeySet(config.TokenKeyID, config.TokenKey, config.TokenVerificationKeys)
+	require.NoError(t, err)
+
+	tokenMaker, err := token.NewMaker(config.TokenType, keySet, config.TokenImplicitAssertion)
+	require.NoError(t, err)
+
+	server := NewServer(&config, store, tokenMaker, fx.StaticProvider{})
+
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := server.GRPCServer()
+
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { conn.Close() })
+
+	return server, pb.NewSimpleBankClient(conn)
+}
+
+// withToken returns a context that calls as username with role.
+func withToken(t *testing.T, s *Server, username string, role string) context.Context {
+	accessToken, _, err := s.tokenMaker.CreateToken(username, role, token.AccessToken, 15*time.Minute)
+	require.NoError(t, err)
+
+	return metadata.AppendToOutgoingContext(context.Background(), authorizationHeaderKey, fmt.Sprintf("Bearer %s", accessToken))
+}
+
+func requireCode(t *testing.T, code codes.Code, err error) {
+	require.Error(t, err)
+	require.Equal(t, code, status.Code(err), err.Error())
+}
+
+func TestMain(m *testing.M) {
+	currency.Register(
+		currency.Currency{Code: "EUR", NumericCode: 978, MinorUnits: 2, Enabled: true},
+		currency.Currency{Code: "JPY", NumericCode: 392, MinorUnits: 0, Enabled: true},
+		currency.Currency{Code: "USD", NumericCode: 840, MinorUnits: 2, Enabled: true},
+	)
+
+	os.Exit(m.Run())
+}
@@ -0,0 +1,53 @@
+package gapi
+
+import (
+	"net"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/fx"
+	"github.com/aseerkt/go-simple-bank/pkg/pb"
+	"github.com/aseerkt/go-simple-bank/pkg/token"
+	"github.com/aseerkt/go-simple-bank/pkg/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server serves the SimpleBank gRPC service. It shares the store, token maker
+// and rate provider of the REST server, so tokens from either work on both.
+type Server struct {
+	pb.UnimplementedSimpleBankServer
+	config       *utils.Config
+	store        db.Store
+	tokenMaker   token.Maker
+	rateProvider fx.RateProvider
+}
+
+func NewServer(config *utils.Config, store db.Store, tokenMaker token.Maker, rateProvider fx.RateProvider) *Server {
+	return &Server{
+		config:       config,
+		store:        store,
+		tokenMaker:   tokenMaker,
+		rateProvider: rateProvider,
+	}
+}
+
+// GRPCServer returns a grpc.Server with the service and its auth interceptor
+// registered.
+func (s *Server) GRPCServer() *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(s.authInterceptor))
+
+	pb.RegisterSimpleBankServer(server, s)
+	reflection.Register(server)
+
+	return server
+}
+
+func (s *Server) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+
+	if err != nil {
+		return err
+	}
+
+	return s.GRPCServer().Serve(listener)
+}
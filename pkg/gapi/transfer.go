@@ -0,0 +1,84 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
+	"github.com/aseerkt/go-simple-bank/pkg/pb"
+	"github.com/aseerkt/go-simple-bank/pkg/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	for _, err := range []error{
+		validateID("from_account_id", req.GetFromAccountId()),
+		validateID("to_account_id", req.GetToAccountId()),
+	} {
+		if err != nil {
+			return nil, invalidArgument(err)
+		}
+	}
+
+	if req.GetFromAccountId() == req.GetToAccountId() {
+		return nil, status.Error(codes.InvalidArgument, "to_account_id must differ from from_account_id")
+	}
+
+	amount, err := parsePositiveAmount(req.GetAmount().GetCurrency(), req.GetAmount().GetAmount())
+
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	arg, err := service.PrepareTransfer(ctx, s.store, s.rateProvider, s.config.FxSpreadBps, service.TransferRequest{
+		Username:      getAuthPayload(ctx).Username,
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        amount,
+	})
+
+	if err != nil {
+		return nil, serviceError(ctx, err)
+	}
+
+	result, err := service.Transfer(ctx, s.store, arg)
+
+	if err != nil {
+		return nil, serviceError(ctx, err)
+	}
+
+	return convertTransferTxResult(result), nil
+}
+
+func (s *Server) GetTransfer(ctx context.Context, req *pb.GetTransferRequest) (*pb.GetTransferResponse, error) {
+	if err := validateID("id", req.GetId()); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	transfer, err := s.store.GetTransfer(ctx, req.GetId())
+
+	if err != nil {
+		return nil, notFoundOr(err)
+	}
+
+	payload := getAuthPayload(ctx)
+
+	if service.HasRole(payload, constants.RoleTeller, constants.RoleAdmin) {
+		return &pb.GetTransferResponse{Transfer: convertTransfer(transfer)}, nil
+	}
+
+	for _, accountID := range []int64{transfer.FromAccountID, transfer.ToAccountID} {
+		account, err := s.store.GetAccount(ctx, accountID)
+
+		if err != nil {
+			return nil, internalError(err)
+		}
+
+		if account.Owner == payload.Username {
+			return &pb.GetTransferResponse{Transfer: convertTransfer(transfer)}, nil
+		}
+	}
+
+	return nil, denyAccess(ctx, fmt.Sprintf("read transfer %d", transfer.ID))
+}
@@ -0,0 +1,219 @@
+package gapi
+
+import (
+	"testing"
+
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/aseerkt/go-simple-bank/pkg/pb"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+)
+
+func TestCreateTransfer(t *testing.T) {
+	account1 := db.Account{ID: 1, Owner: "alice", Balance: 10000, Currency: "USD", Product: db.ProductChecking}
+	account2 := db.Account{ID: 2, Owner: "bob", Balance: 10000, Currency: "USD", Product: db.ProductChecking}
+	systemAccount := db.Account{ID: 3, Owner: db.SystemAccountOwner, Currency: "USD", Product: db.ProductChecking}
+
+	testCases := []struct {
+		name      string
+		owner     string
+		req       *pb.CreateTransferRequest
+		buildStub func(store *mockdb.MockStore)
+		code      codes.Code
+	}{
+		{
+			name:  "Ok",
+			owner: account1.Owner,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        &pb.Money{Amount: "10.50", Currency: "USD"},
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(db.CreateTransferParams{
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        1050,
+					})).
+					Times(1).
+					Return(db.TransferTxResult{
+						Transfer:    db.Transfer{ID: 7, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 1050, Currency: "USD"},
+						FromAccount: account1,
+						ToAccount:   account2,
+					}, nil)
+			},
+			code: codes.OK,
+		},
+		{
+			name:  "SameAccount",
+			owner: account1.Owner,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account1.ID,
+				Amount:        &pb.Money{Amount: "10.50", Currency: "USD"},
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name:  "InvalidAmount",
+			owner: account1.Owner,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        &pb.Money{Amount: "-1", Currency: "USD"},
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name:  "CurrencyMismatch",
+			owner: account1.Owner,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        &pb.Money{Amount: "10.50", Currency: "EUR"},
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name:  "UnauthorizedUser",
+			owner: account2.Owner,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        &pb.Money{Amount: "10.50", Currency: "USD"},
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name:  "UnauthorizedUserOtherCurrency",
+			owner: account2.Owner,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        &pb.Money{Amount: "10.50", Currency: "EUR"},
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.PermissionDenied,
+		},
+		{
+			name:  "SystemAccount",
+			owner: account1.Owner,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   systemAccount.ID,
+				Amount:        &pb.Money{Amount: "10.50", Currency: "USD"},
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(systemAccount.ID)).Times(1).Return(systemAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name:  "InsufficientFunds",
+			owner: account1.Owner,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        &pb.Money{Amount: "10.50", Currency: "USD"},
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			code: codes.FailedPrecondition,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server, client := newTestClient(t, store)
+
+			response, err := client.CreateTransfer(withToken(t, server, tc.owner, constants.RoleCustomer), tc.req)
+
+			if tc.code != codes.OK {
+				requireCode(t, tc.code, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "10.50", response.GetTransfer().GetAmount().GetAmount())
+			require.Equal(t, "USD", response.GetTransfer().GetAmount().GetCurrency())
+		})
+	}
+}
+
+func TestGetTransfer(t *testing.T) {
+	account1 := db.Account{ID: 1, Owner: "alice", Currency: "USD"}
+	account2 := db.Account{ID: 2, Owner: "bob", Currency: "USD"}
+	transfer := db.Transfer{ID: 7, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 1050}
+
+	testCases := []struct {
+		name  string
+		owner string
+		role  string
+		code  codes.Code
+	}{
+		{name: "Sender", owner: account1.Owner, role: constants.RoleCustomer, code: codes.OK},
+		{name: "Recipient", owner: account2.Owner, role: constants.RoleCustomer, code: codes.OK},
+		{name: "Stranger", owner: "mallory", role: constants.RoleCustomer, code: codes.PermissionDenied},
+		{name: "Teller", owner: "terry", role: constants.RoleTeller, code: codes.OK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).MaxTimes(1).Return(account1, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).MaxTimes(1).Return(account2, nil)
+
+			server, client := newTestClient(t, store)
+
+			response, err := client.GetTransfer(withToken(t, server, tc.owner, tc.role), &pb.GetTransferRequest{Id: transfer.ID})
+
+			if tc.code != codes.OK {
+				requireCode(t, tc.code, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, transfer.ID, response.GetTransfer().GetId())
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/pb"
+	"github.com/aseerkt/go-simple-bank/pkg/token"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	for _, err := range []error{
+		validateUsername(req.GetUsername()),
+		validatePassword(req.GetPassword()),
+		validateEmail(req.GetEmail()),
+	} {
+		if err != nil {
+			return nil, invalidArgument(err)
+		}
+	}
+
+	if req.GetFullName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "full_name is required")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.GetPassword()), bcrypt.DefaultCost)
+
+	if err != nil {
+		return nil, invalidArgument(fmt.Errorf("failed to hash password: %w", err))
+	}
+
+	user, err := s.store.CreateUserTx(ctx, db.CreateUserParams{
+		Username:       req.GetUsername(),
+		FullName:       req.GetFullName(),
+		Email:          req.GetEmail(),
+		HashedPassword: string(hashedPassword),
+	})
+
+	if err != nil {
+		if pqError, ok := err.(*pq.Error); ok && pqError.Code.Name() == "unique_violation" {
+			return nil, status.Errorf(codes.AlreadyExists, "user already exists: %s", err)
+		}
+		return nil, internalError(err)
+	}
+
+	return &pb.CreateUserResponse{User: convertUser(user)}, nil
+}
+
+func (s *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
+	if err := validateUsername(req.GetUsername()); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	user, err := s.store.GetUser(ctx, req.GetUsername())
+
+	if err != nil {
+		return nil, notFoundOr(err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.HashedPassword), []byte(req.GetPassword())); err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, token.AccessToken, s.config.AccessTokenDuration)
+
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, token.RefreshToken, s.config.RefreshTokenDuration)
+
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	sessionID, err := uuid.Parse(refreshPayload.ID)
+
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	userAgent, clientIP := clientInfo(ctx)
+
+	session, err := s.store.CreateSession(ctx, db.CreateSessionParams{
+		ID:           sessionID,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		UserAgent:    userAgent,
+		ClientIp:     clientIP,
+		IsBlocked:    false,
+		ExpiresAt:    refreshPayload.ExpiresAt.Time,
+	})
+
+	if err != nil {
+		return nil, internalError(err)
+	}
+
+	return &pb.LoginUserResponse{
+		SessionId:             session.ID.String(),
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  timestamppb.New(accessPayload.ExpiresAt.Time),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(refreshPayload.ExpiresAt.Time),
+		User:                  convertUser(user),
+	}, nil
+}
+
+// clientInfo returns the user agent and address of the caller for the
+// session they log in to.
+func clientInfo(ctx context.Context) (string, string) {
+	userAgent, clientIP := "", ""
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("user-agent"); len(values) > 0 {
+			userAgent = values[0]
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		clientIP = p.Addr.String()
+	}
+
+	return userAgent, clientIP
+}
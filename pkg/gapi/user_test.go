@@ -0,0 +1,200 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/aseerkt/go-simple-bank/pkg/constants"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/aseerkt/go-simple-bank/pkg/pb"
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+)
+
+func createRandomUser(t *testing.T) (db.User, string) {
+	password := gofakeit.Password(true, true, true, false, false, 8)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	require.NoError(t, err)
+
+	return db.User{
+		Username:       gofakeit.Username(),
+		FullName:       gofakeit.Name(),
+		Email:          gofakeit.Email(),
+		HashedPassword: string(hashedPassword),
+		Role:           constants.RoleCustomer,
+		Tier:           db.TierStandard,
+	}, password
+}
+
+func TestCreateUser(t *testing.T) {
+	user, password := createRandomUser(t)
+
+	testCases := []struct {
+		name      string
+		req       *pb.CreateUserRequest
+		buildStub func(store *mockdb.MockStore)
+		code      codes.Code
+	}{
+		{
+			name: "Ok",
+			req: &pb.CreateUserRequest{
+				Username: user.Username,
+				FullName: user.FullName,
+				Email:    user.Email,
+				Password: password,
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateUserParams) (db.User, error) {
+						require.NoError(t, bcrypt.CompareHashAndPassword([]byte(arg.HashedPassword), []byte(password)))
+						return user, nil
+					})
+			},
+			code: codes.OK,
+		},
+		{
+			name: "InvalidEmail",
+			req: &pb.CreateUserRequest{
+				Username: user.Username,
+				FullName: user.FullName,
+				Email:    "not-an-email",
+				Password: password,
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "ShortPassword",
+			req: &pb.CreateUserRequest{
+				Username: user.Username,
+				FullName: user.FullName,
+				Email:    user.Email,
+				Password: "abc",
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "DuplicateUsername",
+			req: &pb.CreateUserRequest{
+				Username: user.Username,
+				FullName: user.FullName,
+				Email:    user.Email,
+				Password: password,
+			},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, &pq.Error{Code: "23505"})
+			},
+			code: codes.AlreadyExists,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			_, client := newTestClient(t, store)
+
+			response, err := client.CreateUser(context.Background(), tc.req)
+
+			if tc.code != codes.OK {
+				requireCode(t, tc.code, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, user.Username, response.GetUser().GetUsername())
+			require.Equal(t, user.Email, response.GetUser().GetEmail())
+		})
+	}
+}
+
+func TestLoginUser(t *testing.T) {
+	user, password := createRandomUser(t)
+
+	testCases := []struct {
+		name      string
+		password  string
+		buildStub func(store *mockdb.MockStore)
+		code      codes.Code
+	}{
+		{
+			name:     "Ok",
+			password: password,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateSessionParams) (db.Session, error) {
+						return db.Session{ID: arg.ID, Username: arg.Username}, nil
+					})
+			},
+			code: codes.OK,
+		},
+		{
+			name:     "UserNotFound",
+			password: password,
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.NotFound,
+		},
+		{
+			name:     "WrongPassword",
+			password: "wrong-password",
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			code: codes.Unauthenticated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			server, client := newTestClient(t, store)
+
+			response, err := client.LoginUser(context.Background(), &pb.LoginUserRequest{
+				Username: user.Username,
+				Password: tc.password,
+			})
+
+			if tc.code != codes.OK {
+				requireCode(t, tc.code, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotEmpty(t, response.GetSessionId())
+
+			payload, err := server.tokenMaker.VerifyToken(response.GetAccessToken())
+			require.NoError(t, err)
+			require.Equal(t, user.Username, payload.Username)
+		})
+	}
+}
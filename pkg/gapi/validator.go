@@ -0,0 +1,73 @@
+package gapi
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+
+	"github.com/aseerkt/go-simple-bank/pkg/currency"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+)
+
+var isAlphanumeric = regexp.MustCompile(`^[a-zA-Z0-9]+$`).MatchString
+
+func validateUsername(value string) error {
+	if !isAlphanumeric(value) {
+		return fmt.Errorf("username %q must be letters and digits only", value)
+	}
+
+	return nil
+}
+
+func validatePassword(value string) error {
+	if len(value) < 6 {
+		return errors.New("password must be at least 6 characters")
+	}
+
+	return nil
+}
+
+func validateEmail(value string) error {
+	if _, err := mail.ParseAddress(value); err != nil {
+		return fmt.Errorf("email %q is invalid: %w", value, err)
+	}
+
+	return nil
+}
+
+func validateCurrency(code string) error {
+	if !currency.IsSupported(code) {
+		return fmt.Errorf("%w: %q", currency.ErrUnknownCurrency, code)
+	}
+
+	return nil
+}
+
+func validateID(name string, value int64) error {
+	if value < 1 {
+		return fmt.Errorf("%s must be positive", name)
+	}
+
+	return nil
+}
+
+// parsePositiveAmount reads a decimal amount in the currency's minor units
+// that must be above zero.
+func parsePositiveAmount(code string, value string) (money.Money, error) {
+	if err := validateCurrency(code); err != nil {
+		return money.Money{}, err
+	}
+
+	amount, err := money.Parse(code, value)
+
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	if !amount.IsPositive() {
+		return money.Money{}, fmt.Errorf("%w: amount must be greater than zero", currency.ErrInvalidAmount)
+	}
+
+	return amount, nil
+}
@@ -0,0 +1,51 @@
+package ledger
+
+import (
+	"context"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+)
+
+// Report lists every place where balances, transfers and entries disagree.
+type Report struct {
+	CheckedAt          time.Time                           `json:"checked_at"`
+	Balanced           bool                                `json:"balanced"`
+	BalanceMismatches  []db.ListBalanceMismatchesRow       `json:"balance_mismatches"`
+	TransferMismatches []db.ListTransferEntryMismatchesRow `json:"transfer_mismatches"`
+	CurrencyImbalances []db.ListCurrencyImbalancesRow      `json:"currency_imbalances"`
+}
+
+// Reconcile checks that each account balance equals the sum of its entries,
+// that each transfer has exactly one debit and one credit entry (plus the two
+// cash account legs of a cross-currency transfer), and that the entries of
+// each currency net to zero.
+func Reconcile(ctx context.Context, q db.Querier) (Report, error) {
+	report := Report{CheckedAt: time.Now()}
+
+	var err error
+
+	report.BalanceMismatches, err = q.ListBalanceMismatches(ctx)
+
+	if err != nil {
+		return report, err
+	}
+
+	report.TransferMismatches, err = q.ListTransferEntryMismatches(ctx)
+
+	if err != nil {
+		return report, err
+	}
+
+	report.CurrencyImbalances, err = q.ListCurrencyImbalances(ctx)
+
+	if err != nil {
+		return report, err
+	}
+
+	report.Balanced = len(report.BalanceMismatches) == 0 &&
+		len(report.TransferMismatches) == 0 &&
+		len(report.CurrencyImbalances) == 0
+
+	return report, nil
+}
@@ -0,0 +1,78 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReconcile(t *testing.T) {
+	testCases := []struct {
+		name          string
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, report Report, err error)
+	}{
+		{
+			name: "Balanced",
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().ListBalanceMismatches(gomock.Any()).Times(1).Return([]db.ListBalanceMismatchesRow{}, nil)
+				store.EXPECT().ListTransferEntryMismatches(gomock.Any()).Times(1).Return([]db.ListTransferEntryMismatchesRow{}, nil)
+				store.EXPECT().ListCurrencyImbalances(gomock.Any()).Times(1).Return([]db.ListCurrencyImbalancesRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, report Report, err error) {
+				require.NoError(t, err)
+				require.True(t, report.Balanced)
+				require.NotZero(t, report.CheckedAt)
+			},
+		},
+		{
+			name: "Mismatches",
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().ListBalanceMismatches(gomock.Any()).Times(1).
+					Return([]db.ListBalanceMismatchesRow{{AccountID: 1, Currency: "USD", Balance: 100, EntriesTotal: 90}}, nil)
+				store.EXPECT().ListTransferEntryMismatches(gomock.Any()).Times(1).
+					Return([]db.ListTransferEntryMismatchesRow{{TransferID: 2, EntryCount: 1, DebitCount: 1}}, nil)
+				store.EXPECT().ListCurrencyImbalances(gomock.Any()).Times(1).
+					Return([]db.ListCurrencyImbalancesRow{{Currency: "USD", Total: 10}}, nil)
+			},
+			checkResponse: func(t *testing.T, report Report, err error) {
+				require.NoError(t, err)
+				require.False(t, report.Balanced)
+				require.Len(t, report.BalanceMismatches, 1)
+				require.Len(t, report.TransferMismatches, 1)
+				require.Len(t, report.CurrencyImbalances, 1)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().ListBalanceMismatches(gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+				store.EXPECT().ListTransferEntryMismatches(gomock.Any()).Times(0)
+				store.EXPECT().ListCurrencyImbalances(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, report Report, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+				require.False(t, report.Balanced)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			report, err := Reconcile(context.Background(), store)
+
+			tc.checkResponse(t, report, err)
+		})
+	}
+}
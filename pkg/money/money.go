@@ -0,0 +1,148 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/aseerkt/go-simple-bank/pkg/currency"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOverflow         = errors.New("amount out of range")
+)
+
+// Money is an amount of minor units in a currency, e.g. 1050 USD is $10.50.
+// Arithmetic between amounts in different currencies or past the range of
+// int64 returns an error instead of a wrong result.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func New(amount int64, code string) Money {
+	return Money{Amount: amount, Currency: code}
+}
+
+// Parse reads a decimal string in the currency's minor units, e.g. "10.50"
+// USD or "1050" JPY.
+func Parse(code string, value string) (Money, error) {
+	amount, err := currency.ParseAmount(code, value)
+
+	if err != nil {
+		return Money{}, err
+	}
+
+	return New(amount, code), nil
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.checkCurrency(other); err != nil {
+		return Money{}, err
+	}
+
+	sum := m.Amount + other.Amount
+
+	if (other.Amount > 0 && sum < m.Amount) || (other.Amount < 0 && sum > m.Amount) {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrOverflow, m, other)
+	}
+
+	return New(sum, m.Currency), nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.checkCurrency(other); err != nil {
+		return Money{}, err
+	}
+
+	difference := m.Amount - other.Amount
+
+	if (other.Amount > 0 && difference > m.Amount) || (other.Amount < 0 && difference < m.Amount) {
+		return Money{}, fmt.Errorf("%w: %s - %s", ErrOverflow, m, other)
+	}
+
+	return New(difference, m.Currency), nil
+}
+
+// Neg returns -m, which overflows only for the smallest int64.
+func (m Money) Neg() (Money, error) {
+	if m.Amount == math.MinInt64 {
+		return Money{}, fmt.Errorf("%w: -(%s)", ErrOverflow, m)
+	}
+
+	return New(-m.Amount, m.Currency), nil
+}
+
+// Cmp returns -1, 0 or +1 as m is less than, equal to or greater than other.
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.checkCurrency(other); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case m.Amount < other.Amount:
+		return -1, nil
+	case m.Amount > other.Amount:
+		return 1, nil
+	}
+
+	return 0, nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+func (m Money) String() string {
+	return currency.FormatAmount(m.Currency, m.Amount) + " " + m.Currency
+}
+
+func (m Money) checkCurrency(other Money) error {
+	if m.Currency != other.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+
+	return nil
+}
+
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON writes m as {"amount": "10.50", "currency": "USD"}, keeping the
+// amount a string so it survives clients that read numbers as floats.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{
+		Amount:   currency.FormatAmount(m.Currency, m.Amount),
+		Currency: m.Currency,
+	})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var value moneyJSON
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	parsed, err := Parse(value.Currency, value.Amount)
+
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+
+	return nil
+}
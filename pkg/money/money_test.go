@@ -0,0 +1,109 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/aseerkt/go-simple-bank/pkg/currency"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	currency.Register(
+		currency.Currency{Code: "USD", NumericCode: 840, MinorUnits: 2, Enabled: true},
+		currency.Currency{Code: "EUR", NumericCode: 978, MinorUnits: 2, Enabled: true},
+		currency.Currency{Code: "JPY", NumericCode: 392, MinorUnits: 0, Enabled: true},
+		currency.Currency{Code: "KWD", NumericCode: 414, MinorUnits: 3, Enabled: true},
+	)
+
+	os.Exit(m.Run())
+}
+
+func TestAdd(t *testing.T) {
+	sum, err := New(1050, "USD").Add(New(-50, "USD"))
+	require.NoError(t, err)
+	require.Equal(t, New(1000, "USD"), sum)
+
+	_, err = New(math.MaxInt64, "USD").Add(New(1, "USD"))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(math.MinInt64, "USD").Add(New(-1, "USD"))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(1, "USD").Add(New(1, "EUR"))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestSub(t *testing.T) {
+	difference, err := New(1050, "USD").Sub(New(2000, "USD"))
+	require.NoError(t, err)
+	require.Equal(t, New(-950, "USD"), difference)
+
+	_, err = New(math.MinInt64, "USD").Sub(New(1, "USD"))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(0, "USD").Sub(New(math.MinInt64, "USD"))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(1, "USD").Sub(New(1, "JPY"))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestNeg(t *testing.T) {
+	negated, err := New(1050, "USD").Neg()
+	require.NoError(t, err)
+	require.Equal(t, New(-1050, "USD"), negated)
+
+	_, err = New(math.MinInt64, "USD").Neg()
+	require.ErrorIs(t, err, ErrOverflow)
+}
+
+func TestCmp(t *testing.T) {
+	testCases := []struct {
+		a, b     Money
+		expected int
+	}{
+		{New(1, "USD"), New(2, "USD"), -1},
+		{New(2, "USD"), New(2, "USD"), 0},
+		{New(3, "USD"), New(2, "USD"), 1},
+	}
+
+	for _, tc := range testCases {
+		cmp, err := tc.a.Cmp(tc.b)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, cmp)
+	}
+
+	_, err := New(1, "USD").Cmp(New(1, "EUR"))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestJSON(t *testing.T) {
+	testCases := []struct {
+		money    Money
+		expected string
+	}{
+		{New(1050, "USD"), `{"amount":"10.50","currency":"USD"}`},
+		{New(-1050, "JPY"), `{"amount":"-1050","currency":"JPY"}`},
+		{New(1, "KWD"), `{"amount":"0.001","currency":"KWD"}`},
+	}
+
+	for _, tc := range testCases {
+		data, err := json.Marshal(tc.money)
+		require.NoError(t, err)
+		require.JSONEq(t, tc.expected, string(data))
+
+		var got Money
+		require.NoError(t, json.Unmarshal(data, &got))
+		require.Equal(t, tc.money, got)
+	}
+
+	var got Money
+	err := json.Unmarshal([]byte(`{"amount":"10.5","currency":"JPY"}`), &got)
+	require.ErrorIs(t, err, currency.ErrInvalidAmount)
+
+	err = json.Unmarshal([]byte(`{"amount":"10","currency":"XXX"}`), &got)
+	require.ErrorIs(t, err, currency.ErrUnknownCurrency)
+}
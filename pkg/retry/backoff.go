@@ -0,0 +1,14 @@
+package retry
+
+import "time"
+
+// Backoff is the wait before retrying after attempt failed: base after the
+// first attempt, doubled after each one after that, up to max.
+func Backoff(attempt int32, base, max time.Duration) time.Duration {
+	wait := base
+	for i := int32(1); i < attempt && wait < max; i++ {
+		wait *= 2
+	}
+
+	return min(wait, max)
+}
@@ -0,0 +1,15 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, time.Minute, Backoff(1, time.Minute, time.Hour))
+	require.Equal(t, 2*time.Minute, Backoff(2, time.Minute, time.Hour))
+	require.Equal(t, 8*time.Minute, Backoff(4, time.Minute, time.Hour))
+	require.Equal(t, time.Hour, Backoff(20, time.Minute, time.Hour))
+}
@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+)
+
+// AccrueInterest accrues interest for every whole UTC day that hasn't been
+// accrued yet and then posts every month that has ended but not been posted.
+// Both steps only pick up what's left to do, so a run that stops halfway is
+// finished by the next one, and workers in other replicas can run it at the
+// same time.
+func (w *Worker) AccrueInterest(ctx context.Context) error {
+	today := truncateDay(w.now())
+
+	day := today.AddDate(0, 0, -1)
+
+	last, err := w.store.GetLastInterestAccrualDate(ctx)
+
+	if err == nil {
+		day = truncateDay(last).AddDate(0, 0, 1)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	for ; day.Before(today); day = day.AddDate(0, 0, 1) {
+		_, err := w.store.AccrueInterest(ctx, db.AccrueInterestParams{
+			AccrualDate: day,
+			DayEnd:      day.AddDate(0, 0, 1),
+		})
+
+		if err != nil {
+			return fmt.Errorf("accrue interest for %s: %w", day.Format(time.DateOnly), err)
+		}
+	}
+
+	periods, err := w.store.ListUnpostedInterestPeriods(ctx, today.AddDate(0, 0, 1-today.Day()))
+
+	if err != nil {
+		return err
+	}
+
+	for _, period := range periods {
+		_, err := w.store.PostInterestTx(ctx, db.PostInterestTxParams{
+			AccountID: period.AccountID,
+			Period:    period.Period,
+		})
+
+		// another worker posted it since the list was read
+		if errors.Is(err, db.ErrInterestPosted) {
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("post interest of account %d for %s: %w", period.AccountID, period.Period.Format("2006-01"), err)
+		}
+	}
+
+	return nil
+}
+
+// truncateDay returns the start of the UTC day t falls on.
+func truncateDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
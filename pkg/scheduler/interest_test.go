@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAccrueInterest(t *testing.T) {
+	now := time.Date(2024, 8, 2, 9, 30, 0, 0, time.UTC)
+
+	day := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	accrue := func(store *mockdb.MockStore, days ...time.Time) {
+		calls := make([]any, len(days))
+
+		for i, d := range days {
+			calls[i] = store.EXPECT().AccrueInterest(gomock.Any(), gomock.Eq(db.AccrueInterestParams{
+				AccrualDate: d,
+				DayEnd:      d.AddDate(0, 0, 1),
+			})).Times(1).Return(int64(3), nil)
+		}
+
+		gomock.InOrder(calls...)
+	}
+
+	periods := []db.ListUnpostedInterestPeriodsRow{
+		{AccountID: 10, Period: day(7, 1)},
+		{AccountID: 20, Period: day(7, 1)},
+	}
+
+	testCases := []struct {
+		name          string
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, err error)
+	}{
+		{
+			name: "FirstRun",
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLastInterestAccrualDate(gomock.Any()).Times(1).Return(time.Time{}, sql.ErrNoRows)
+				accrue(store, day(8, 1))
+				store.EXPECT().ListUnpostedInterestPeriods(gomock.Any(), gomock.Eq(day(8, 1))).Times(1).Return(nil, nil)
+				store.EXPECT().PostInterestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "CatchUp",
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLastInterestAccrualDate(gomock.Any()).Times(1).Return(day(7, 30), nil)
+				accrue(store, day(7, 31), day(8, 1))
+				store.EXPECT().ListUnpostedInterestPeriods(gomock.Any(), gomock.Eq(day(8, 1))).Times(1).Return(periods, nil)
+				store.EXPECT().PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{AccountID: 10, Period: day(7, 1)})).Times(1)
+				store.EXPECT().PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{AccountID: 20, Period: day(7, 1)})).Times(1)
+			},
+			checkResponse: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "UpToDate",
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLastInterestAccrualDate(gomock.Any()).Times(1).Return(day(8, 1), nil)
+				store.EXPECT().AccrueInterest(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListUnpostedInterestPeriods(gomock.Any(), gomock.Eq(day(8, 1))).Times(1).Return(nil, nil)
+			},
+			checkResponse: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "PostedElsewhere",
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLastInterestAccrualDate(gomock.Any()).Times(1).Return(day(8, 1), nil)
+				store.EXPECT().ListUnpostedInterestPeriods(gomock.Any(), gomock.Any()).Times(1).Return(periods, nil)
+				store.EXPECT().PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{AccountID: 10, Period: day(7, 1)})).Times(1).
+					Return(db.PostInterestTxResult{}, db.ErrInterestPosted)
+				store.EXPECT().PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{AccountID: 20, Period: day(7, 1)})).Times(1)
+			},
+			checkResponse: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "AccrualError",
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLastInterestAccrualDate(gomock.Any()).Times(1).Return(day(7, 30), nil)
+				store.EXPECT().AccrueInterest(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+				store.EXPECT().ListUnpostedInterestPeriods(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			worker := NewWorker(store, time.Second)
+			worker.now = func() time.Time { return now }
+
+			tc.checkResponse(t, worker.AccrueInterest(context.Background()))
+		})
+	}
+}
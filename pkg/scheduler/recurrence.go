@@ -0,0 +1,153 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRecurrence = errors.New("invalid recurrence")
+
+// Recurrence frequencies.
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+	Yearly  = "YEARLY"
+)
+
+// untilLayout is the UTC date-time form of UNTIL in an RRULE.
+const untilLayout = "20060102T150405Z"
+
+// Recurrence is the subset of an iCalendar RRULE that standing orders need:
+// FREQ, INTERVAL, COUNT and UNTIL, e.g. "FREQ=MONTHLY;INTERVAL=1;COUNT=12".
+// Occurrences fall on the start time every interval, with monthly and yearly
+// ones moved to the last day of shorter months.
+type Recurrence struct {
+	Freq     string
+	Interval int
+	// Count is the number of occurrences including the first, zero for no
+	// limit.
+	Count int
+	// Until is the last time an occurrence can fall on, zero for no limit.
+	Until time.Time
+}
+
+// ParseRecurrence reads an RRULE, with or without its "RRULE:" prefix.
+func ParseRecurrence(rule string) (Recurrence, error) {
+	r := Recurrence{Interval: 1}
+
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+
+	if rule == "" {
+		return r, fmt.Errorf("%w: empty rule", ErrInvalidRecurrence)
+	}
+
+	for _, part := range strings.Split(rule, ";") {
+		name, value, ok := strings.Cut(part, "=")
+
+		if !ok || value == "" {
+			return r, fmt.Errorf("%w: %q", ErrInvalidRecurrence, part)
+		}
+
+		var err error
+
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			r.Freq = strings.ToUpper(value)
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+
+			if err == nil && r.Interval < 1 {
+				err = errors.New("must be at least 1")
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+
+			if err == nil && r.Count < 1 {
+				err = errors.New("must be at least 1")
+			}
+		case "UNTIL":
+			r.Until, err = time.Parse(untilLayout, value)
+		default:
+			err = errors.New("unsupported")
+		}
+
+		if err != nil {
+			return r, fmt.Errorf("%w: %s: %s", ErrInvalidRecurrence, name, err)
+		}
+	}
+
+	switch r.Freq {
+	case Daily, Weekly, Monthly, Yearly:
+	case "":
+		return r, fmt.Errorf("%w: FREQ is required", ErrInvalidRecurrence)
+	default:
+		return r, fmt.Errorf("%w: unsupported FREQ %s", ErrInvalidRecurrence, r.Freq)
+	}
+
+	if r.Count > 0 && !r.Until.IsZero() {
+		return r, fmt.Errorf("%w: COUNT and UNTIL can't be used together", ErrInvalidRecurrence)
+	}
+
+	return r, nil
+}
+
+// String returns r as an RRULE without the "RRULE:" prefix.
+func (r Recurrence) String() string {
+	rule := fmt.Sprintf("FREQ=%s;INTERVAL=%d", r.Freq, r.Interval)
+
+	if r.Count > 0 {
+		rule += fmt.Sprintf(";COUNT=%d", r.Count)
+	}
+
+	if !r.Until.IsZero() {
+		rule += ";UNTIL=" + r.Until.UTC().Format(untilLayout)
+	}
+
+	return rule
+}
+
+// Occurrence returns the n-th occurrence after start, where start itself is
+// occurrence zero. It returns false once the recurrence has ended.
+func (r Recurrence) Occurrence(start time.Time, n int) (time.Time, bool) {
+	if r.Count > 0 && n >= r.Count {
+		return time.Time{}, false
+	}
+
+	steps := n * r.Interval
+
+	var next time.Time
+
+	switch r.Freq {
+	case Daily:
+		next = start.AddDate(0, 0, steps)
+	case Weekly:
+		next = start.AddDate(0, 0, 7*steps)
+	case Monthly:
+		next = addMonths(start, steps)
+	case Yearly:
+		next = addMonths(start, 12*steps)
+	default:
+		return time.Time{}, false
+	}
+
+	if !r.Until.IsZero() && next.After(r.Until) {
+		return time.Time{}, false
+	}
+
+	return next, true
+}
+
+// addMonths adds months to t, keeping its day of the month unless the month
+// is too short for it.
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+
+	return first.AddDate(0, 0, min(day, lastDay)-1)
+}
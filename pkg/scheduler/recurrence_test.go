@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	recurrence, err := ParseRecurrence("RRULE:FREQ=monthly;COUNT=12")
+	require.NoError(t, err)
+	require.Equal(t, Recurrence{Freq: Monthly, Interval: 1, Count: 12}, recurrence)
+	require.Equal(t, "FREQ=MONTHLY;INTERVAL=1;COUNT=12", recurrence.String())
+
+	recurrence, err = ParseRecurrence("FREQ=WEEKLY;INTERVAL=2;UNTIL=20241231T000000Z")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), recurrence.Until)
+	require.Equal(t, "FREQ=WEEKLY;INTERVAL=2;UNTIL=20241231T000000Z", recurrence.String())
+
+	for _, rule := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=-1",
+		"FREQ=DAILY;UNTIL=2024-12-31",
+		"FREQ=DAILY;COUNT=2;UNTIL=20241231T000000Z",
+		"FREQ=DAILY;BYDAY=MO",
+		"FREQ",
+	} {
+		_, err := ParseRecurrence(rule)
+		require.ErrorIs(t, err, ErrInvalidRecurrence, rule)
+	}
+}
+
+func TestOccurrence(t *testing.T) {
+	start := time.Date(2024, 1, 31, 9, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		rule     string
+		n        int
+		expected time.Time
+		ok       bool
+	}{
+		{"FREQ=DAILY", 0, start, true},
+		{"FREQ=DAILY;INTERVAL=3", 2, time.Date(2024, 2, 6, 9, 30, 0, 0, time.UTC), true},
+		{"FREQ=WEEKLY", 1, time.Date(2024, 2, 7, 9, 30, 0, 0, time.UTC), true},
+		// months too short for the 31st fall on their last day
+		{"FREQ=MONTHLY", 1, time.Date(2024, 2, 29, 9, 30, 0, 0, time.UTC), true},
+		{"FREQ=MONTHLY", 2, time.Date(2024, 3, 31, 9, 30, 0, 0, time.UTC), true},
+		{"FREQ=MONTHLY;INTERVAL=3", 1, time.Date(2024, 4, 30, 9, 30, 0, 0, time.UTC), true},
+		{"FREQ=YEARLY", 1, time.Date(2025, 1, 31, 9, 30, 0, 0, time.UTC), true},
+		{"FREQ=MONTHLY;COUNT=3", 2, time.Date(2024, 3, 31, 9, 30, 0, 0, time.UTC), true},
+		{"FREQ=MONTHLY;COUNT=3", 3, time.Time{}, false},
+		{"FREQ=DAILY;UNTIL=20240202T093000Z", 2, time.Date(2024, 2, 2, 9, 30, 0, 0, time.UTC), true},
+		{"FREQ=DAILY;UNTIL=20240202T093000Z", 3, time.Time{}, false},
+	}
+
+	for _, tc := range testCases {
+		recurrence, err := ParseRecurrence(tc.rule)
+		require.NoError(t, err)
+
+		next, ok := recurrence.Occurrence(start, tc.n)
+		require.Equal(t, tc.ok, ok, tc.rule)
+		require.Equal(t, tc.expected, next, tc.rule)
+	}
+}
@@ -0,0 +1,239 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/retry"
+	"github.com/lib/pq"
+)
+
+// Schedule statuses. A schedule is active until its last occurrence has run,
+// or failed when the last occurrence ran out of attempts.
+const (
+	StatusActive    = "active"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+)
+
+const (
+	// lease is how long a claimed schedule is kept from other workers. A
+	// worker that dies mid-run gives the schedule up when the lease ends.
+	lease = time.Minute
+	// maxAttempts is how many times an occurrence is tried before it's
+	// given up on.
+	maxAttempts = 5
+	// retryBackoff is the wait before the first retry, doubled after each
+	// failed attempt up to maxRetryBackoff.
+	retryBackoff    = time.Minute
+	maxRetryBackoff = time.Hour
+)
+
+// Worker runs scheduled transfers as they come due. Schedules are leased one
+// at a time with row locks that other workers skip, so a worker can run in
+// every server replica. Each occurrence is sent with an idempotency key, so an
+// occurrence that runs again after a worker died mid-run moves money at most
+// once. The worker also releases holds that have expired and, once a day,
+// accrues and posts interest.
+type Worker struct {
+	store    db.Store
+	interval time.Duration
+	now      func() time.Time
+	// interestDay is the last UTC day interest was brought up to date on.
+	interestDay time.Time
+}
+
+func NewWorker(store db.Store, interval time.Duration) *Worker {
+	return &Worker{store: store, interval: interval, now: time.Now}
+}
+
+// Start runs due schedules and expires holds every interval, and brings
+// interest up to date on the first tick of each day, until ctx is done.
+func (w *Worker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.RunDue(ctx); err != nil {
+			log.Println("unable to run scheduled transfers: ", err)
+		}
+
+		if _, err := w.ExpireHolds(ctx); err != nil {
+			log.Println("unable to expire holds: ", err)
+		}
+
+		if day := truncateDay(w.now()); !day.Equal(w.interestDay) {
+			if err := w.AccrueInterest(ctx); err != nil {
+				log.Println("unable to accrue interest: ", err)
+			} else {
+				w.interestDay = day
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue runs schedules until none are due and returns how many occurrences
+// were tried.
+func (w *Worker) RunDue(ctx context.Context) (int, error) {
+	ran := 0
+
+	for ctx.Err() == nil {
+		schedule, err := w.store.ClaimDueScheduledTransfer(ctx, int32(lease/time.Second))
+
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ran, nil
+			}
+			return ran, err
+		}
+
+		if err := w.run(ctx, schedule); err != nil {
+			return ran, fmt.Errorf("scheduled transfer %d: %w", schedule.ID, err)
+		}
+
+		ran++
+	}
+
+	return ran, ctx.Err()
+}
+
+// ExpireHolds releases holds until none have expired and returns how many
+// were released.
+func (w *Worker) ExpireHolds(ctx context.Context) (int, error) {
+	expired := 0
+
+	for ctx.Err() == nil {
+		result, err := w.store.ExpireHoldTx(ctx)
+
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return expired, nil
+			}
+			return expired, err
+		}
+
+		log.Printf("hold %d on account %d expired", result.Hold.ID, result.Hold.AccountID)
+
+		expired++
+	}
+
+	return expired, ctx.Err()
+}
+
+// IdempotencyKey identifies the transfer of one occurrence of a schedule.
+func IdempotencyKey(scheduleID int64, scheduledFor time.Time) string {
+	return fmt.Sprintf("scheduled:%d:%d", scheduleID, scheduledFor.Unix())
+}
+
+// run tries the next occurrence of schedule and records the outcome. Only
+// errors recording the outcome are returned; a failed transfer is retried.
+func (w *Worker) run(ctx context.Context, schedule db.ScheduledTransfer) error {
+	scheduledFor := schedule.NextRunAt.Time
+	attempt := schedule.Attempts + 1
+
+	transfer, transferErr := w.transfer(ctx, schedule, IdempotencyKey(schedule.ID, scheduledFor))
+
+	run := db.CreateScheduledTransferRunParams{
+		ScheduledTransferID: schedule.ID,
+		ScheduledFor:        scheduledFor,
+		Attempt:             attempt,
+	}
+
+	if transferErr != nil {
+		run.Error = sql.NullString{String: transferErr.Error(), Valid: true}
+	} else {
+		run.TransferID = sql.NullInt64{Int64: transfer.ID, Valid: true}
+	}
+
+	if _, err := w.store.CreateScheduledTransferRun(ctx, run); err != nil {
+		return err
+	}
+
+	if transferErr != nil && attempt < maxAttempts {
+		_, err := w.store.RetryScheduledTransfer(ctx, db.RetryScheduledTransferParams{
+			DueAt:        sql.NullTime{Time: w.now().Add(retry.Backoff(attempt, retryBackoff, maxRetryBackoff)), Valid: true},
+			LastError:    run.Error,
+			ID:           schedule.ID,
+			ScheduledFor: schedule.NextRunAt,
+		})
+
+		return err
+	}
+
+	arg := db.AdvanceScheduledTransferParams{
+		Status:       StatusCompleted,
+		LastError:    run.Error,
+		ID:           schedule.ID,
+		ScheduledFor: schedule.NextRunAt,
+	}
+
+	if next, ok := nextOccurrence(schedule); ok {
+		arg.Status = StatusActive
+		arg.NextRunAt = sql.NullTime{Time: next, Valid: true}
+	} else if transferErr != nil {
+		arg.Status = StatusFailed
+	}
+
+	if transferErr != nil {
+		log.Printf("giving up on scheduled transfer %d at %s after %d attempts: %s", schedule.ID, scheduledFor, attempt, transferErr)
+	}
+
+	_, err := w.store.AdvanceScheduledTransfer(ctx, arg)
+
+	return err
+}
+
+// transfer sends the occurrence under key, or returns the transfer already
+// sent under it by an earlier run.
+func (w *Worker) transfer(ctx context.Context, schedule db.ScheduledTransfer, key string) (db.Transfer, error) {
+	idempotencyKey := sql.NullString{String: key, Valid: true}
+
+	transfer, err := w.store.GetTransferByIdempotencyKey(ctx, idempotencyKey)
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return transfer, err
+	}
+
+	result, err := w.store.TransferTx(ctx, db.CreateTransferParams{
+		FromAccountID:  schedule.FromAccountID,
+		ToAccountID:    schedule.ToAccountID,
+		Amount:         schedule.Amount,
+		IdempotencyKey: idempotencyKey,
+	})
+
+	// another worker sent it since the lookup
+	var pqError *pq.Error
+
+	if errors.As(err, &pqError) && pqError.Code.Name() == "unique_violation" {
+		return w.store.GetTransferByIdempotencyKey(ctx, idempotencyKey)
+	}
+
+	return result.Transfer, err
+}
+
+// nextOccurrence returns the occurrence after the one schedule is running.
+func nextOccurrence(schedule db.ScheduledTransfer) (time.Time, bool) {
+	if !schedule.Recurrence.Valid {
+		return time.Time{}, false
+	}
+
+	recurrence, err := ParseRecurrence(schedule.Recurrence.String)
+
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return recurrence.Occurrence(schedule.StartAt, int(schedule.RunCount)+1)
+}
@@ -0,0 +1,239 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRunDue(t *testing.T) {
+	now := time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)
+	start := now.Add(-time.Minute)
+
+	oneOff := db.ScheduledTransfer{
+		ID:            1,
+		Owner:         "alfred",
+		FromAccountID: 10,
+		ToAccountID:   20,
+		Amount:        1050,
+		Currency:      "USD",
+		StartAt:       start,
+		Status:        StatusActive,
+		NextRunAt:     sql.NullTime{Time: start, Valid: true},
+		DueAt:         sql.NullTime{Time: start, Valid: true},
+	}
+
+	monthly := oneOff
+	monthly.Recurrence = sql.NullString{String: "FREQ=MONTHLY;INTERVAL=1", Valid: true}
+
+	key := sql.NullString{String: IdempotencyKey(oneOff.ID, start), Valid: true}
+
+	transferArg := db.CreateTransferParams{
+		FromAccountID:  oneOff.FromAccountID,
+		ToAccountID:    oneOff.ToAccountID,
+		Amount:         oneOff.Amount,
+		IdempotencyKey: key,
+	}
+
+	transfer := db.Transfer{ID: 99, FromAccountID: 10, ToAccountID: 20, Amount: 1050, IdempotencyKey: key}
+
+	succeeded := db.CreateScheduledTransferRunParams{
+		ScheduledTransferID: oneOff.ID,
+		ScheduledFor:        start,
+		Attempt:             1,
+		TransferID:          sql.NullInt64{Int64: transfer.ID, Valid: true},
+	}
+
+	claim := func(store *mockdb.MockStore, schedule db.ScheduledTransfer) {
+		gomock.InOrder(
+			store.EXPECT().ClaimDueScheduledTransfer(gomock.Any(), gomock.Eq(int32(60))).Times(1).Return(schedule, nil),
+			store.EXPECT().ClaimDueScheduledTransfer(gomock.Any(), gomock.Any()).Times(1).Return(db.ScheduledTransfer{}, sql.ErrNoRows),
+		)
+	}
+
+	testCases := []struct {
+		name          string
+		buildStub     func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, ran int, err error)
+	}{
+		{
+			name: "OneOff",
+			buildStub: func(store *mockdb.MockStore) {
+				claim(store, oneOff)
+				store.EXPECT().GetTransferByIdempotencyKey(gomock.Any(), gomock.Eq(key)).Times(1).Return(db.Transfer{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(transferArg)).Times(1).Return(db.TransferTxResult{Transfer: transfer}, nil)
+				store.EXPECT().CreateScheduledTransferRun(gomock.Any(), gomock.Eq(succeeded)).Times(1)
+				store.EXPECT().AdvanceScheduledTransfer(gomock.Any(), gomock.Eq(db.AdvanceScheduledTransferParams{
+					Status:       StatusCompleted,
+					ID:           oneOff.ID,
+					ScheduledFor: oneOff.NextRunAt,
+				})).Times(1).Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, ran int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, ran)
+			},
+		},
+		{
+			name: "Recurring",
+			buildStub: func(store *mockdb.MockStore) {
+				claim(store, monthly)
+				store.EXPECT().GetTransferByIdempotencyKey(gomock.Any(), gomock.Eq(key)).Times(1).Return(db.Transfer{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(transferArg)).Times(1).Return(db.TransferTxResult{Transfer: transfer}, nil)
+				store.EXPECT().CreateScheduledTransferRun(gomock.Any(), gomock.Eq(succeeded)).Times(1)
+				store.EXPECT().AdvanceScheduledTransfer(gomock.Any(), gomock.Eq(db.AdvanceScheduledTransferParams{
+					Status:       StatusActive,
+					NextRunAt:    sql.NullTime{Time: start.AddDate(0, 1, 0), Valid: true},
+					ID:           monthly.ID,
+					ScheduledFor: monthly.NextRunAt,
+				})).Times(1).Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, ran int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, ran)
+			},
+		},
+		{
+			name: "AlreadySent",
+			buildStub: func(store *mockdb.MockStore) {
+				claim(store, oneOff)
+				store.EXPECT().GetTransferByIdempotencyKey(gomock.Any(), gomock.Eq(key)).Times(1).Return(transfer, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateScheduledTransferRun(gomock.Any(), gomock.Eq(succeeded)).Times(1)
+				store.EXPECT().AdvanceScheduledTransfer(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, ran int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, ran)
+			},
+		},
+		{
+			name: "SentConcurrently",
+			buildStub: func(store *mockdb.MockStore) {
+				claim(store, oneOff)
+				gomock.InOrder(
+					store.EXPECT().GetTransferByIdempotencyKey(gomock.Any(), gomock.Eq(key)).Times(1).Return(db.Transfer{}, sql.ErrNoRows),
+					store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(transferArg)).Times(1).
+						Return(db.TransferTxResult{}, &pq.Error{Code: "23505"}),
+					store.EXPECT().GetTransferByIdempotencyKey(gomock.Any(), gomock.Eq(key)).Times(1).Return(transfer, nil),
+				)
+				store.EXPECT().CreateScheduledTransferRun(gomock.Any(), gomock.Eq(succeeded)).Times(1)
+				store.EXPECT().AdvanceScheduledTransfer(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, ran int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, ran)
+			},
+		},
+		{
+			name: "Retry",
+			buildStub: func(store *mockdb.MockStore) {
+				failed := oneOff
+				failed.Attempts = 2
+
+				lastError := sql.NullString{String: db.ErrInsufficientFunds.Error(), Valid: true}
+
+				claim(store, failed)
+				store.EXPECT().GetTransferByIdempotencyKey(gomock.Any(), gomock.Eq(key)).Times(1).Return(db.Transfer{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(transferArg)).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+				store.EXPECT().CreateScheduledTransferRun(gomock.Any(), gomock.Eq(db.CreateScheduledTransferRunParams{
+					ScheduledTransferID: oneOff.ID,
+					ScheduledFor:        start,
+					Attempt:             3,
+					Error:               lastError,
+				})).Times(1)
+				store.EXPECT().RetryScheduledTransfer(gomock.Any(), gomock.Eq(db.RetryScheduledTransferParams{
+					DueAt:        sql.NullTime{Time: now.Add(4 * time.Minute), Valid: true},
+					LastError:    lastError,
+					ID:           oneOff.ID,
+					ScheduledFor: oneOff.NextRunAt,
+				})).Times(1).Return(int64(1), nil)
+				store.EXPECT().AdvanceScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, ran int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, ran)
+			},
+		},
+		{
+			name: "GiveUp",
+			buildStub: func(store *mockdb.MockStore) {
+				failed := oneOff
+				failed.Attempts = maxAttempts - 1
+
+				claim(store, failed)
+				store.EXPECT().GetTransferByIdempotencyKey(gomock.Any(), gomock.Eq(key)).Times(1).Return(db.Transfer{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(transferArg)).Times(1).Return(db.TransferTxResult{}, db.ErrAccountClosed)
+				store.EXPECT().CreateScheduledTransferRun(gomock.Any(), gomock.Any()).Times(1)
+				store.EXPECT().RetryScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().AdvanceScheduledTransfer(gomock.Any(), gomock.Eq(db.AdvanceScheduledTransferParams{
+					Status:       StatusFailed,
+					LastError:    sql.NullString{String: db.ErrAccountClosed.Error(), Valid: true},
+					ID:           oneOff.ID,
+					ScheduledFor: oneOff.NextRunAt,
+				})).Times(1).Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, ran int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, ran)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().ClaimDueScheduledTransfer(gomock.Any(), gomock.Any()).Times(1).Return(db.ScheduledTransfer{}, sql.ErrConnDone)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, ran int, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+				require.Zero(t, ran)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			worker := NewWorker(store, time.Second)
+			worker.now = func() time.Time { return now }
+
+			ran, err := worker.RunDue(context.Background())
+
+			tc.checkResponse(t, ran, err)
+		})
+	}
+}
+
+func TestExpireHolds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	gomock.InOrder(
+		store.EXPECT().ExpireHoldTx(gomock.Any()).Times(2).Return(db.HoldTxResult{Hold: db.Hold{ID: 1, Status: db.HoldExpired}}, nil),
+		store.EXPECT().ExpireHoldTx(gomock.Any()).Times(1).Return(db.HoldTxResult{}, sql.ErrNoRows),
+	)
+
+	expired, err := NewWorker(store, time.Second).ExpireHolds(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, expired)
+
+	store.EXPECT().ExpireHoldTx(gomock.Any()).Times(1).Return(db.HoldTxResult{}, sql.ErrConnDone)
+
+	expired, err = NewWorker(store, time.Second).ExpireHolds(context.Background())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	require.Zero(t, expired)
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"os"
+
+	"github.com/aseerkt/go-simple-bank/pkg/token"
+)
+
+// Kinds of error the service returns. Each server maps them onto its own
+// status codes through errors.Is.
+var (
+	// ErrAccessDenied is the only error clients see for an authorization
+	// denial; the reason goes to the security log.
+	ErrAccessDenied = errors.New("access denied")
+	ErrNotFound     = errors.New("not found")
+	ErrInvalid      = errors.New("invalid argument")
+	ErrRejected     = errors.New("rejected")
+)
+
+// SecurityLog records authorization denials and privileged changes.
+var SecurityLog = log.New(os.Stderr, "[SECURITY] ", log.LstdFlags|log.LUTC)
+
+// Error is an error of one of the service kinds. Its message is that of the
+// error it wraps, and both the kind and the wrapped error match errors.Is.
+type Error struct {
+	Kind error
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+func newError(kind, err error) error {
+	return &Error{Kind: kind, Err: err}
+}
+
+// notFoundOr returns a not found error for sql.ErrNoRows and err as it is for
+// anything else.
+func notFoundOr(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return newError(ErrNotFound, err)
+	}
+
+	return err
+}
+
+// deny returns an access denied error whose message is the reason it was
+// denied, to be logged with LogDenial.
+func deny(reason string) error {
+	return newError(ErrAccessDenied, errors.New(reason))
+}
+
+// LogDenial writes an access denial to the security log. client is where the
+// call came from and call what it was, in the terms of the API it came
+// through.
+func LogDenial(username, client, call, reason string) {
+	SecurityLog.Printf("access denied: user=%q client=%s call=%q reason=%q", username, client, call, reason)
+}
+
+// HasRole reports whether payload carries one of roles.
+func HasRole(payload *token.Payload, roles ...string) bool {
+	for _, role := range roles {
+		if payload.Role == role {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/fx"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+)
+
+// TransferRequest is a transfer Username asked for, with an amount already
+// parsed in the currency they gave.
+type TransferRequest struct {
+	Username      string
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        money.Money
+}
+
+// PrepareTransfer checks that the user may send the transfer and turns it
+// into the parameters of TransferTx, quoting it when the accounts are in
+// different currencies. The sender's ownership is checked before anything
+// else about the account, so the errors don't tell strangers its currency.
+func PrepareTransfer(ctx context.Context, store db.Store, rates fx.RateProvider, spreadBps int32, req TransferRequest) (db.CreateTransferParams, error) {
+	var arg db.CreateTransferParams
+
+	fromAccount, err := store.GetAccount(ctx, req.FromAccountID)
+
+	if err != nil {
+		return arg, notFoundOr(err)
+	}
+
+	if fromAccount.Owner != req.Username {
+		return arg, deny(fmt.Sprintf("debit from account %d owned by %s", fromAccount.ID, fromAccount.Owner))
+	}
+
+	if fromAccount.Currency != req.Amount.Currency {
+		return arg, newError(ErrInvalid, fmt.Errorf("account %d currency mismatch: %s vs %s", fromAccount.ID, fromAccount.Currency, req.Amount.Currency))
+	}
+
+	toAccount, err := store.GetAccount(ctx, req.ToAccountID)
+
+	if err != nil {
+		return arg, notFoundOr(err)
+	}
+
+	if toAccount.Owner == db.SystemAccountOwner {
+		return arg, newError(ErrInvalid, fmt.Errorf("%w: account %d can't receive transfers", db.ErrSystemAccount, toAccount.ID))
+	}
+
+	arg = db.CreateTransferParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        req.Amount.Amount,
+	}
+
+	if toAccount.Currency != fromAccount.Currency {
+		quote, err := fx.QuoteAmount(ctx, rates, req.Amount, toAccount.Currency, spreadBps)
+
+		if err != nil {
+			if fx.IsUnquotable(err) {
+				return arg, newError(ErrRejected, err)
+			}
+			return arg, err
+		}
+
+		arg.ToAmount = quote.ToAmount
+		arg.FxRate = quote.Rate
+		arg.FxSpreadBps = quote.SpreadBps
+	}
+
+	return arg, nil
+}
+
+// Transfer runs TransferTx, returning a rejected error when the transfer was
+// refused rather than failed.
+func Transfer(ctx context.Context, store db.Store, arg db.CreateTransferParams) (db.TransferTxResult, error) {
+	result, err := store.TransferTx(ctx, arg)
+
+	if err != nil && db.IsTransferRejected(err) {
+		return result, newError(ErrRejected, err)
+	}
+
+	return result, err
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/aseerkt/go-simple-bank/pkg/currency"
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/fx"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/aseerkt/go-simple-bank/pkg/money"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPrepareTransfer(t *testing.T) {
+	currency.Register(
+		currency.Currency{Code: "USD", NumericCode: 840, MinorUnits: 2, Enabled: true},
+		currency.Currency{Code: "EUR", NumericCode: 978, MinorUnits: 2, Enabled: true},
+	)
+
+	account1 := db.Account{ID: 1, Owner: "alice", Currency: "USD"}
+	account2 := db.Account{ID: 2, Owner: "bob", Currency: "USD"}
+	euroAccount := db.Account{ID: 3, Owner: "bob", Currency: "EUR"}
+	cashAccount := db.Account{ID: 4, Owner: db.SystemAccountOwner, Currency: "USD"}
+
+	rates := fx.StaticProvider{"USD/EUR": 92_000_000}
+
+	testCases := []struct {
+		name      string
+		req       TransferRequest
+		buildStub func(store *mockdb.MockStore)
+		check     func(t *testing.T, arg db.CreateTransferParams, err error)
+	}{
+		{
+			name: "Ok",
+			req:  TransferRequest{Username: "alice", FromAccountID: 1, ToAccountID: 2, Amount: money.New(1050, "USD")},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+			},
+			check: func(t *testing.T, arg db.CreateTransferParams, err error) {
+				require.NoError(t, err)
+				require.Equal(t, db.CreateTransferParams{FromAccountID: 1, ToAccountID: 2, Amount: 1050}, arg)
+			},
+		},
+		{
+			name: "CrossCurrency",
+			req:  TransferRequest{Username: "alice", FromAccountID: 1, ToAccountID: 3, Amount: money.New(10_000, "USD")},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(euroAccount.ID)).Times(1).Return(euroAccount, nil)
+			},
+			check: func(t *testing.T, arg db.CreateTransferParams, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(9_200), arg.ToAmount)
+				require.Equal(t, int64(92_000_000), arg.FxRate)
+			},
+		},
+		{
+			name: "NotOwner",
+			req:  TransferRequest{Username: "mallory", FromAccountID: 1, ToAccountID: 2, Amount: money.New(1050, "EUR")},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
+			},
+			check: func(t *testing.T, arg db.CreateTransferParams, err error) {
+				// denied before the currency is compared
+				require.ErrorIs(t, err, ErrAccessDenied)
+				require.NotContains(t, err.Error(), "currency")
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			req:  TransferRequest{Username: "alice", FromAccountID: 1, ToAccountID: 2, Amount: money.New(1050, "EUR")},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
+			},
+			check: func(t *testing.T, arg db.CreateTransferParams, err error) {
+				require.ErrorIs(t, err, ErrInvalid)
+			},
+		},
+		{
+			name: "NotFound",
+			req:  TransferRequest{Username: "alice", FromAccountID: 1, ToAccountID: 2, Amount: money.New(1050, "USD")},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			check: func(t *testing.T, arg db.CreateTransferParams, err error) {
+				require.ErrorIs(t, err, ErrNotFound)
+				require.ErrorIs(t, err, sql.ErrNoRows)
+			},
+		},
+		{
+			name: "SystemAccount",
+			req:  TransferRequest{Username: "alice", FromAccountID: 1, ToAccountID: 4, Amount: money.New(1050, "USD")},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(cashAccount.ID)).Times(1).Return(cashAccount, nil)
+			},
+			check: func(t *testing.T, arg db.CreateTransferParams, err error) {
+				require.ErrorIs(t, err, ErrInvalid)
+				require.ErrorIs(t, err, db.ErrSystemAccount)
+			},
+		},
+		{
+			name: "InternalError",
+			req:  TransferRequest{Username: "alice", FromAccountID: 1, ToAccountID: 2, Amount: money.New(1050, "USD")},
+			buildStub: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, sql.ErrConnDone)
+			},
+			check: func(t *testing.T, arg db.CreateTransferParams, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+				require.NotErrorIs(t, err, ErrNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStub(store)
+
+			arg, err := PrepareTransfer(context.Background(), store, rates, 0, tc.req)
+			tc.check(t, arg, err)
+		})
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	arg := db.CreateTransferParams{FromAccountID: 1, ToAccountID: 2, Amount: 1050}
+
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+
+	_, err := Transfer(context.Background(), store, arg)
+	require.ErrorIs(t, err, ErrRejected)
+	require.ErrorIs(t, err, db.ErrInsufficientFunds)
+	require.Equal(t, db.ErrInsufficientFunds.Error(), err.Error())
+
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{}, sql.ErrConnDone)
+
+	_, err = Transfer(context.Background(), store, arg)
+	require.NotErrorIs(t, err, ErrRejected)
+}
@@ -0,0 +1,93 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+const minRSAKeyBits = 2048
+
+// JSONWebKey is the public half of a signing key as described in RFC 7517.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// PublicKeyPublisher is implemented by makers that sign tokens with an
+// asymmetric key, so other services can verify them without the secret.
+type PublicKeyPublisher interface {
+	PublicKeys() (JSONWebKeySet, error)
+}
+
+func newJSONWebKey(kid string, alg string, publicKey crypto.PublicKey) (JSONWebKey, error) {
+	encode := base64.RawURLEncoding.EncodeToString
+
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		return JSONWebKey{
+			KeyType:   "OKP",
+			KeyID:     kid,
+			Use:       "sig",
+			Algorithm: alg,
+			Curve:     "Ed25519",
+			X:         encode(key),
+		}, nil
+	case *rsa.PublicKey:
+		return JSONWebKey{
+			KeyType:   "RSA",
+			KeyID:     kid,
+			Use:       "sig",
+			Algorithm: alg,
+			N:         encode(key.N.Bytes()),
+			E:         encode(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	}
+
+	return JSONWebKey{}, fmt.Errorf("unsupported public key type %T", publicKey)
+}
+
+// parsePrivateKey expects the base64 encoding of a PKCS #8 DER private key,
+// i.e. the body of a "BEGIN PRIVATE KEY" PEM block on a single line.
+func parsePrivateKey(material string) (crypto.Signer, error) {
+	der, err := base64.StdEncoding.DecodeString(material)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key encoding: %w", err)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	switch key := key.(type) {
+	case ed25519.PrivateKey:
+		return key, nil
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("invalid rsa key size: minimum %d bits required", minRSAKeyBits)
+		}
+		return key, nil
+	case *ecdsa.PrivateKey:
+		return nil, fmt.Errorf("ecdsa keys are not supported")
+	}
+
+	return nil, fmt.Errorf("unsupported private key type %T", key)
+}
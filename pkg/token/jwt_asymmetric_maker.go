@@ -0,0 +1,131 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTAsymmetricMaker signs JWTs with an Ed25519 (EdDSA) or RSA (RS256)
+// private key, the public keys are published through PublicKeys.
+type JWTAsymmetricMaker struct {
+	method jwt.SigningMethod
+	keys   *keyring[crypto.Signer]
+}
+
+func NewJWTAsymmetricMaker(method jwt.SigningMethod, keySet KeySet) (Maker, error) {
+	var parse func(string) (crypto.Signer, error)
+
+	switch method {
+	case jwt.SigningMethodEdDSA:
+		parse = func(material string) (crypto.Signer, error) {
+			key, err := parsePrivateKey(material)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := key.(ed25519.PrivateKey); !ok {
+				return nil, fmt.Errorf("%s requires an ed25519 key", method.Alg())
+			}
+			return key, nil
+		}
+	case jwt.SigningMethodRS256:
+		parse = func(material string) (crypto.Signer, error) {
+			key, err := parsePrivateKey(material)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := key.(*rsa.PrivateKey); !ok {
+				return nil, fmt.Errorf("%s requires an rsa key", method.Alg())
+			}
+			return key, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported signing method: %s", method.Alg())
+	}
+
+	keys, err := newKeyring(keySet, parse)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &JWTAsymmetricMaker{
+		method: method,
+		keys:   keys,
+	}, nil
+}
+
+func (jm *JWTAsymmetricMaker) CreateToken(username string, role string, tokenType string, duration time.Duration) (string, *Payload, error) {
+	payload := NewPayload(username, role, tokenType, duration)
+
+	kid, privateKey := jm.keys.primary()
+
+	jwt := jwt.NewWithClaims(jm.method, payload)
+	jwt.Header["kid"] = kid
+
+	token, err := jwt.SignedString(privateKey)
+
+	return token, payload, err
+}
+
+func (jm *JWTAsymmetricMaker) VerifyToken(token string) (*Payload, error) {
+	var keyFunc jwt.Keyfunc = func(t *jwt.Token) (interface{}, error) {
+
+		if t.Method.Alg() != jm.method.Alg() {
+			return nil, jwt.ErrSignatureInvalid
+		}
+
+		kid, ok := t.Header["kid"].(string)
+
+		if !ok {
+			return nil, ErrUnknownKeyID
+		}
+
+		privateKey, err := jm.keys.get(kid)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return privateKey.Public(), nil
+	}
+
+	jwt, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if payload, ok := jwt.Claims.(*Payload); !ok {
+		return nil, errors.New("invalid token")
+	} else {
+		return payload, nil
+	}
+}
+
+func (jm *JWTAsymmetricMaker) Rotate(keySet KeySet) error {
+	return jm.keys.rotate(keySet)
+}
+
+func (jm *JWTAsymmetricMaker) PublicKeys() (JSONWebKeySet, error) {
+	kids, privateKeys := jm.keys.all()
+
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(kids))}
+
+	for i, kid := range kids {
+		jwk, err := newJSONWebKey(kid, jm.method.Alg(), privateKeys[i].Public())
+
+		if err != nil {
+			return set, err
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set, nil
+}
@@ -0,0 +1,109 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePrivateKey(t *testing.T, key crypto.Signer) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(der)
+}
+
+func newEd25519Key(t *testing.T) string {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return encodePrivateKey(t, key)
+}
+
+func newRSAKey(t *testing.T, bits int) string {
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+	return encodePrivateKey(t, key)
+}
+
+func TestNewJWTAsymmetricMaker(t *testing.T) {
+	testCases := []struct {
+		name    string
+		method  jwt.SigningMethod
+		key     string
+		wantErr bool
+	}{
+		{name: "EdDSA", method: jwt.SigningMethodEdDSA, key: newEd25519Key(t)},
+		{name: "RS256", method: jwt.SigningMethodRS256, key: newRSAKey(t, 2048)},
+		{name: "EdDSAWithRSAKey", method: jwt.SigningMethodEdDSA, key: newRSAKey(t, 2048), wantErr: true},
+		{name: "RS256WithEd25519Key", method: jwt.SigningMethodRS256, key: newEd25519Key(t), wantErr: true},
+		{name: "WeakRSAKey", method: jwt.SigningMethodRS256, key: newRSAKey(t, 1024), wantErr: true},
+		{name: "NotBase64", method: jwt.SigningMethodEdDSA, key: "not a key!", wantErr: true},
+		{name: "UnsupportedMethod", method: jwt.SigningMethodHS256, key: newEd25519Key(t), wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			maker, err := NewJWTAsymmetricMaker(tc.method, NewKeySet("k1", tc.key))
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Nil(t, maker)
+				return
+			}
+
+			require.NoError(t, err)
+
+			token, payload, err := maker.CreateToken("alfred", "customer", AccessToken, time.Hour)
+			require.NoError(t, err)
+
+			verified, err := maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, payload.ID, verified.ID)
+			require.Equal(t, "alfred", verified.Username)
+		})
+	}
+}
+
+func TestJWTAsymmetricMakerPublicKeys(t *testing.T) {
+	keySet := KeySet{
+		PrimaryID: "k2",
+		Keys:      map[string]string{"k1": newEd25519Key(t), "k2": newEd25519Key(t)},
+	}
+
+	maker, err := NewJWTAsymmetricMaker(jwt.SigningMethodEdDSA, keySet)
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken("alfred", "customer", AccessToken, time.Hour)
+	require.NoError(t, err)
+
+	jwks, err := maker.(PublicKeyPublisher).PublicKeys()
+	require.NoError(t, err)
+	require.Len(t, jwks.Keys, 2)
+
+	for _, jwk := range jwks.Keys {
+		require.Equal(t, "OKP", jwk.KeyType)
+		require.Equal(t, "Ed25519", jwk.Curve)
+		require.Equal(t, "EdDSA", jwk.Algorithm)
+	}
+
+	// a verifier holding nothing but the published key for the token's kid
+	parsed, err := jwt.ParseWithClaims(token, &Payload{}, func(tk *jwt.Token) (interface{}, error) {
+		for _, jwk := range jwks.Keys {
+			if jwk.KeyID == tk.Header["kid"] {
+				x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+				return ed25519.PublicKey(x), err
+			}
+		}
+		return nil, ErrUnknownKeyID
+	}, jwt.WithValidMethods([]string{"EdDSA"}))
+
+	require.NoError(t, err)
+	require.Equal(t, "alfred", parsed.Claims.(*Payload).Username)
+}
@@ -11,26 +11,40 @@ import (
 const minSecretKeyLength = 12
 
 type JWTMaker struct {
-	secretKey string
+	keys *keyring[[]byte]
 }
 
-func NewJWTMaker(secretKey string) (Maker, error) {
+func parseJWTSecretKey(secretKey string) ([]byte, error) {
 	if len(secretKey) < minSecretKeyLength {
 		return nil, fmt.Errorf("invalid secret key size: minimum %d characters required", minSecretKeyLength)
 	}
 
+	return []byte(secretKey), nil
+}
+
+func NewJWTMaker(keySet KeySet) (Maker, error) {
+	keys, err := newKeyring(keySet, parseJWTSecretKey)
+
+	if err != nil {
+		return nil, err
+	}
+
 	return &JWTMaker{
-		secretKey: secretKey,
+		keys: keys,
 	}, nil
 }
 
-func (jm *JWTMaker) CreateToken(username string, duration time.Duration) (string, error) {
-	payload := NewPayload(username, duration)
+func (jm *JWTMaker) CreateToken(username string, role string, tokenType string, duration time.Duration) (string, *Payload, error) {
+	payload := NewPayload(username, role, tokenType, duration)
+
+	kid, secretKey := jm.keys.primary()
 
 	jwt := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
+	jwt.Header["kid"] = kid
 
-	return jwt.SignedString([]byte(jm.secretKey))
+	token, err := jwt.SignedString(secretKey)
 
+	return token, payload, err
 }
 
 func (jm *JWTMaker) VerifyToken(token string) (*Payload, error) {
@@ -40,7 +54,13 @@ func (jm *JWTMaker) VerifyToken(token string) (*Payload, error) {
 			return nil, jwt.ErrSignatureInvalid
 		}
 
-		return []byte(jm.secretKey), nil
+		kid, ok := t.Header["kid"].(string)
+
+		if !ok {
+			return nil, ErrUnknownKeyID
+		}
+
+		return jm.keys.get(kid)
 	}
 
 	jwt, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc)
@@ -56,3 +76,7 @@ func (jm *JWTMaker) VerifyToken(token string) (*Payload, error) {
 	}
 
 }
+
+func (jm *JWTMaker) Rotate(keySet KeySet) error {
+	return jm.keys.rotate(keySet)
+}
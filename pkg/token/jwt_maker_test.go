@@ -38,28 +38,30 @@ func TestNewJWTMaker(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			tc.checkResults(NewJWTMaker(tc.secretKey))
+			tc.checkResults(NewJWTMaker(NewKeySet("k1", tc.secretKey)))
 		})
 	}
 
 }
 
 func TestCreateToken(t *testing.T) {
-	jm, err := NewJWTMaker("thelongestsecretkeythatyouwillneverimagine")
+	jm, err := NewJWTMaker(NewKeySet("k1", "thelongestsecretkeythatyouwillneverimagine"))
 
 	require.NoError(t, err)
 
-	token, err := jm.CreateToken("armageddon", 12*time.Hour)
+	token, payload, err := jm.CreateToken("armageddon", "customer", AccessToken, 12*time.Hour)
 
 	require.NoError(t, err)
 	require.NotZero(t, token)
+	require.Equal(t, "armageddon", payload.Username)
+	require.NotZero(t, payload.ID)
 }
 
 func TestVerifyToken(t *testing.T) {
 
 	secretKey := "thesecretjustforverifyingsomeshit"
 
-	jm, err := NewJWTMaker(secretKey)
+	jm, err := NewJWTMaker(NewKeySet("k1", secretKey))
 
 	require.NoError(t, err)
 
@@ -71,7 +73,7 @@ func TestVerifyToken(t *testing.T) {
 		{
 			name: "Ok",
 			createToken: func() string {
-				token, err := jm.CreateToken("alfred", 12*time.Hour)
+				token, _, err := jm.CreateToken("alfred", "customer", AccessToken, 12*time.Hour)
 
 				require.NoError(t, err)
 				return token
@@ -79,6 +81,8 @@ func TestVerifyToken(t *testing.T) {
 			checkResults: func(p *Payload, err error) {
 				require.NoError(t, err)
 				require.Equal(t, p.Username, "alfred")
+				require.Equal(t, p.Role, "customer")
+				require.Equal(t, p.TokenType, AccessToken)
 				require.NotZero(t, p.ID)
 				require.NotZero(t, p.IssuedAt)
 				require.NotZero(t, p.ExpiresAt)
@@ -111,3 +115,18 @@ func TestVerifyToken(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyTokenType(t *testing.T) {
+	jm, err := NewJWTMaker(NewKeySet("k1", "thesecretjustforverifyingsomeshit"))
+	require.NoError(t, err)
+
+	refreshToken, _, err := jm.CreateToken("alfred", "customer", RefreshToken, time.Hour)
+	require.NoError(t, err)
+
+	payload, err := VerifyTokenType(jm, refreshToken, RefreshToken)
+	require.NoError(t, err)
+	require.Equal(t, RefreshToken, payload.TokenType)
+
+	_, err = VerifyTokenType(jm, refreshToken, AccessToken)
+	require.ErrorIs(t, err, ErrTokenType)
+}
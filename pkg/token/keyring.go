@@ -0,0 +1,144 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var ErrUnknownKeyID = errors.New("token signed with unknown key id")
+
+// KeySet is the key material a Maker is configured with. Tokens are created
+// with the primary key and verified with whichever key their kid points at.
+type KeySet struct {
+	PrimaryID string
+	Keys      map[string]string
+}
+
+func NewKeySet(primaryID string, primaryKey string) KeySet {
+	return KeySet{
+		PrimaryID: primaryID,
+		Keys:      map[string]string{primaryID: primaryKey},
+	}
+}
+
+// ParseKeySet builds a KeySet from the primary key and a comma separated list
+// of "kid:key" pairs that are still accepted for verification.
+func ParseKeySet(primaryID string, primaryKey string, verificationKeys string) (KeySet, error) {
+	keys := NewKeySet(primaryID, primaryKey)
+
+	for _, pair := range strings.Split(verificationKeys, ",") {
+		pair = strings.TrimSpace(pair)
+
+		if pair == "" {
+			continue
+		}
+
+		kid, key, ok := strings.Cut(pair, ":")
+
+		if !ok || kid == "" || key == "" {
+			return KeySet{}, fmt.Errorf("invalid verification key %q: expected kid:key", pair)
+		}
+
+		if _, exists := keys.Keys[kid]; exists {
+			return KeySet{}, fmt.Errorf("duplicate key id %q", kid)
+		}
+
+		keys.Keys[kid] = key
+	}
+
+	return keys, nil
+}
+
+// keyring holds the parsed keys of a KeySet and lets them be swapped while
+// tokens are being created and verified.
+type keyring[K any] struct {
+	mu        sync.RWMutex
+	parse     func(string) (K, error)
+	primaryID string
+	keys      map[string]K
+}
+
+func newKeyring[K any](keySet KeySet, parse func(string) (K, error)) (*keyring[K], error) {
+	kr := &keyring[K]{parse: parse}
+
+	if err := kr.rotate(keySet); err != nil {
+		return nil, err
+	}
+
+	return kr, nil
+}
+
+func (kr *keyring[K]) rotate(keySet KeySet) error {
+	if keySet.PrimaryID == "" {
+		return errors.New("primary key id is required")
+	}
+
+	if _, ok := keySet.Keys[keySet.PrimaryID]; !ok {
+		return fmt.Errorf("primary key %q not found in key set", keySet.PrimaryID)
+	}
+
+	keys := make(map[string]K, len(keySet.Keys))
+
+	for kid, material := range keySet.Keys {
+		key, err := kr.parse(material)
+
+		if err != nil {
+			return fmt.Errorf("key %q: %w", kid, err)
+		}
+
+		keys[kid] = key
+	}
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	kr.primaryID = keySet.PrimaryID
+	kr.keys = keys
+
+	return nil
+}
+
+func (kr *keyring[K]) primary() (string, K) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	return kr.primaryID, kr.keys[kr.primaryID]
+}
+
+func (kr *keyring[K]) get(kid string) (K, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	key, ok := kr.keys[kid]
+
+	if !ok {
+		return key, ErrUnknownKeyID
+	}
+
+	return key, nil
+}
+
+// all returns every key in the ring ordered by key id.
+func (kr *keyring[K]) all() ([]string, []K) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	kids := make([]string, 0, len(kr.keys))
+
+	for kid := range kr.keys {
+		kids = append(kids, kid)
+	}
+
+	sort.Strings(kids)
+
+	keys := make([]K, 0, len(kids))
+
+	for _, kid := range kids {
+		keys = append(keys, kr.keys[kid])
+	}
+
+	return kids, keys
+}
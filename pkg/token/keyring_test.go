@@ -0,0 +1,82 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeySet(t *testing.T) {
+	keySet, err := ParseKeySet("k2", "secret2", "k1:secret1, k0:secret0")
+	require.NoError(t, err)
+	require.Equal(t, "k2", keySet.PrimaryID)
+	require.Equal(t, map[string]string{"k2": "secret2", "k1": "secret1", "k0": "secret0"}, keySet.Keys)
+
+	keySet, err = ParseKeySet("k1", "secret1", "")
+	require.NoError(t, err)
+	require.Equal(t, NewKeySet("k1", "secret1"), keySet)
+
+	_, err = ParseKeySet("k1", "secret1", "k0")
+	require.Error(t, err)
+
+	_, err = ParseKeySet("k1", "secret1", "k1:secret0")
+	require.Error(t, err)
+}
+
+func TestKeyRotation(t *testing.T) {
+	keyGenerators := map[string]func() string{
+		TypeJWT: func() string {
+			return paseto.NewV4SymmetricKey().ExportHex()[:32]
+		},
+		TypePASETO: func() string {
+			return paseto.NewV4SymmetricKey().ExportHex()
+		},
+	}
+
+	for tokenType, newKey := range keyGenerators {
+		t.Run(tokenType, func(t *testing.T) {
+			key1, key2 := newKey(), newKey()
+
+			maker, err := NewMaker(tokenType, NewKeySet("k1", key1), "implicit")
+			require.NoError(t, err)
+
+			oldToken, _, err := maker.CreateToken("alfred", "customer", AccessToken, time.Hour)
+			require.NoError(t, err)
+
+			rotator, ok := maker.(KeyRotator)
+			require.True(t, ok)
+
+			// k2 becomes primary while k1 is still accepted
+			err = rotator.Rotate(KeySet{PrimaryID: "k2", Keys: map[string]string{"k1": key1, "k2": key2}})
+			require.NoError(t, err)
+
+			newToken, _, err := maker.CreateToken("alfred", "customer", AccessToken, time.Hour)
+			require.NoError(t, err)
+
+			_, err = maker.VerifyToken(oldToken)
+			require.NoError(t, err)
+
+			_, err = maker.VerifyToken(newToken)
+			require.NoError(t, err)
+
+			// k1 retired
+			err = rotator.Rotate(NewKeySet("k2", key2))
+			require.NoError(t, err)
+
+			_, err = maker.VerifyToken(oldToken)
+			require.ErrorIs(t, err, ErrUnknownKeyID)
+
+			_, err = maker.VerifyToken(newToken)
+			require.NoError(t, err)
+
+			// a broken key set leaves the current keys in place
+			err = rotator.Rotate(KeySet{PrimaryID: "k3", Keys: map[string]string{"k2": key2}})
+			require.Error(t, err)
+
+			_, err = maker.VerifyToken(newToken)
+			require.NoError(t, err)
+		})
+	}
+}
@@ -2,15 +2,69 @@ package token
 
 import (
 	"errors"
+	"fmt"
 	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	TypeJWT          = "jwt"
+	TypeJWTEdDSA     = "jwt-eddsa"
+	TypeJWTRS256     = "jwt-rs256"
+	TypePASETO       = "paseto"
+	TypePASETOPublic = "paseto-public"
 )
 
 var (
 	ErrInvalidToken = errors.New("token invalid")
 	ErrExpiredToken = errors.New("token expired")
+	ErrTokenType    = errors.New("wrong token type")
 )
 
 type Maker interface {
-	CreateToken(username string, duration time.Duration) (string, error)
+	CreateToken(username string, role string, tokenType string, duration time.Duration) (string, *Payload, error)
 	VerifyToken(token string) (*Payload, error)
 }
+
+// KeyRotator is implemented by makers whose keys can be replaced at runtime.
+type KeyRotator interface {
+	Rotate(keySet KeySet) error
+}
+
+// NewMaker builds the Maker for the configured token type. Symmetric types
+// take secrets as keys, asymmetric ones take base64 PKCS #8 private keys. The
+// implicit assertion is only used by PASETO tokens.
+func NewMaker(tokenType string, keySet KeySet, implicit string) (Maker, error) {
+	switch tokenType {
+	case TypeJWT:
+		return NewJWTMaker(keySet)
+	case TypeJWTEdDSA:
+		return NewJWTAsymmetricMaker(jwt.SigningMethodEdDSA, keySet)
+	case TypeJWTRS256:
+		return NewJWTAsymmetricMaker(jwt.SigningMethodRS256, keySet)
+	case TypePASETO:
+		return NewPasetoMaker(keySet, implicit)
+	case TypePASETOPublic:
+		return NewPasetoPublicMaker(keySet, implicit)
+	}
+
+	return nil, fmt.Errorf("unsupported token type: %q", tokenType)
+}
+
+// VerifyTokenType verifies token and checks that it was issued as tokenType,
+// so a refresh token can't stand in for an access token or the other way
+// round.
+func VerifyTokenType(maker Maker, token string, tokenType string) (*Payload, error) {
+	payload, err := maker.VerifyToken(token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if payload.TokenType != tokenType {
+		return nil, ErrTokenType
+	}
+
+	return payload, nil
+}
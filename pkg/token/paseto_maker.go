@@ -1,38 +1,76 @@
 package token
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"aidanwoods.dev/go-paseto"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
 )
 
 type PasetoMaker struct {
-	symmetricKey paseto.V4SymmetricKey
-	implicit     []byte
+	keys     *keyring[paseto.V4SymmetricKey]
+	implicit []byte
 }
 
-func NewPasetoMaker() Maker {
-	return &PasetoMaker{paseto.NewV4SymmetricKey(), []byte("some_implicit")}
+// pasetoFooter is stored unencrypted alongside the token so the key it was
+// encrypted with can be found before decrypting.
+type pasetoFooter struct {
+	KeyID string `json:"kid"`
 }
 
-func (pm *PasetoMaker) CreateToken(username string, duration time.Duration) (string, error) {
-	token := paseto.NewToken()
+// parsePasetoSymmetricKey expects the hex encoding of a 32 byte v4.local key.
+func parsePasetoSymmetricKey(symmetricKey string) (paseto.V4SymmetricKey, error) {
+	key, err := paseto.V4SymmetricKeyFromHex(symmetricKey)
+
+	if err != nil {
+		return key, fmt.Errorf("invalid paseto symmetric key: %w", err)
+	}
 
-	token.SetString("id", uuid.New().String())
-	token.SetString("username", username)
-	token.SetIssuedAt(time.Now())
-	token.SetNotBefore(time.Now())
-	token.SetExpiration(time.Now().Add(duration))
+	return key, nil
+}
+
+func NewPasetoMaker(keySet KeySet, implicit string) (Maker, error) {
+	keys, err := newKeyring(keySet, parsePasetoSymmetricKey)
+
+	if err != nil {
+		return nil, err
+	}
 
-	return token.V4Encrypt(pm.symmetricKey, pm.implicit), nil
+	return &PasetoMaker{keys, []byte(implicit)}, nil
+}
+
+func (pm *PasetoMaker) CreateToken(username string, role string, tokenType string, duration time.Duration) (string, *Payload, error) {
+	payload := NewPayload(username, role, tokenType, duration)
+
+	kid, symmetricKey := pm.keys.primary()
+
+	token, err := newPasetoToken(payload, kid)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token.V4Encrypt(symmetricKey, pm.implicit), payload, nil
 }
 
 func (pm *PasetoMaker) VerifyToken(token string) (*Payload, error) {
-	parser := paseto.NewParser()
-	parser.AddRule(paseto.NotExpired())
-	parsedToken, err := parser.ParseV4Local(pm.symmetricKey, token, pm.implicit)
+	parser := newPasetoParser()
+
+	kid, err := getPasetoKeyID(parser, paseto.V4Local, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	symmetricKey, err := pm.keys.get(kid)
+
+	if err != nil {
+		return nil, err
+	}
+
+	parsedToken, err := parser.ParseV4Local(symmetricKey, token, pm.implicit)
 
 	if err != nil {
 		return nil, err
@@ -46,6 +84,53 @@ func (pm *PasetoMaker) VerifyToken(token string) (*Payload, error) {
 	return payload, nil
 }
 
+func (pm *PasetoMaker) Rotate(keySet KeySet) error {
+	return pm.keys.rotate(keySet)
+}
+
+func newPasetoParser() paseto.Parser {
+	parser := paseto.NewParser()
+	parser.AddRule(paseto.NotExpired())
+	return parser
+}
+
+func newPasetoToken(payload *Payload, kid string) (*paseto.Token, error) {
+	footer, err := json.Marshal(pasetoFooter{KeyID: kid})
+
+	if err != nil {
+		return nil, err
+	}
+
+	token := paseto.NewToken()
+
+	token.SetString("id", payload.ID)
+	token.SetString("username", payload.Username)
+	token.SetString("role", payload.Role)
+	token.SetString("token_type", payload.TokenType)
+	token.SetIssuedAt(payload.IssuedAt.Time)
+	token.SetNotBefore(payload.IssuedAt.Time)
+	token.SetExpiration(payload.ExpiresAt.Time)
+	token.SetFooter(footer)
+
+	return &token, nil
+}
+
+func getPasetoKeyID(parser paseto.Parser, protocol paseto.Protocol, token string) (string, error) {
+	var footer pasetoFooter
+
+	rawFooter, err := parser.UnsafeParseFooter(protocol, token)
+
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+
+	if err := json.Unmarshal(rawFooter, &footer); err != nil {
+		return "", ErrInvalidToken
+	}
+
+	return footer.KeyID, nil
+}
+
 func getPayloadFromToken(token *paseto.Token) (*Payload, error) {
 	id, err := token.GetString("id")
 	if err != nil {
@@ -55,6 +140,14 @@ func getPayloadFromToken(token *paseto.Token) (*Payload, error) {
 	if err != nil {
 		return nil, ErrInvalidToken
 	}
+	role, err := token.GetString("role")
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	tokenType, err := token.GetString("token_type")
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
 	issuedAt, err := token.GetIssuedAt()
 	if err != nil {
 		return nil, ErrInvalidToken
@@ -65,7 +158,9 @@ func getPayloadFromToken(token *paseto.Token) (*Payload, error) {
 	}
 
 	return &Payload{
-		Username: username,
+		Username:  username,
+		Role:      role,
+		TokenType: tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        id,
 			IssuedAt:  jwt.NewNumericDate(issuedAt),
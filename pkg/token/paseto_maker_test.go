@@ -0,0 +1,90 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPasetoMaker(t *testing.T) {
+	testCases := []struct {
+		name         string
+		symmetricKey string
+		checkResults func(Maker, error)
+	}{
+		{
+			name:         "Ok",
+			symmetricKey: paseto.NewV4SymmetricKey().ExportHex(),
+			checkResults: func(m Maker, e error) {
+				require.NoError(t, e)
+				require.NotEmpty(t, m)
+			},
+		},
+		{
+			name:         "NotHex",
+			symmetricKey: "thisisnotahexencodedkeyatall",
+			checkResults: func(m Maker, e error) {
+				require.Error(t, e)
+				require.Empty(t, m)
+			},
+		},
+		{
+			name:         "InvalidKeySize",
+			symmetricKey: "deadbeef",
+			checkResults: func(m Maker, e error) {
+				require.Error(t, e)
+				require.Empty(t, m)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.checkResults(NewPasetoMaker(NewKeySet("k1", tc.symmetricKey), "implicit"))
+		})
+	}
+}
+
+func TestPasetoMakerPersistentKey(t *testing.T) {
+	symmetricKey := paseto.NewV4SymmetricKey().ExportHex()
+
+	pm1, err := NewPasetoMaker(NewKeySet("k1", symmetricKey), "implicit")
+	require.NoError(t, err)
+
+	token, payload, err := pm1.CreateToken("alfred", "customer", AccessToken, time.Hour)
+	require.NoError(t, err)
+
+	// a second maker built from the same configuration, as after a restart or
+	// on another replica, must accept the token
+	pm2, err := NewPasetoMaker(NewKeySet("k1", symmetricKey), "implicit")
+	require.NoError(t, err)
+
+	verified, err := pm2.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, payload.ID, verified.ID)
+	require.Equal(t, "alfred", verified.Username)
+	require.Equal(t, "customer", verified.Role)
+	require.Equal(t, AccessToken, verified.TokenType)
+
+	pm3, err := NewPasetoMaker(NewKeySet("k1", symmetricKey), "other_implicit")
+	require.NoError(t, err)
+
+	_, err = pm3.VerifyToken(token)
+	require.Error(t, err)
+}
+
+func TestNewMaker(t *testing.T) {
+	maker, err := NewMaker(TypeJWT, NewKeySet("k1", "secretsthatgoespublicareeviltruth"), "")
+	require.NoError(t, err)
+	require.IsType(t, &JWTMaker{}, maker)
+
+	maker, err = NewMaker(TypePASETO, NewKeySet("k1", paseto.NewV4SymmetricKey().ExportHex()), "implicit")
+	require.NoError(t, err)
+	require.IsType(t, &PasetoMaker{}, maker)
+
+	maker, err = NewMaker("opaque", NewKeySet("k1", "secretsthatgoespublicareeviltruth"), "")
+	require.Error(t, err)
+	require.Nil(t, maker)
+}
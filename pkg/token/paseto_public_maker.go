@@ -0,0 +1,105 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+)
+
+// PasetoPublicMaker signs v4.public tokens with an Ed25519 key, the public
+// keys are published through PublicKeys.
+type PasetoPublicMaker struct {
+	keys     *keyring[paseto.V4AsymmetricSecretKey]
+	implicit []byte
+}
+
+func parsePasetoSecretKey(material string) (paseto.V4AsymmetricSecretKey, error) {
+	key, err := parsePrivateKey(material)
+
+	if err != nil {
+		return paseto.V4AsymmetricSecretKey{}, err
+	}
+
+	ed25519Key, ok := key.(ed25519.PrivateKey)
+
+	if !ok {
+		return paseto.V4AsymmetricSecretKey{}, fmt.Errorf("v4.public requires an ed25519 key")
+	}
+
+	return paseto.NewV4AsymmetricSecretKeyFromEd25519(ed25519Key)
+}
+
+func NewPasetoPublicMaker(keySet KeySet, implicit string) (Maker, error) {
+	keys, err := newKeyring(keySet, parsePasetoSecretKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasetoPublicMaker{keys, []byte(implicit)}, nil
+}
+
+func (pm *PasetoPublicMaker) CreateToken(username string, role string, tokenType string, duration time.Duration) (string, *Payload, error) {
+	payload := NewPayload(username, role, tokenType, duration)
+
+	kid, secretKey := pm.keys.primary()
+
+	token, err := newPasetoToken(payload, kid)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token.V4Sign(secretKey, pm.implicit), payload, nil
+}
+
+func (pm *PasetoPublicMaker) VerifyToken(token string) (*Payload, error) {
+	parser := newPasetoParser()
+
+	kid, err := getPasetoKeyID(parser, paseto.V4Public, token)
+
+	if err != nil {
+		return nil, err
+	}
+
+	secretKey, err := pm.keys.get(kid)
+
+	if err != nil {
+		return nil, err
+	}
+
+	parsedToken, err := parser.ParseV4Public(secretKey.Public(), token, pm.implicit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return getPayloadFromToken(parsedToken)
+}
+
+func (pm *PasetoPublicMaker) Rotate(keySet KeySet) error {
+	return pm.keys.rotate(keySet)
+}
+
+func (pm *PasetoPublicMaker) PublicKeys() (JSONWebKeySet, error) {
+	kids, secretKeys := pm.keys.all()
+
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(kids))}
+
+	for i, kid := range kids {
+		publicKey := ed25519.PublicKey(secretKeys[i].Public().ExportBytes())
+
+		// v4.public isn't a registered JOSE algorithm, so alg is left out
+		jwk, err := newJSONWebKey(kid, "", publicKey)
+
+		if err != nil {
+			return set, err
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set, nil
+}
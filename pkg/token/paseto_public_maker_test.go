@@ -0,0 +1,51 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasetoPublicMaker(t *testing.T) {
+	maker, err := NewPasetoPublicMaker(NewKeySet("k1", newEd25519Key(t)), "implicit")
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateToken("alfred", "customer", AccessToken, time.Hour)
+	require.NoError(t, err)
+	require.Contains(t, token, "v4.public.")
+
+	verified, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, payload.ID, verified.ID)
+	require.Equal(t, "alfred", verified.Username)
+
+	// another service verifies with nothing but the published key
+	jwks, err := maker.(PublicKeyPublisher).PublicKeys()
+	require.NoError(t, err)
+	require.Len(t, jwks.Keys, 1)
+	require.Equal(t, "k1", jwks.Keys[0].KeyID)
+	require.Equal(t, "Ed25519", jwks.Keys[0].Curve)
+
+	x, err := base64.RawURLEncoding.DecodeString(jwks.Keys[0].X)
+	require.NoError(t, err)
+
+	publicKey, err := paseto.NewV4AsymmetricPublicKeyFromEd25519(ed25519.PublicKey(x))
+	require.NoError(t, err)
+
+	parsed, err := paseto.NewParser().ParseV4Public(publicKey, token, []byte("implicit"))
+	require.NoError(t, err)
+
+	username, err := parsed.GetString("username")
+	require.NoError(t, err)
+	require.Equal(t, "alfred", username)
+}
+
+func TestNewPasetoPublicMakerInvalidKey(t *testing.T) {
+	maker, err := NewPasetoPublicMaker(NewKeySet("k1", newRSAKey(t, 2048)), "implicit")
+	require.Error(t, err)
+	require.Nil(t, maker)
+}
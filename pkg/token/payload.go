@@ -7,14 +7,25 @@ import (
 	"github.com/google/uuid"
 )
 
+// Token types tell access tokens apart from the refresh tokens that are only
+// good for renewing them.
+const (
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+)
+
 type Payload struct {
-	Username string `json:"username"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	TokenType string `json:"token_type"`
 	jwt.RegisteredClaims
 }
 
-func NewPayload(username string, duration time.Duration) *Payload {
+func NewPayload(username string, role string, tokenType string, duration time.Duration) *Payload {
 	return &Payload{
-		Username: username,
+		Username:  username,
+		Role:      role,
+		TokenType: tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        uuid.NewString(),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
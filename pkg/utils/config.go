@@ -2,7 +2,9 @@ package utils
 
 import (
 	"log"
+	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 )
 
@@ -10,8 +12,33 @@ type Config struct {
 	Mode          string `mapstructure:"GIN_MODE"`
 	DBDriver      string `mapstructure:"DB_DRIVER"`
 	ServerAddress string `mapstructure:"SERVER_ADDRESS"`
-	DBUrl         string `mapstructure:"DB_URL"`
-	MigrateUrl    string `mapstructure:"MIGRATE_URL"`
+	// GRPCServerAddress is where the gRPC API listens; empty disables it.
+	GRPCServerAddress string `mapstructure:"GRPC_SERVER_ADDRESS"`
+	DBUrl             string `mapstructure:"DB_URL"`
+	MigrateUrl        string `mapstructure:"MIGRATE_URL"`
+
+	TokenType  string `mapstructure:"TOKEN_TYPE"`
+	TokenKeyID string `mapstructure:"TOKEN_KEY_ID"`
+	TokenKey   string `mapstructure:"TOKEN_KEY"`
+	// TokenSymmetricKey is the old name of TOKEN_KEY, only read when TOKEN_KEY
+	// isn't set.
+	TokenSymmetricKey      string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	TokenVerificationKeys  string        `mapstructure:"TOKEN_VERIFICATION_KEYS"`
+	TokenImplicitAssertion string        `mapstructure:"TOKEN_IMPLICIT_ASSERTION"`
+	AccessTokenDuration    time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration   time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+
+	FxSpreadBps int32  `mapstructure:"FX_SPREAD_BPS"`
+	FxRatesFile string `mapstructure:"FX_RATES_FILE"`
+
+	SchedulerInterval time.Duration `mapstructure:"SCHEDULER_INTERVAL"`
+
+	EventPublisher      string        `mapstructure:"EVENT_PUBLISHER"`
+	EventPublisherUrl   string        `mapstructure:"EVENT_PUBLISHER_URL"`
+	EventTopic          string        `mapstructure:"EVENT_TOPIC"`
+	OutboxRelayInterval time.Duration `mapstructure:"OUTBOX_RELAY_INTERVAL"`
+
+	WebhookInterval time.Duration `mapstructure:"WEBHOOK_INTERVAL"`
 }
 
 func LoadConfig(path string) Config {
@@ -21,14 +48,49 @@ func LoadConfig(path string) Config {
 	viper.SetConfigName("app")
 	viper.SetConfigType("env")
 	viper.AutomaticEnv()
+	// the old key name isn't in the config file any more, so it has to be
+	// declared to be read from the environment
+	viper.SetDefault("TOKEN_SYMMETRIC_KEY", "")
 
 	if err := viper.ReadInConfig(); err != nil {
 		log.Fatal("unable to read config: ", err)
 	}
 
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := unmarshal(&config); err != nil {
 		log.Fatal("unable to unmarshal config: ", err)
 	}
 
 	return config
 }
+
+// unmarshal reads the loaded config into config, falling back to the old
+// names of renamed settings.
+func unmarshal(config *Config) error {
+	if err := viper.Unmarshal(config); err != nil {
+		return err
+	}
+
+	if config.TokenKey == "" && config.TokenSymmetricKey != "" {
+		log.Println("TOKEN_SYMMETRIC_KEY is deprecated, set TOKEN_KEY instead")
+		config.TokenKey = config.TokenSymmetricKey
+	}
+
+	return nil
+}
+
+// WatchConfig calls onChange with the reloaded config whenever the config file
+// loaded by LoadConfig changes.
+func WatchConfig(onChange func(Config)) {
+	viper.OnConfigChange(func(e fsnotify.Event) {
+		var config Config
+
+		if err := unmarshal(&config); err != nil {
+			log.Println("unable to unmarshal reloaded config: ", err)
+			return
+		}
+
+		onChange(config)
+	})
+
+	viper.WatchConfig()
+}
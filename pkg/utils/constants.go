@@ -1,8 +0,0 @@
-package utils
-
-const (
-	USD = "USD"
-	EUR = "EUR"
-	CAD = "CAD"
-	INR = "INR"
-)
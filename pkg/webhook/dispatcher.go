@@ -0,0 +1,210 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/events"
+	"github.com/aseerkt/go-simple-bank/pkg/retry"
+)
+
+const (
+	// lease is how long a claimed delivery is kept from other dispatchers,
+	// longer than a request can take.
+	lease = time.Minute
+	// requestTimeout is how long an endpoint has to answer.
+	requestTimeout = 10 * time.Second
+	// MaxAttempts is how many times a delivery is tried before it's dead.
+	MaxAttempts = 12
+	// retryBackoff is the wait before the first retry, doubled after each
+	// failed attempt up to maxRetryBackoff.
+	retryBackoff    = 30 * time.Second
+	maxRetryBackoff = 4 * time.Hour
+)
+
+// Dispatcher sends queued events to webhook endpoints as signed POSTs.
+// Claiming a delivery pushes its next attempt a lease into the future, so
+// replicas sharing the queue don't send it twice, and one whose dispatcher
+// died mid-request comes due again. A delivery that fails is retried with
+// exponential backoff and is dead after MaxAttempts, until it's replayed.
+// Delivery is at least once; endpoints can drop repeats by the Webhook-Id
+// header.
+type Dispatcher struct {
+	store    db.Store
+	client   *http.Client
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewDispatcher(store db.Store, interval time.Duration) *Dispatcher {
+	return &Dispatcher{
+		store: store,
+		client: &http.Client{
+			Timeout:   requestTimeout,
+			Transport: newTransport(),
+			// a redirect is a failed delivery rather than a request to
+			// somewhere the secret wasn't registered for
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// newTransport dials endpoints directly, never through a proxy, and only at
+// public addresses, so a webhook can't be pointed at a service inside the
+// network.
+func newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   requestTimeout,
+		KeepAlive: 30 * time.Second,
+		Control:   dialControl,
+	}).DialContext
+
+	return transport
+}
+
+// Start sends due deliveries every interval until ctx is done.
+func (d *Dispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DeliverDue(ctx); err != nil {
+			log.Println("unable to deliver webhooks: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue tries deliveries until none are due and returns how many were
+// tried.
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	tried := 0
+
+	for ctx.Err() == nil {
+		delivery, err := d.store.ClaimDueWebhookDelivery(ctx, int32(lease/time.Second))
+
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return tried, nil
+			}
+			return tried, err
+		}
+
+		if err := d.deliver(ctx, delivery); err != nil {
+			return tried, fmt.Errorf("webhook delivery %d: %w", delivery.ID, err)
+		}
+
+		tried++
+	}
+
+	return tried, ctx.Err()
+}
+
+// deliver sends delivery and records the outcome. Only errors loading or
+// recording the delivery are returned; a failed request is retried.
+func (d *Dispatcher) deliver(ctx context.Context, delivery db.WebhookDelivery) error {
+	webhook, err := d.store.GetWebhook(ctx, delivery.WebhookID)
+
+	if err != nil {
+		// the webhook was deleted along with its deliveries
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	event, err := d.store.GetOutboxEvent(ctx, delivery.EventID)
+
+	if err != nil {
+		return err
+	}
+
+	statusCode, sendErr := d.send(ctx, webhook, events.FromOutbox(event))
+
+	attempt := delivery.Attempts + 1
+
+	arg := db.RecordWebhookDeliveryAttemptParams{
+		Status:        db.DeliveryDelivered,
+		NextAttemptAt: delivery.NextAttemptAt,
+		ID:            delivery.ID,
+	}
+
+	if statusCode != 0 {
+		arg.LastStatusCode = sql.NullInt32{Int32: int32(statusCode), Valid: true}
+	}
+
+	if sendErr != nil {
+		arg.LastError = sql.NullString{String: sendErr.Error(), Valid: true}
+
+		if attempt < MaxAttempts {
+			arg.Status = db.DeliveryPending
+			arg.NextAttemptAt = d.now().Add(retry.Backoff(attempt, retryBackoff, maxRetryBackoff))
+		} else {
+			arg.Status = db.DeliveryDead
+			log.Printf("giving up on webhook delivery %d to %s after %d attempts: %s", delivery.ID, webhook.Url, attempt, sendErr)
+		}
+	}
+
+	_, err = d.store.RecordWebhookDeliveryAttempt(ctx, arg)
+
+	return err
+}
+
+// send POSTs event to webhook and returns the status code it answered with.
+// Any status but 2xx is an error.
+func (d *Dispatcher) send(ctx context.Context, webhook db.Webhook, event events.Event) (int, error) {
+	body, err := json.Marshal(event)
+
+	if err != nil {
+		return 0, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := d.now()
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderID, strconv.FormatInt(event.ID, 10))
+	request.Header.Set(HeaderEvent, event.Type)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	request.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+
+	response, err := d.client.Do(request)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		return response.StatusCode, fmt.Errorf("unexpected status %s", response.Status)
+	}
+
+	return response.StatusCode, nil
+}
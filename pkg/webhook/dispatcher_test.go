@@ -0,0 +1,230 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aseerkt/go-simple-bank/pkg/db"
+	"github.com/aseerkt/go-simple-bank/pkg/events"
+	"github.com/aseerkt/go-simple-bank/pkg/mockdb"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeliverDue(t *testing.T) {
+	now := time.Date(2024, 8, 5, 9, 0, 0, 0, time.UTC)
+
+	event := db.Outbox{
+		ID:        7,
+		EventType: db.EventTransferCompleted,
+		Key:       db.AccountKey(1),
+		Payload:   json.RawMessage(`{"transfer":{"id":3}}`),
+		CreatedAt: now.Add(-time.Minute),
+	}
+
+	delivery := db.WebhookDelivery{
+		ID:            11,
+		WebhookID:     5,
+		EventID:       event.ID,
+		Status:        db.DeliveryPending,
+		NextAttemptAt: now.Add(lease),
+	}
+
+	testCases := []struct {
+		name          string
+		status        int
+		attempts      int32
+		buildStub     func(store *mockdb.MockStore, webhook db.Webhook)
+		checkResponse func(t *testing.T, tried int, err error)
+	}{
+		{
+			name:   "Delivered",
+			status: http.StatusNoContent,
+			buildStub: func(store *mockdb.MockStore, webhook db.Webhook) {
+				store.EXPECT().RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Eq(db.RecordWebhookDeliveryAttemptParams{
+					Status:         db.DeliveryDelivered,
+					NextAttemptAt:  delivery.NextAttemptAt,
+					LastStatusCode: sql.NullInt32{Int32: http.StatusNoContent, Valid: true},
+					ID:             delivery.ID,
+				})).Times(1)
+			},
+			checkResponse: func(t *testing.T, tried int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, tried)
+			},
+		},
+		{
+			name:     "Retried",
+			status:   http.StatusInternalServerError,
+			attempts: 2,
+			buildStub: func(store *mockdb.MockStore, webhook db.Webhook) {
+				store.EXPECT().RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Eq(db.RecordWebhookDeliveryAttemptParams{
+					Status:         db.DeliveryPending,
+					NextAttemptAt:  now.Add(4 * retryBackoff),
+					LastStatusCode: sql.NullInt32{Int32: http.StatusInternalServerError, Valid: true},
+					LastError:      sql.NullString{String: "unexpected status 500 Internal Server Error", Valid: true},
+					ID:             delivery.ID,
+				})).Times(1)
+			},
+			checkResponse: func(t *testing.T, tried int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, tried)
+			},
+		},
+		{
+			name:     "Dead",
+			status:   http.StatusGone,
+			attempts: MaxAttempts - 1,
+			buildStub: func(store *mockdb.MockStore, webhook db.Webhook) {
+				store.EXPECT().RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Eq(db.RecordWebhookDeliveryAttemptParams{
+					Status:         db.DeliveryDead,
+					NextAttemptAt:  delivery.NextAttemptAt,
+					LastStatusCode: sql.NullInt32{Int32: http.StatusGone, Valid: true},
+					LastError:      sql.NullString{String: "unexpected status 410 Gone", Valid: true},
+					ID:             delivery.ID,
+				})).Times(1)
+			},
+			checkResponse: func(t *testing.T, tried int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, tried)
+			},
+		},
+		{
+			name:   "RecordError",
+			status: http.StatusOK,
+			buildStub: func(store *mockdb.MockStore, webhook db.Webhook) {
+				store.EXPECT().RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.WebhookDelivery{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, tried int, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+				require.Zero(t, tried)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var received []*http.Request
+			var bodies [][]byte
+
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+
+				received = append(received, r)
+				bodies = append(bodies, body)
+
+				w.WriteHeader(tc.status)
+			}))
+			defer receiver.Close()
+
+			webhook := db.Webhook{
+				ID:     delivery.WebhookID,
+				Owner:  "alice",
+				Url:    receiver.URL,
+				Secret: "whsec_test",
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			claimed := delivery
+			claimed.Attempts = tc.attempts
+
+			gomock.InOrder(
+				store.EXPECT().ClaimDueWebhookDelivery(gomock.Any(), gomock.Eq(int32(lease/time.Second))).Times(1).Return(claimed, nil),
+				store.EXPECT().ClaimDueWebhookDelivery(gomock.Any(), gomock.Any()).MaxTimes(1).Return(db.WebhookDelivery{}, sql.ErrNoRows),
+			)
+			store.EXPECT().GetWebhook(gomock.Any(), gomock.Eq(webhook.ID)).Times(1).Return(webhook, nil)
+			store.EXPECT().GetOutboxEvent(gomock.Any(), gomock.Eq(event.ID)).Times(1).Return(event, nil)
+			tc.buildStub(store, webhook)
+
+			dispatcher := NewDispatcher(store, time.Second)
+			dispatcher.now = func() time.Time { return now }
+			// the receiver listens on loopback, which the dispatcher's own
+			// transport refuses to dial
+			dispatcher.client.Transport = receiver.Client().Transport
+
+			tried, err := dispatcher.DeliverDue(context.Background())
+			tc.checkResponse(t, tried, err)
+
+			require.Len(t, received, 1)
+
+			request := received[0]
+			require.Equal(t, http.MethodPost, request.Method)
+			require.Equal(t, "7", request.Header.Get(HeaderID))
+			require.Equal(t, db.EventTransferCompleted, request.Header.Get(HeaderEvent))
+			require.Equal(t, "1722848400", request.Header.Get(HeaderTimestamp))
+			require.NoError(t, Verify(webhook.Secret, request.Header.Get(HeaderSignature), bodies[0], time.Minute, now))
+
+			var sent events.Event
+			require.NoError(t, json.Unmarshal(bodies[0], &sent))
+			require.Equal(t, events.FromOutbox(event).Payload, sent.Payload)
+			require.Equal(t, event.ID, sent.ID)
+		})
+	}
+}
+
+func TestDeliverDeletedWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	gomock.InOrder(
+		store.EXPECT().ClaimDueWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).Return(db.WebhookDelivery{ID: 1, WebhookID: 2}, nil),
+		store.EXPECT().ClaimDueWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).Return(db.WebhookDelivery{}, sql.ErrNoRows),
+	)
+	store.EXPECT().GetWebhook(gomock.Any(), gomock.Eq(int64(2))).Times(1).Return(db.Webhook{}, sql.ErrNoRows)
+	store.EXPECT().RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).Times(0)
+
+	tried, err := NewDispatcher(store, time.Second).DeliverDue(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, tried)
+}
+
+func TestDeliverInternalEndpoint(t *testing.T) {
+	received := false
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+	}))
+	defer receiver.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	delivery := db.WebhookDelivery{ID: 1, WebhookID: 2, EventID: 3}
+
+	gomock.InOrder(
+		store.EXPECT().ClaimDueWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).Return(delivery, nil),
+		store.EXPECT().ClaimDueWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).Return(db.WebhookDelivery{}, sql.ErrNoRows),
+	)
+	store.EXPECT().GetWebhook(gomock.Any(), gomock.Eq(delivery.WebhookID)).Times(1).
+		Return(db.Webhook{ID: delivery.WebhookID, Url: receiver.URL, Secret: "whsec_test"}, nil)
+	store.EXPECT().GetOutboxEvent(gomock.Any(), gomock.Eq(delivery.EventID)).Times(1).
+		Return(db.Outbox{ID: delivery.EventID, EventType: db.EventTransferCompleted, Payload: json.RawMessage(`{}`)}, nil)
+	store.EXPECT().RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, arg db.RecordWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+			require.Equal(t, db.DeliveryPending, arg.Status)
+			require.False(t, arg.LastStatusCode.Valid)
+			require.Contains(t, arg.LastError.String, ErrForbiddenEndpoint.Error())
+			return db.WebhookDelivery{}, nil
+		})
+
+	tried, err := NewDispatcher(store, time.Second).DeliverDue(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, tried)
+	require.False(t, received)
+}
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+)
+
+var (
+	ErrInsecureURL       = errors.New("webhook url must be https")
+	ErrForbiddenEndpoint = errors.New("webhook endpoint is not a public address")
+)
+
+// ValidateURL checks that rawURL is an https URL whose host isn't a literal
+// internal address. Host names are only checked when the dispatcher dials
+// them, as they can resolve somewhere else by then.
+func ValidateURL(rawURL string) error {
+	endpoint, err := url.Parse(rawURL)
+
+	if err != nil {
+		return err
+	}
+
+	if endpoint.Scheme != "https" || endpoint.Host == "" {
+		return ErrInsecureURL
+	}
+
+	if ip := net.ParseIP(endpoint.Hostname()); ip != nil && !isPublic(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenEndpoint, ip)
+	}
+
+	return nil
+}
+
+// isPublic reports whether ip can be reached from outside the network the
+// dispatcher runs in.
+func isPublic(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+}
+
+// dialControl refuses connections to internal addresses. It runs after the
+// host name is resolved, for every address tried, so a name that's changed
+// to resolve inside the network since it was registered is caught too.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !isPublic(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenEndpoint, host)
+	}
+
+	return nil
+}
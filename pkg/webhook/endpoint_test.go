@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateURL(t *testing.T) {
+	testCases := []struct {
+		name string
+		url  string
+		err  error
+	}{
+		{name: "Ok", url: "https://partner.example.com/hooks"},
+		{name: "PublicIP", url: "https://93.184.216.34/hooks"},
+		{name: "HTTP", url: "http://partner.example.com/hooks", err: ErrInsecureURL},
+		{name: "NoHost", url: "https:///hooks", err: ErrInsecureURL},
+		{name: "Loopback", url: "https://127.0.0.1:8080/hooks", err: ErrForbiddenEndpoint},
+		{name: "LoopbackIPv6", url: "https://[::1]/hooks", err: ErrForbiddenEndpoint},
+		{name: "Private", url: "https://10.0.3.7/hooks", err: ErrForbiddenEndpoint},
+		{name: "LinkLocal", url: "https://169.254.169.254/latest/meta-data", err: ErrForbiddenEndpoint},
+		{name: "Unspecified", url: "https://0.0.0.0/hooks", err: ErrForbiddenEndpoint},
+		{name: "MappedIPv4", url: "https://[::ffff:192.168.1.1]/hooks", err: ErrForbiddenEndpoint},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateURL(tc.url)
+
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestDialControl(t *testing.T) {
+	require.NoError(t, dialControl("tcp", net.JoinHostPort("93.184.216.34", "443"), nil))
+	require.ErrorIs(t, dialControl("tcp", net.JoinHostPort("127.0.0.1", "443"), nil), ErrForbiddenEndpoint)
+	require.ErrorIs(t, dialControl("tcp6", net.JoinHostPort("fe80::1", "443"), nil), ErrForbiddenEndpoint)
+	require.ErrorIs(t, dialControl("tcp", net.JoinHostPort("172.16.0.10", "443"), nil), ErrForbiddenEndpoint)
+}
@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	timestamp := time.Unix(1722589200, 0)
+	body := []byte(`{"id":1}`)
+
+	signature := Sign("secret", timestamp, body)
+	require.Equal(t, "t=1722589200,v1=2a79638178394ab4f4c969912f7da6e06365320f920933b0b9f2aa950bcc6151", signature)
+
+	testCases := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+		err    error
+	}{
+		{
+			name:   "OK",
+			secret: "secret",
+			header: signature,
+			body:   body,
+			now:    timestamp.Add(time.Minute),
+		},
+		{
+			name:   "RotatedSecret",
+			secret: "secret",
+			header: signature + ",v1=" + Sign("old", timestamp, body)[len("t=1722589200,v1="):],
+			body:   body,
+			now:    timestamp,
+		},
+		{
+			name:   "WrongSecret",
+			secret: "other",
+			header: signature,
+			body:   body,
+			now:    timestamp,
+			err:    ErrInvalidSignature,
+		},
+		{
+			name:   "TamperedBody",
+			secret: "secret",
+			header: signature,
+			body:   []byte(`{"id":2}`),
+			now:    timestamp,
+			err:    ErrInvalidSignature,
+		},
+		{
+			name:   "Malformed",
+			secret: "secret",
+			header: "v1=abc",
+			body:   body,
+			now:    timestamp,
+			err:    ErrInvalidSignature,
+		},
+		{
+			name:   "Stale",
+			secret: "secret",
+			header: signature,
+			body:   body,
+			now:    timestamp.Add(10 * time.Minute),
+			err:    ErrStaleSignature,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Verify(tc.secret, tc.header, tc.body, 5*time.Minute, tc.now)
+
+			if tc.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+CREATE TABLE "idempotency_keys" (
+  "key" VARCHAR NOT NULL,
+  "username" VARCHAR NOT NULL,
+  "request_hash" VARCHAR NOT NULL,
+  "response_status" INTEGER,
+  "response_body" BYTEA,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("username", "key")
+);
+
+ALTER TABLE "idempotency_keys"
+ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
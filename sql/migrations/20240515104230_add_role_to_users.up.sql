@@ -0,0 +1,5 @@
+ALTER TABLE "users"
+ADD COLUMN "role" VARCHAR NOT NULL DEFAULT 'customer';
+
+ALTER TABLE "users"
+ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('customer', 'teller', 'admin'));
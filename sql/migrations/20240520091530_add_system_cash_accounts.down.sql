@@ -0,0 +1,19 @@
+-- Every deposit, withdrawal and fee has its other side on a system account,
+-- so once they have entries removing them would leave the ledger unbalanced.
+DO $$
+BEGIN
+  IF EXISTS (
+    SELECT 1
+    FROM "entries"
+      JOIN "accounts" ON "accounts".id = "entries".account_id
+    WHERE "accounts".owner = 'system'
+  ) THEN
+    RAISE EXCEPTION 'system accounts have entries, rolling them back would unbalance the ledger';
+  END IF;
+END $$;
+
+DELETE FROM "accounts"
+WHERE owner = 'system';
+
+DELETE FROM "users"
+WHERE username = 'system';
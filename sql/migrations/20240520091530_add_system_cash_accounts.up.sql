@@ -0,0 +1,5 @@
+-- The system user owns the per-currency cash accounts that deposits and
+-- withdrawals are posted against. It has no usable password and the lowest
+-- role. Its accounts are created on first use.
+INSERT INTO "users" (username, hashed_password, full_name, email, role)
+VALUES ('system', '', 'Simple Bank', 'system@simplebank.internal', 'customer');
@@ -0,0 +1,25 @@
+ALTER TABLE "entries"
+ADD COLUMN "transfer_id" bigint;
+
+ALTER TABLE "entries"
+ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+-- Link existing transfer entries. A transfer and its two entries are written
+-- in one transaction, so they share the same now() timestamp.
+UPDATE "entries" e
+SET transfer_id = t.id
+FROM "transfers" t
+WHERE e.transfer_id IS NULL
+  AND e.create_at = t.created_at
+  AND (
+    (
+      e.account_id = t.from_account_id
+      AND e.amount = - t.amount
+    )
+    OR (
+      e.account_id = t.to_account_id
+      AND e.amount = t.amount
+    )
+  );
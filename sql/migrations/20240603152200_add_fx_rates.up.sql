@@ -0,0 +1,27 @@
+-- rate is the mid-market price of one unit of base_currency in
+-- quote_currency, scaled by 10^8.
+CREATE TABLE "rates" (
+  "base_currency" varchar NOT NULL,
+  "quote_currency" varchar NOT NULL,
+  "rate" bigint NOT NULL CHECK ("rate" > 0),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("base_currency", "quote_currency")
+);
+
+-- amount is debited in the source currency and to_amount credited in the
+-- destination currency at fx_rate (scaled by 10^8, spread already applied).
+ALTER TABLE "transfers"
+ADD COLUMN "to_amount" bigint;
+
+ALTER TABLE "transfers"
+ADD COLUMN "fx_rate" bigint NOT NULL DEFAULT 100000000;
+
+ALTER TABLE "transfers"
+ADD COLUMN "fx_spread_bps" integer NOT NULL DEFAULT 0;
+
+UPDATE "transfers"
+SET to_amount = amount;
+
+ALTER TABLE "transfers"
+ALTER COLUMN "to_amount"
+SET NOT NULL;
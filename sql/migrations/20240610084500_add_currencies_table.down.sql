@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "to_currency";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "currency";
+
+DROP TABLE IF EXISTS "currencies";
@@ -0,0 +1,38 @@
+CREATE TABLE "currencies" (
+  "code" varchar(3) PRIMARY KEY,
+  "numeric_code" integer UNIQUE NOT NULL,
+  "minor_units" integer NOT NULL CHECK ("minor_units" BETWEEN 0 AND 4),
+  "enabled" boolean NOT NULL DEFAULT true
+);
+
+INSERT INTO "currencies" (code, numeric_code, minor_units)
+VALUES ('USD', 840, 2),
+  ('EUR', 978, 2),
+  ('CAD', 124, 2),
+  ('INR', 356, 2),
+  ('JPY', 392, 0),
+  ('KWD', 414, 3);
+
+-- Amounts of a transfer are in the currencies of its two accounts. Keeping
+-- them on the row lets transfers be formatted without loading the accounts.
+ALTER TABLE "transfers"
+ADD COLUMN "currency" varchar;
+
+ALTER TABLE "transfers"
+ADD COLUMN "to_currency" varchar;
+
+UPDATE "transfers" t
+SET currency = fa.currency,
+  to_currency = ta.currency
+FROM "accounts" fa,
+  "accounts" ta
+WHERE fa.id = t.from_account_id
+  AND ta.id = t.to_account_id;
+
+ALTER TABLE "transfers"
+ALTER COLUMN "currency"
+SET NOT NULL;
+
+ALTER TABLE "transfers"
+ALTER COLUMN "to_currency"
+SET NOT NULL;
@@ -0,0 +1,23 @@
+ALTER TABLE "accounts"
+ADD COLUMN "status" VARCHAR NOT NULL DEFAULT 'active';
+
+ALTER TABLE "accounts"
+ADD CONSTRAINT "accounts_status_check" CHECK ("status" IN ('active', 'frozen', 'closed'));
+
+CREATE TABLE "account_status_changes" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "from_status" VARCHAR NOT NULL,
+  "to_status" VARCHAR NOT NULL,
+  "changed_by" VARCHAR NOT NULL,
+  "reason" VARCHAR NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "account_status_changes" ("account_id");
+
+ALTER TABLE "account_status_changes"
+ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_status_changes"
+ADD FOREIGN KEY ("changed_by") REFERENCES "users" ("username");
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS "transfers_from_account_id_created_at_idx";
+
+DROP TABLE IF EXISTS "tier_limits";
+
+DROP TABLE IF EXISTS "account_limits";
+
+ALTER TABLE IF EXISTS "users" DROP CONSTRAINT IF EXISTS "users_tier_check";
+
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "tier";
\ No newline at end of file
@@ -0,0 +1,35 @@
+ALTER TABLE "users"
+ADD COLUMN "tier" VARCHAR NOT NULL DEFAULT 'standard';
+
+ALTER TABLE "users"
+ADD CONSTRAINT "users_tier_check" CHECK ("tier" IN ('standard', 'premium'));
+
+-- Limits are in minor units of the account's currency. A NULL limit doesn't
+-- apply.
+CREATE TABLE "account_limits" (
+  "account_id" bigint PRIMARY KEY,
+  "per_transfer_limit" bigint,
+  "daily_limit" bigint,
+  "monthly_limit" bigint,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "account_limits"
+ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+-- Tier limits cap what a user in the tier sends from all of their accounts in
+-- a currency together.
+CREATE TABLE "tier_limits" (
+  "tier" VARCHAR NOT NULL,
+  "currency" VARCHAR NOT NULL,
+  "per_transfer_limit" bigint,
+  "daily_limit" bigint,
+  "monthly_limit" bigint,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("tier", "currency")
+);
+
+ALTER TABLE "tier_limits"
+ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "scheduled_transfer_runs";
+
+DROP TABLE IF EXISTS "scheduled_transfers";
+
+DROP INDEX IF EXISTS "transfers_idempotency_key_idx";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "idempotency_key";
\ No newline at end of file
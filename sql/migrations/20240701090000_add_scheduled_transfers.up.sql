@@ -0,0 +1,70 @@
+-- Transfers made on behalf of a schedule carry a key per occurrence so that
+-- running an occurrence twice can't move money twice.
+ALTER TABLE "transfers"
+ADD COLUMN "idempotency_key" VARCHAR;
+
+CREATE UNIQUE INDEX ON "transfers" ("idempotency_key");
+
+-- next_run_at is the occurrence that runs next and due_at when it's next
+-- tried, which is later than next_run_at while a failed run backs off.
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "owner" VARCHAR NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" VARCHAR NOT NULL,
+  "recurrence" VARCHAR,
+  "start_at" timestamptz NOT NULL,
+  "status" VARCHAR NOT NULL DEFAULT 'active',
+  "run_count" integer NOT NULL DEFAULT 0,
+  "next_run_at" timestamptz,
+  "due_at" timestamptz,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "last_error" VARCHAR,
+  "locked_until" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "scheduled_transfers"
+ADD CONSTRAINT "scheduled_transfers_amount_check" CHECK ("amount" > 0);
+
+ALTER TABLE "scheduled_transfers"
+ADD CONSTRAINT "scheduled_transfers_status_check" CHECK (
+    "status" IN ('active', 'completed', 'failed', 'cancelled')
+  );
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+
+CREATE INDEX ON "scheduled_transfers" ("status", "due_at");
+
+ALTER TABLE "scheduled_transfers"
+ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers"
+ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers"
+ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers"
+ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
+
+CREATE TABLE "scheduled_transfer_runs" (
+  "id" bigserial PRIMARY KEY,
+  "scheduled_transfer_id" bigint NOT NULL,
+  "scheduled_for" timestamptz NOT NULL,
+  "attempt" integer NOT NULL,
+  "transfer_id" bigint,
+  "error" VARCHAR,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id");
+
+ALTER TABLE "scheduled_transfer_runs"
+ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id");
+
+ALTER TABLE "scheduled_transfer_runs"
+ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
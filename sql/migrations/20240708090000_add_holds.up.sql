@@ -0,0 +1,43 @@
+-- available_balance is the balance less what's reserved by active holds.
+ALTER TABLE "accounts"
+ADD COLUMN "available_balance" bigint NOT NULL DEFAULT 0;
+
+UPDATE "accounts"
+SET "available_balance" = "balance";
+
+CREATE TABLE "holds" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" VARCHAR NOT NULL,
+  "status" VARCHAR NOT NULL DEFAULT 'active',
+  "expires_at" timestamptz NOT NULL,
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "holds"
+ADD CONSTRAINT "holds_amount_check" CHECK ("amount" > 0);
+
+ALTER TABLE "holds"
+ADD CONSTRAINT "holds_status_check" CHECK (
+    "status" IN ('active', 'captured', 'voided', 'expired')
+  );
+
+CREATE INDEX ON "holds" ("account_id");
+
+CREATE INDEX ON "holds" ("status", "expires_at");
+
+ALTER TABLE "holds"
+ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds"
+ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds"
+ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
+
+ALTER TABLE "holds"
+ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,74 @@
+-- Products set what kind of account an account is and how much interest it
+-- earns. System products are for the bank's own accounts and can't be opened
+-- by customers. Rates are yearly in basis points.
+CREATE TABLE "products" (
+  "code" VARCHAR PRIMARY KEY,
+  "name" VARCHAR NOT NULL,
+  "system" boolean NOT NULL DEFAULT false,
+  "interest_rate_bps" integer NOT NULL DEFAULT 0,
+  "interest_days_in_year" integer NOT NULL DEFAULT 365,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "products"
+ADD CONSTRAINT "products_interest_rate_bps_check" CHECK ("interest_rate_bps" >= 0);
+
+ALTER TABLE "products"
+ADD CONSTRAINT "products_interest_days_in_year_check" CHECK ("interest_days_in_year" IN (360, 365));
+
+INSERT INTO "products" ("code", "name", "system", "interest_rate_bps")
+VALUES ('checking', 'Checking', false, 0),
+  ('savings', 'Savings', false, 200),
+  ('cash', 'Cash', true, 0),
+  ('interest_expense', 'Interest expense', true, 0);
+
+ALTER TABLE "accounts"
+ADD COLUMN "product" VARCHAR NOT NULL DEFAULT 'checking';
+
+UPDATE "accounts"
+SET "product" = 'cash'
+WHERE "owner" = 'system';
+
+ALTER TABLE "accounts"
+ADD FOREIGN KEY ("product") REFERENCES "products" ("code");
+
+-- A user can hold one account of each product per currency, and the system
+-- user one account of each kind.
+ALTER TABLE "accounts" DROP CONSTRAINT "owner_currency_key";
+
+ALTER TABLE "accounts"
+ADD CONSTRAINT "owner_currency_product_key" UNIQUE ("owner", "currency", "product");
+
+-- One row per account and day, with the end of day balance and the rate it
+-- earned, so the exact interest of any period can be summed up later.
+CREATE TABLE "interest_accruals" (
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "rate_bps" integer NOT NULL,
+  "days_in_year" integer NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "accrual_date")
+);
+
+ALTER TABLE "interest_accruals"
+ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE INDEX ON "interest_accruals" ("accrual_date");
+
+-- One row per account and month of posted interest. period is the first day
+-- of the month.
+CREATE TABLE "interest_postings" (
+  "account_id" bigint NOT NULL,
+  "period" date NOT NULL,
+  "amount" bigint NOT NULL,
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "period")
+);
+
+ALTER TABLE "interest_postings"
+ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_postings"
+ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
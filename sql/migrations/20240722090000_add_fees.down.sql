@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "transfer_fees";
+
+DROP TABLE IF EXISTS "fee_rules";
+
+DELETE FROM "products"
+WHERE "code" = 'fee_income';
\ No newline at end of file
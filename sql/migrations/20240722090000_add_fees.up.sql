@@ -0,0 +1,65 @@
+INSERT INTO "products" ("code", "name", "system")
+VALUES ('fee_income', 'Fee income', true);
+
+-- Fee rules say what a transfer costs the sender, by the currency and product
+-- of the account it's sent from and whether it goes to an account of the same
+-- owner (internal) or someone else's (external). Amounts are in minor units
+-- and a NULL percent_max doesn't cap the percentage fee.
+CREATE TABLE "fee_rules" (
+  "currency" VARCHAR NOT NULL,
+  "product" VARCHAR NOT NULL,
+  "transfer_type" VARCHAR NOT NULL,
+  "flat_amount" bigint NOT NULL DEFAULT 0,
+  "percent_bps" integer NOT NULL DEFAULT 0,
+  "percent_min" bigint NOT NULL DEFAULT 0,
+  "percent_max" bigint,
+  "fx_surcharge_bps" integer NOT NULL DEFAULT 0,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("currency", "product", "transfer_type")
+);
+
+ALTER TABLE "fee_rules"
+ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
+
+ALTER TABLE "fee_rules"
+ADD FOREIGN KEY ("product") REFERENCES "products" ("code");
+
+ALTER TABLE "fee_rules"
+ADD CONSTRAINT "fee_rules_transfer_type_check" CHECK ("transfer_type" IN ('internal', 'external'));
+
+ALTER TABLE "fee_rules"
+ADD CONSTRAINT "fee_rules_amounts_check" CHECK (
+    "flat_amount" >= 0
+    AND "percent_bps" >= 0
+    AND "percent_min" >= 0
+    AND "percent_max" >= "percent_min"
+    AND "fx_surcharge_bps" >= 0
+  );
+
+-- Each fee charged on a transfer is a leg of its own: a debit from the sender
+-- and a credit to the fee income account of the currency, both entries
+-- carrying the transfer id.
+CREATE TABLE "transfer_fees" (
+  "id" bigserial PRIMARY KEY,
+  "transfer_id" bigint NOT NULL,
+  "kind" VARCHAR NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" VARCHAR NOT NULL,
+  "debit_entry_id" bigint NOT NULL,
+  "credit_entry_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "transfer_fees"
+ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_fees"
+ADD FOREIGN KEY ("debit_entry_id") REFERENCES "entries" ("id");
+
+ALTER TABLE "transfer_fees"
+ADD FOREIGN KEY ("credit_entry_id") REFERENCES "entries" ("id");
+
+ALTER TABLE "transfer_fees"
+ADD CONSTRAINT "transfer_fees_kind_check" CHECK ("kind" IN ('flat', 'percent', 'fx_surcharge'));
+
+CREATE INDEX ON "transfer_fees" ("transfer_id");
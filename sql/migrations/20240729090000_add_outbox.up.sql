@@ -0,0 +1,14 @@
+-- Domain events are written to the outbox in the transaction that causes them
+-- and published from there by the relay. key is what consumers order events
+-- by, e.g. account:42, and published_at is set once the relay has sent it.
+CREATE TABLE "outbox" (
+  "id" bigserial PRIMARY KEY,
+  "event_type" VARCHAR NOT NULL,
+  "key" VARCHAR NOT NULL,
+  "payload" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "published_at" timestamptz
+);
+
+CREATE INDEX ON "outbox" ("id")
+WHERE "published_at" IS NULL;
@@ -0,0 +1,44 @@
+-- Webhooks are endpoints users register to be sent the events about their
+-- accounts. An empty event_types gets every event.
+CREATE TABLE "webhooks" (
+  "id" bigserial PRIMARY KEY,
+  "owner" VARCHAR NOT NULL,
+  "url" VARCHAR NOT NULL,
+  "secret" VARCHAR NOT NULL,
+  "event_types" VARCHAR [] NOT NULL DEFAULT '{}',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "webhooks"
+ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+CREATE INDEX ON "webhooks" ("owner");
+
+-- One row per webhook and event. Pending deliveries are tried at
+-- next_attempt_at, and deliveries that ran out of attempts are dead until
+-- they're replayed.
+CREATE TABLE "webhook_deliveries" (
+  "id" bigserial PRIMARY KEY,
+  "webhook_id" bigint NOT NULL,
+  "event_id" bigint NOT NULL,
+  "status" VARCHAR NOT NULL DEFAULT 'pending',
+  "attempts" integer NOT NULL DEFAULT 0,
+  "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+  "last_status_code" integer,
+  "last_error" VARCHAR,
+  "delivered_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  UNIQUE ("webhook_id", "event_id")
+);
+
+ALTER TABLE "webhook_deliveries"
+ADD FOREIGN KEY ("webhook_id") REFERENCES "webhooks" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "webhook_deliveries"
+ADD FOREIGN KEY ("event_id") REFERENCES "outbox" ("id");
+
+ALTER TABLE "webhook_deliveries"
+ADD CONSTRAINT "webhook_deliveries_status_check" CHECK ("status" IN ('pending', 'delivered', 'dead'));
+
+CREATE INDEX ON "webhook_deliveries" ("next_attempt_at")
+WHERE "status" = 'pending';
@@ -1,6 +1,6 @@
 -- name: CreateAccount :one
-INSERT INTO accounts (owner, balance, currency)
-VALUES ($1, $2, $3)
+INSERT INTO accounts (owner, balance, available_balance, currency, product)
+VALUES ($1, $2, $2, $3, $4)
 RETURNING *;
 
 -- name: GetAccount :one
@@ -9,6 +9,13 @@ FROM accounts
 WHERE id = $1
 LIMIT 1;
 
+-- name: GetAccountForUpdate :one
+SELECT *
+FROM accounts
+WHERE id = $1
+LIMIT 1
+FOR UPDATE;
+
 -- name: ListAccounts :many
 SELECT *
 FROM accounts
@@ -17,15 +24,55 @@ LIMIT $2 OFFSET $3;
 
 -- name: UpdateAccount :exec
 UPDATE accounts
-SET balance = $2
+SET balance = $2,
+  available_balance = available_balance + $2 - balance
 WHERE id = $1;
 
 -- name: AddAccountBalance :one
 UPDATE accounts
-SET balance = balance + sqlc.arg(amount)
+SET balance = balance + sqlc.arg(amount),
+  available_balance = available_balance + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: AddAccountAvailableBalance :one
+-- Holds reserve and release funds by moving only the available balance.
+UPDATE accounts
+SET available_balance = available_balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
--- name: DeleteAccount :exec
-DELETE FROM accounts
-WHERE id = $1;
\ No newline at end of file
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateAccountStatusChange :one
+INSERT INTO account_status_changes (
+    account_id,
+    from_status,
+    to_status,
+    changed_by,
+    reason
+  )
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListAccountStatusChanges :many
+SELECT *
+FROM account_status_changes
+WHERE account_id = $1
+ORDER BY id;
+
+-- name: CreateSystemAccount :exec
+INSERT INTO accounts (owner, balance, currency, product)
+VALUES ('system', 0, $1, $2) ON CONFLICT (owner, currency, product) DO NOTHING;
+
+-- name: GetSystemAccount :one
+SELECT *
+FROM accounts
+WHERE owner = 'system'
+  AND currency = $1
+  AND product = $2
+LIMIT 1;
@@ -0,0 +1,4 @@
+-- name: ListCurrencies :many
+SELECT *
+FROM currencies
+ORDER BY code;
@@ -1,10 +1,45 @@
 -- name: CreateEntry :one
-INSERT INTO entries (account_id, amount)
-VALUES ($1, $2)
+INSERT INTO entries (account_id, amount, transfer_id)
+VALUES ($1, $2, $3)
 RETURNING *;
 
 -- name: GetEntry :one
 SELECT *
 FROM entries
 WHERE id = $1
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: ListEntries :many
+SELECT id,
+  account_id,
+  amount,
+  create_at,
+  running_balance
+FROM (
+    SELECT id,
+      account_id,
+      amount,
+      create_at,
+      SUM(amount) OVER (
+        ORDER BY id
+      )::bigint AS running_balance
+    FROM entries
+    WHERE account_id = sqlc.arg(account_id)
+  ) AS statement
+WHERE id > sqlc.arg(after_id)::bigint
+  AND (
+    sqlc.narg(start_time)::timestamptz IS NULL
+    OR create_at >= sqlc.narg(start_time)
+  )
+  AND (
+    sqlc.narg(end_time)::timestamptz IS NULL
+    OR create_at < sqlc.narg(end_time)
+  )
+ORDER BY id
+LIMIT sqlc.arg(page_size);
+
+-- name: ListTransferEntries :many
+SELECT *
+FROM entries
+WHERE transfer_id = $1
+ORDER BY id;
\ No newline at end of file
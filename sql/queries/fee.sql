@@ -0,0 +1,53 @@
+-- name: GetFeeRule :one
+SELECT *
+FROM fee_rules
+WHERE currency = $1
+  AND product = $2
+  AND transfer_type = $3
+LIMIT 1;
+
+-- name: ListFeeRules :many
+SELECT *
+FROM fee_rules
+ORDER BY currency,
+  product,
+  transfer_type;
+
+-- name: UpsertFeeRule :one
+INSERT INTO fee_rules (
+    currency,
+    product,
+    transfer_type,
+    flat_amount,
+    percent_bps,
+    percent_min,
+    percent_max,
+    fx_surcharge_bps
+  )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (currency, product, transfer_type) DO
+UPDATE
+SET flat_amount = EXCLUDED.flat_amount,
+  percent_bps = EXCLUDED.percent_bps,
+  percent_min = EXCLUDED.percent_min,
+  percent_max = EXCLUDED.percent_max,
+  fx_surcharge_bps = EXCLUDED.fx_surcharge_bps,
+  updated_at = now()
+RETURNING *;
+
+-- name: CreateTransferFee :one
+INSERT INTO transfer_fees (
+    transfer_id,
+    kind,
+    amount,
+    currency,
+    debit_entry_id,
+    credit_entry_id
+  )
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ListTransferFees :many
+SELECT *
+FROM transfer_fees
+WHERE transfer_id = $1
+ORDER BY id;
\ No newline at end of file
@@ -0,0 +1,42 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+    account_id,
+    to_account_id,
+    amount,
+    currency,
+    expires_at
+  )
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetHold :one
+SELECT *
+FROM holds
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT *
+FROM holds
+WHERE id = $1
+LIMIT 1
+FOR UPDATE;
+
+-- name: GetExpiredHoldForUpdate :one
+-- Holds locked by another transaction are skipped, so expiry can run in
+-- several places at once.
+SELECT *
+FROM holds
+WHERE status = 'active'
+  AND expires_at <= now()
+ORDER BY expires_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: UpdateHoldStatus :one
+UPDATE holds
+SET status = $2,
+  transfer_id = $3,
+  updated_at = now()
+WHERE id = $1
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,35 @@
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (key, username, request_hash)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT *
+FROM idempotency_keys
+WHERE username = $1
+  AND key = $2
+LIMIT 1;
+
+-- name: ReclaimIdempotencyKey :one
+-- Takes over a key for the same request when its response was never recorded,
+-- as when the server died midway, once it's older than stale_seconds.
+UPDATE idempotency_keys
+SET created_at = now()
+WHERE username = $1
+  AND key = $2
+  AND request_hash = $3
+  AND response_status IS NULL
+  AND created_at <= now() - sqlc.arg(stale_seconds)::int * interval '1 second'
+RETURNING *;
+
+-- name: UpdateIdempotencyKeyResponse :exec
+UPDATE idempotency_keys
+SET response_status = $3,
+  response_body = $4
+WHERE username = $1
+  AND key = $2;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE username = $1
+  AND key = $2;
\ No newline at end of file
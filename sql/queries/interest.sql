@@ -0,0 +1,89 @@
+-- name: AccrueInterest :execrows
+-- Accrues a day of interest on every open account whose product earns it, on
+-- the balance the account had at day_end. Accounts already accrued for the day
+-- are left alone, so a day can safely be accrued again.
+INSERT INTO interest_accruals (
+    account_id,
+    accrual_date,
+    balance,
+    rate_bps,
+    days_in_year
+  )
+SELECT a.id,
+  sqlc.arg(accrual_date)::date,
+  a.balance - COALESCE(
+    (
+      SELECT SUM(e.amount)
+      FROM entries e
+      WHERE e.account_id = a.id
+        AND e.create_at >= sqlc.arg(day_end)
+    ),
+    0
+  ),
+  p.interest_rate_bps,
+  p.interest_days_in_year
+FROM accounts a
+  JOIN products p ON p.code = a.product
+WHERE p.interest_rate_bps > 0
+  AND a.status <> 'closed'
+  AND a.created_at < sqlc.arg(day_end) ON CONFLICT (account_id, accrual_date) DO NOTHING;
+
+-- name: GetLastInterestAccrualDate :one
+SELECT accrual_date
+FROM interest_accruals
+ORDER BY accrual_date DESC
+LIMIT 1;
+
+-- name: SumInterestAccruals :many
+-- Sums balance times rate exactly per day count, leaving the division to the
+-- caller.
+SELECT days_in_year,
+  SUM(balance::numeric * rate_bps)::text AS numerator
+FROM interest_accruals
+WHERE account_id = sqlc.arg(account_id)
+  AND accrual_date < sqlc.arg(before)
+GROUP BY days_in_year
+ORDER BY days_in_year;
+
+-- name: ListUnpostedInterestPeriods :many
+SELECT DISTINCT a.account_id,
+  date_trunc('month', a.accrual_date)::date AS period
+FROM interest_accruals a
+WHERE a.accrual_date < sqlc.arg(before)
+  AND NOT EXISTS (
+    SELECT 1
+    FROM interest_postings p
+    WHERE p.account_id = a.account_id
+      AND p.period = date_trunc('month', a.accrual_date)::date
+  )
+ORDER BY period,
+  a.account_id;
+
+-- name: GetInterestPosting :one
+SELECT *
+FROM interest_postings
+WHERE account_id = $1
+  AND period = $2
+LIMIT 1;
+
+-- name: GetPostedInterest :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS amount
+FROM interest_postings
+WHERE account_id = sqlc.arg(account_id)
+  AND period < sqlc.arg(before);
+
+-- name: CreateInterestPosting :one
+INSERT INTO interest_postings (
+    account_id,
+    period,
+    amount,
+    transfer_id
+  )
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ListInterestPostings :many
+SELECT *
+FROM interest_postings
+WHERE account_id = $1
+ORDER BY period;
\ No newline at end of file
@@ -0,0 +1,61 @@
+-- name: ListBalanceMismatches :many
+SELECT a.id AS account_id,
+  a.currency,
+  a.balance,
+  COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+  LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- name: ListTransferEntryMismatches :many
+-- Fee legs share the transfer id but are checked through the currency totals.
+SELECT t.id AS transfer_id,
+  t.from_account_id,
+  t.to_account_id,
+  t.amount,
+  t.to_amount,
+  COUNT(e.id) AS entry_count,
+  COUNT(e.id) FILTER (
+    WHERE e.account_id = t.from_account_id
+      AND e.amount = - t.amount
+  ) AS debit_count,
+  COUNT(e.id) FILTER (
+    WHERE e.account_id = t.to_account_id
+      AND e.amount = t.to_amount
+  ) AS credit_count
+FROM transfers t
+  JOIN accounts fa ON fa.id = t.from_account_id
+  JOIN accounts ta ON ta.id = t.to_account_id
+  LEFT JOIN entries e ON e.transfer_id = t.id
+  AND NOT EXISTS (
+    SELECT 1
+    FROM transfer_fees f
+    WHERE e.id IN (f.debit_entry_id, f.credit_entry_id)
+  )
+GROUP BY t.id,
+  fa.currency,
+  ta.currency
+HAVING COUNT(e.id) <> CASE
+    WHEN fa.currency = ta.currency THEN 2
+    ELSE 4
+  END
+  OR COUNT(e.id) FILTER (
+    WHERE e.account_id = t.from_account_id
+      AND e.amount = - t.amount
+  ) <> 1
+  OR COUNT(e.id) FILTER (
+    WHERE e.account_id = t.to_account_id
+      AND e.amount = t.to_amount
+  ) <> 1
+ORDER BY t.id;
+
+-- name: ListCurrencyImbalances :many
+SELECT a.currency,
+  SUM(e.amount)::bigint AS total
+FROM entries e
+  JOIN accounts a ON a.id = e.account_id
+GROUP BY a.currency
+HAVING SUM(e.amount) <> 0
+ORDER BY a.currency;
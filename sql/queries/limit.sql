@@ -0,0 +1,77 @@
+-- name: GetAccountLimit :one
+SELECT *
+FROM account_limits
+WHERE account_id = $1
+LIMIT 1;
+
+-- name: UpsertAccountLimit :one
+INSERT INTO account_limits (
+    account_id,
+    per_transfer_limit,
+    daily_limit,
+    monthly_limit
+  )
+VALUES ($1, $2, $3, $4) ON CONFLICT (account_id) DO
+UPDATE
+SET per_transfer_limit = EXCLUDED.per_transfer_limit,
+  daily_limit = EXCLUDED.daily_limit,
+  monthly_limit = EXCLUDED.monthly_limit,
+  updated_at = now()
+RETURNING *;
+
+-- name: GetTierLimit :one
+SELECT *
+FROM tier_limits
+WHERE tier = $1
+  AND currency = $2
+LIMIT 1;
+
+-- name: UpsertTierLimit :one
+INSERT INTO tier_limits (
+    tier,
+    currency,
+    per_transfer_limit,
+    daily_limit,
+    monthly_limit
+  )
+VALUES ($1, $2, $3, $4, $5) ON CONFLICT (tier, currency) DO
+UPDATE
+SET per_transfer_limit = EXCLUDED.per_transfer_limit,
+  daily_limit = EXCLUDED.daily_limit,
+  monthly_limit = EXCLUDED.monthly_limit,
+  updated_at = now()
+RETURNING *;
+
+-- name: DeleteTierLimit :exec
+DELETE FROM tier_limits
+WHERE tier = $1
+  AND currency = $2;
+
+-- name: GetAccountOutgoingTotals :one
+-- Reversals are refunds rather than spending, so they don't count.
+SELECT COALESCE(
+    SUM(amount) FILTER (
+      WHERE created_at >= sqlc.arg(day_start)
+    ),
+    0
+  )::bigint AS daily,
+  COALESCE(SUM(amount), 0)::bigint AS monthly
+FROM transfers
+WHERE from_account_id = sqlc.arg(account_id)
+  AND reversal_of IS NULL
+  AND created_at >= sqlc.arg(month_start);
+
+-- name: GetOwnerOutgoingTotals :one
+SELECT COALESCE(
+    SUM(t.amount) FILTER (
+      WHERE t.created_at >= sqlc.arg(day_start)
+    ),
+    0
+  )::bigint AS daily,
+  COALESCE(SUM(t.amount), 0)::bigint AS monthly
+FROM transfers t
+  JOIN accounts a ON a.id = t.from_account_id
+WHERE a.owner = sqlc.arg(owner)
+  AND t.currency = sqlc.arg(currency)
+  AND t.reversal_of IS NULL
+  AND t.created_at >= sqlc.arg(month_start);
\ No newline at end of file
@@ -0,0 +1,28 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (event_type, key, payload)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ListUnpublishedOutboxEvents :many
+SELECT *
+FROM outbox
+WHERE published_at IS NULL
+ORDER BY id
+LIMIT $1;
+
+-- name: MarkOutboxEventsPublished :execrows
+UPDATE outbox
+SET published_at = now()
+WHERE id = ANY(sqlc.arg(ids)::bigint [])
+  AND published_at IS NULL;
+
+-- name: TryAdvisoryXactLock :one
+-- Takes a lock held until the end of the transaction, or returns false at once
+-- if another transaction holds it.
+SELECT pg_try_advisory_xact_lock(sqlc.arg(key)::bigint) AS locked;
+
+-- name: GetOutboxEvent :one
+SELECT *
+FROM outbox
+WHERE id = $1
+LIMIT 1;
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- name: GetProduct :one
+SELECT *
+FROM products
+WHERE code = $1
+LIMIT 1;
+
+-- name: ListProducts :many
+SELECT *
+FROM products
+WHERE NOT system
+ORDER BY code;
+
+-- name: UpdateProductInterest :one
+UPDATE products
+SET interest_rate_bps = $2,
+  interest_days_in_year = $3,
+  updated_at = now()
+WHERE code = $1
+  AND NOT system
+RETURNING *;
\ No newline at end of file
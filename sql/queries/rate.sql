@@ -0,0 +1,14 @@
+-- name: GetRate :one
+SELECT *
+FROM rates
+WHERE base_currency = $1
+  AND quote_currency = $2
+LIMIT 1;
+
+-- name: UpsertRate :one
+INSERT INTO rates (base_currency, quote_currency, rate)
+VALUES ($1, $2, $3) ON CONFLICT (base_currency, quote_currency) DO
+UPDATE
+SET rate = EXCLUDED.rate,
+  updated_at = now()
+RETURNING *;
@@ -0,0 +1,114 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+    owner,
+    from_account_id,
+    to_account_id,
+    amount,
+    currency,
+    recurrence,
+    start_at,
+    next_run_at,
+    due_at
+  )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $7, $7)
+RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT *
+FROM scheduled_transfers
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListScheduledTransfers :many
+SELECT *
+FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2 OFFSET $3;
+
+-- name: UpdateScheduledTransfer :one
+-- Rescheduling starts the recurrence over from the new start time.
+UPDATE scheduled_transfers
+SET amount = $2,
+  recurrence = $3,
+  start_at = $4,
+  run_count = 0,
+  next_run_at = $4,
+  due_at = $4,
+  attempts = 0,
+  last_error = NULL,
+  updated_at = now()
+WHERE id = $1
+  AND status = 'active'
+RETURNING *;
+
+-- name: CancelScheduledTransfer :one
+UPDATE scheduled_transfers
+SET status = 'cancelled',
+  next_run_at = NULL,
+  due_at = NULL,
+  updated_at = now()
+WHERE id = $1
+  AND status = 'active'
+RETURNING *;
+
+-- name: ClaimDueScheduledTransfer :one
+-- Leases the schedule that's been due the longest. Rows leased or locked by
+-- another worker are skipped, so any number of workers can claim at once.
+UPDATE scheduled_transfers
+SET locked_until = now() + sqlc.arg(lease_seconds)::int * interval '1 second'
+WHERE id = (
+    SELECT id
+    FROM scheduled_transfers
+    WHERE status = 'active'
+      AND due_at <= now()
+      AND (
+        locked_until IS NULL
+        OR locked_until < now()
+      )
+    ORDER BY due_at
+    LIMIT 1 FOR UPDATE SKIP LOCKED
+  )
+RETURNING *;
+
+-- name: AdvanceScheduledTransfer :execrows
+-- Moves a schedule past the occurrence at scheduled_for. Nothing is updated
+-- when the schedule has been changed since it was claimed.
+UPDATE scheduled_transfers
+SET status = sqlc.arg(status),
+  run_count = run_count + 1,
+  next_run_at = sqlc.narg(next_run_at),
+  due_at = sqlc.narg(next_run_at),
+  attempts = 0,
+  last_error = sqlc.narg(last_error),
+  locked_until = NULL,
+  updated_at = now()
+WHERE id = sqlc.arg(id)
+  AND next_run_at = sqlc.arg(scheduled_for);
+
+-- name: RetryScheduledTransfer :execrows
+UPDATE scheduled_transfers
+SET attempts = attempts + 1,
+  due_at = sqlc.arg(due_at),
+  last_error = sqlc.arg(last_error),
+  locked_until = NULL,
+  updated_at = now()
+WHERE id = sqlc.arg(id)
+  AND next_run_at = sqlc.arg(scheduled_for);
+
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs (
+    scheduled_transfer_id,
+    scheduled_for,
+    attempt,
+    transfer_id,
+    error
+  )
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListScheduledTransferRuns :many
+SELECT *
+FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id;
\ No newline at end of file
@@ -1,10 +1,98 @@
 -- name: CreateTransfer :one
-INSERT INTO transfers (from_account_id, to_account_id, amount)
-VALUES ($1, $2, $3)
+INSERT INTO transfers (
+    from_account_id,
+    to_account_id,
+    amount,
+    reversal_of,
+    to_amount,
+    fx_rate,
+    fx_spread_bps,
+    currency,
+    to_currency,
+    idempotency_key
+  )
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 RETURNING *;
 
 -- name: GetTransfer :one
 SELECT *
 FROM transfers
 WHERE id = $1
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: GetTransferByIdempotencyKey :one
+SELECT *
+FROM transfers
+WHERE idempotency_key = $1
+LIMIT 1;
+
+-- name: GetTransferForUpdate :one
+SELECT *
+FROM transfers
+WHERE id = $1
+LIMIT 1
+FOR UPDATE;
+
+-- name: GetReversedAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS amount,
+  COALESCE(SUM(to_amount), 0)::bigint AS to_amount
+FROM transfers
+WHERE reversal_of = $1;
+
+-- name: ListTransfers :many
+SELECT *
+FROM transfers
+WHERE (
+    from_account_id = sqlc.arg(account_id)
+    OR to_account_id = sqlc.arg(account_id)
+  )
+  AND (
+    sqlc.narg(direction)::varchar IS NULL
+    OR (
+      sqlc.narg(direction) = 'outgoing'
+      AND from_account_id = sqlc.arg(account_id)
+    )
+    OR (
+      sqlc.narg(direction) = 'incoming'
+      AND to_account_id = sqlc.arg(account_id)
+    )
+  )
+  AND (
+    sqlc.narg(counterparty_id)::bigint IS NULL
+    OR (
+      from_account_id = sqlc.arg(account_id)
+      AND to_account_id = sqlc.narg(counterparty_id)
+    )
+    OR (
+      to_account_id = sqlc.arg(account_id)
+      AND from_account_id = sqlc.narg(counterparty_id)
+    )
+  )
+  AND (
+    sqlc.narg(min_amount)::bigint IS NULL
+    OR CASE
+      WHEN from_account_id = sqlc.arg(account_id) THEN amount
+      ELSE to_amount
+    END >= sqlc.narg(min_amount)
+  )
+  AND (
+    sqlc.narg(max_amount)::bigint IS NULL
+    OR CASE
+      WHEN from_account_id = sqlc.arg(account_id) THEN amount
+      ELSE to_amount
+    END <= sqlc.narg(max_amount)
+  )
+  AND (
+    sqlc.narg(start_time)::timestamptz IS NULL
+    OR created_at >= sqlc.narg(start_time)
+  )
+  AND (
+    sqlc.narg(end_time)::timestamptz IS NULL
+    OR created_at < sqlc.narg(end_time)
+  )
+  AND (
+    sqlc.narg(before_id)::bigint IS NULL
+    OR id < sqlc.narg(before_id)
+  )
+ORDER BY id DESC
+LIMIT sqlc.arg(page_size);
\ No newline at end of file
@@ -7,4 +7,23 @@ RETURNING *;
 SELECT *
 FROM "users"
 WHERE username = $1
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: UpdateUserRole :one
+UPDATE "users"
+SET role = $2
+WHERE username = $1
+RETURNING *;
+
+-- name: GetUserForUpdate :one
+SELECT *
+FROM "users"
+WHERE username = $1
+LIMIT 1
+FOR UPDATE;
+
+-- name: UpdateUserTier :one
+UPDATE "users"
+SET tier = $2
+WHERE username = $1
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,95 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (owner, url, secret, event_types)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetWebhook :one
+SELECT *
+FROM webhooks
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListWebhooks :many
+SELECT *
+FROM webhooks
+WHERE owner = $1
+ORDER BY id;
+
+-- name: DeleteWebhook :exec
+DELETE FROM webhooks
+WHERE id = $1;
+
+-- name: CreateWebhookDeliveries :execrows
+-- Queues the event for every webhook of the owners that takes its type.
+INSERT INTO webhook_deliveries (webhook_id, event_id)
+SELECT id,
+  sqlc.arg(event_id)::bigint
+FROM webhooks
+WHERE owner = ANY(sqlc.arg(owners)::varchar [])
+  AND (
+    cardinality(event_types) = 0
+    OR sqlc.arg(event_type)::varchar = ANY(event_types)
+  ) ON CONFLICT DO NOTHING;
+
+-- name: ClaimDueWebhookDelivery :one
+-- Leases the pending delivery that's been due the longest by pushing its next
+-- attempt back. Rows locked by another worker are skipped, and a delivery
+-- whose worker died is tried again once the lease is over.
+UPDATE webhook_deliveries
+SET next_attempt_at = now() + sqlc.arg(lease_seconds)::int * interval '1 second'
+WHERE id = (
+    SELECT id
+    FROM webhook_deliveries
+    WHERE status = 'pending'
+      AND next_attempt_at <= now()
+    ORDER BY next_attempt_at
+    LIMIT 1 FOR UPDATE SKIP LOCKED
+  )
+RETURNING *;
+
+-- name: RecordWebhookDeliveryAttempt :one
+UPDATE webhook_deliveries
+SET status = sqlc.arg(status),
+  attempts = attempts + 1,
+  next_attempt_at = sqlc.arg(next_attempt_at),
+  last_status_code = sqlc.narg(last_status_code),
+  last_error = sqlc.narg(last_error),
+  delivered_at = CASE
+    WHEN sqlc.arg(status) = 'delivered' THEN now()
+    ELSE delivered_at
+  END
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT *
+FROM webhook_deliveries
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListWebhookDeliveries :many
+SELECT *
+FROM webhook_deliveries
+WHERE webhook_id = sqlc.arg(webhook_id)
+  AND (
+    sqlc.narg(status)::varchar IS NULL
+    OR status = sqlc.narg(status)
+  )
+  AND (
+    sqlc.narg(before_id)::bigint IS NULL
+    OR id < sqlc.narg(before_id)
+  )
+ORDER BY id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: ReplayWebhookDelivery :one
+-- Queues a delivery that's no longer pending to be sent again from scratch.
+UPDATE webhook_deliveries
+SET status = 'pending',
+  attempts = 0,
+  next_attempt_at = now(),
+  last_status_code = NULL,
+  last_error = NULL
+WHERE id = $1
+  AND status <> 'pending'
+RETURNING *;
\ No newline at end of file